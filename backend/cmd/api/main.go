@@ -9,9 +9,17 @@ import (
 	"syscall"
 
 	"github.com/georgiev098/film-manager/backend/internal/app"
+	"github.com/georgiev098/film-manager/backend/internal/audit"
 	"github.com/georgiev098/film-manager/backend/internal/core"
 	"github.com/georgiev098/film-manager/backend/internal/db"
+	"github.com/georgiev098/film-manager/backend/internal/helpers"
+	"github.com/georgiev098/film-manager/backend/internal/jwtkeys"
+	"github.com/georgiev098/film-manager/backend/internal/mailer"
 	"github.com/georgiev098/film-manager/backend/internal/models"
+	"github.com/georgiev098/film-manager/backend/internal/password"
+	"github.com/georgiev098/film-manager/backend/internal/ratelimit"
+	"github.com/georgiev098/film-manager/backend/internal/repositories"
+	"github.com/georgiev098/film-manager/backend/internal/services"
 	"github.com/go-playground/validator/v10"
 	"github.com/joho/godotenv"
 )
@@ -46,20 +54,89 @@ func main() {
 	app.DB = database
 
 	// ---- MIGRATE SCHEMA ----
-	err = app.DB.AutoMigrate(&models.User{}, &models.Camera{}, &models.Lens{}, &models.RefreshToken{})
+	err = app.DB.AutoMigrate(&models.User{}, &models.Camera{}, &models.Lens{}, &models.FilmStock{}, &models.Roll{}, &models.Kit{}, &models.Shoot{}, &models.ArchiveLocation{}, &models.Frame{}, &models.Print{}, &models.RefreshToken{}, &models.PasswordResetToken{}, &models.RecoveryCode{}, &models.PersonalAccessToken{}, &models.UserIdentity{}, &models.RateLimitCounter{}, &models.DataRequest{}, &models.AuditEvent{})
 	if err != nil {
 		app.ErrorLog.Fatalf("AutoMigrate failed: %v", err)
 	}
 
+	// ---- MAILER ----
+	mail, err := mailer.New(app.Config.Mail, app.InfoLog)
+	if err != nil {
+		app.ErrorLog.Fatalf("Mailer setup failed: %v", err)
+	}
+
+	// ---- RATE LIMIT STORE ----
+	limits, err := ratelimit.New(app.Config.LoginProtection.Store, app.DB)
+	if err != nil {
+		app.ErrorLog.Fatalf("Rate limit store setup failed: %v", err)
+	}
+
+	// ---- SIGNING KEYS ----
+	var keys *jwtkeys.KeySet
+	if app.Config.Auth.SigningKeyFile != "" {
+		keys, err = jwtkeys.Load(app.Config.Auth.SigningKeyFile, app.Config.Auth.VerificationKeys)
+	} else if app.Config.Env == "dev" {
+		// Access tokens won't survive a restart, clients just refresh
+		app.InfoLog.Println("JWT_SIGNING_KEY_FILE not set, using a temporary signing key")
+		keys, err = jwtkeys.Generate()
+	} else {
+		app.ErrorLog.Fatal("JWT_SIGNING_KEY_FILE is required in production")
+	}
+	if err != nil {
+		app.ErrorLog.Fatalf("Loading signing keys failed: %v", err)
+	}
+
+	// ---- VALIDATION ----
+	validate := validator.New()
+	validate.RegisterTagNameFunc(helpers.JSONFieldName)
+
+	policy := &password.Policy{
+		MinLength:   app.Config.PasswordPolicy.MinLength,
+		MaxLength:   app.Config.PasswordPolicy.MaxLength,
+		MinScore:    app.Config.PasswordPolicy.MinScore,
+		BannedWords: app.Config.PasswordPolicy.BannedWords,
+		Logger:      app.ErrorLog,
+	}
+	if app.Config.PasswordPolicy.BreachedPath != "" {
+		policy.Breached, err = password.LoadBreachedList(app.Config.PasswordPolicy.BreachedPath)
+		if err != nil {
+			app.ErrorLog.Fatalf("Loading breached password list failed: %v", err)
+		}
+	}
+
+	err = policy.RegisterValidations(validate)
+	if err != nil {
+		app.ErrorLog.Fatalf("Password policy setup failed: %v", err)
+	}
+
 	// ---- COMPOSITION ROOT ----
 	// Bundle shared dependencies
 	deps := &core.AppDeps{
-		DB:       app.DB,
-		Logger:   app.InfoLog,
-		Config:   app.Config,
-		Validate: validator.New(),
+		DB:        app.DB,
+		Logger:    app.InfoLog,
+		Config:    app.Config,
+		Validate:  validate,
+		Mailer:    mail,
+		Keys:      keys,
+		Limits:    limits,
+		Passwords: password.NewHasher(app.Config.Password),
+		Audit:     audit.NewService(repositories.NewAuditRepo(app.DB), app.InfoLog),
 	}
 
+	// ---- BACKGROUND JOBS ----
+	dataRequests := services.NewDataRequestService(
+		repositories.NewDataRequestRepo(deps.DB),
+		repositories.NewUserDataRepo(deps.DB),
+		services.NewCollectionService(deps.DB, deps.Validate, services.NewItemQuota(deps.DB, deps.Config.Auth.UnverifiedPolicy, deps.Config.Auth.UnverifiedItemLimit), deps.Audit),
+		services.NewLoginGuard(deps.Limits, deps.Config.LoginProtection),
+		deps.Passwords,
+		deps.Mailer,
+		deps.Logger,
+		deps.Config.AppURL,
+		deps.Config.DataRequests,
+	)
+	go dataRequests.Run(ctx)
+
 	err = app.Serve(ctx, deps)
 	if err != nil && err != http.ErrServerClosed {
 		app.ErrorLog.Fatalf("Server error: %v", err)
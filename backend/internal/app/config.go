@@ -3,11 +3,14 @@ package app
 import (
 	"flag"
 	"fmt"
+	"net/http"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/georgiev098/film-manager/backend/internal/core"
 	"github.com/georgiev098/film-manager/backend/internal/helpers"
+	"github.com/georgiev098/film-manager/backend/internal/password"
 )
 
 func LoadConfig() core.Config {
@@ -73,8 +76,166 @@ func LoadConfig() core.Config {
 		cfg.Auth.RefreshTTL = time.Duration(helpers.AtoiOrDefault(refreshTTL, 7)) * 24 * time.Hour
 	}
 
+	resetTTL := os.Getenv("PASSWORD_RESET_TTL_MINUTES")
+	if resetTTL == "" {
+		cfg.Auth.PasswordResetTTL = 30 * time.Minute
+	} else {
+		cfg.Auth.PasswordResetTTL = time.Duration(helpers.AtoiOrDefault(resetTTL, 30)) * time.Minute
+	}
+
 	cfg.Auth.AccessSecret = jwtSecret
 
+	// Access token signing keys, see package jwtkeys
+	cfg.Auth.SigningKeyFile = os.Getenv("JWT_SIGNING_KEY_FILE")
+	for _, file := range strings.Split(os.Getenv("JWT_VERIFICATION_KEY_FILES"), ",") {
+		if file = strings.TrimSpace(file); file != "" {
+			cfg.Auth.VerificationKeys = append(cfg.Auth.VerificationKeys, file)
+		}
+	}
+
+	cfg.Auth.Issuer = os.Getenv("JWT_ISSUER")
+	if cfg.Auth.Issuer == "" {
+		cfg.Auth.Issuer = "film-manager"
+	}
+
+	cfg.Auth.Audience = os.Getenv("JWT_AUDIENCE")
+	if cfg.Auth.Audience == "" {
+		cfg.Auth.Audience = "film-manager-api"
+	}
+
+	// Email verification
+	cfg.Auth.VerificationSecret = os.Getenv("EMAIL_VERIFICATION_SECRET")
+	if cfg.Auth.VerificationSecret == "" {
+		cfg.Auth.VerificationSecret = jwtSecret
+	}
+
+	verificationTTL := os.Getenv("EMAIL_VERIFICATION_TTL_HOURS")
+	if verificationTTL == "" {
+		cfg.Auth.VerificationTTL = 24 * time.Hour
+	} else {
+		cfg.Auth.VerificationTTL = time.Duration(helpers.AtoiOrDefault(verificationTTL, 24)) * time.Hour
+	}
+
+	cfg.Auth.UnverifiedPolicy = os.Getenv("UNVERIFIED_POLICY")
+	switch cfg.Auth.UnverifiedPolicy {
+	case "":
+		cfg.Auth.UnverifiedPolicy = core.UnverifiedPolicyLimited
+	case core.UnverifiedPolicyNone, core.UnverifiedPolicyReadOnly, core.UnverifiedPolicyLimited:
+	default:
+		panic("UNVERIFIED_POLICY must be none, read_only or limited")
+	}
+
+	cfg.Auth.UnverifiedItemLimit = helpers.AtoiOrDefault(os.Getenv("UNVERIFIED_ITEM_LIMIT"), 5)
+
+	appURL := os.Getenv("APP_URL")
+	if appURL == "" {
+		appURL = "http://localhost:5173"
+	}
+
+	// Mail config
+	cfg.Mail.Driver = os.Getenv("MAIL_DRIVER")
+	if cfg.Mail.Driver == "" {
+		cfg.Mail.Driver = "log"
+	}
+
+	cfg.Mail.From = os.Getenv("MAIL_FROM")
+	if cfg.Mail.From == "" {
+		cfg.Mail.From = "Film Manager <no-reply@localhost>"
+	}
+
+	cfg.Mail.SMTPHost = os.Getenv("SMTP_HOST")
+	cfg.Mail.SMTPPort = helpers.AtoiOrDefault(os.Getenv("SMTP_PORT"), 587)
+	cfg.Mail.SMTPUsername = os.Getenv("SMTP_USERNAME")
+	cfg.Mail.SMTPPassword = os.Getenv("SMTP_PASSWORD")
+	cfg.Mail.FileDir = os.Getenv("MAIL_FILE_DIR")
+
+	// Password hashing
+	cfg.Password.Memory = uint32(helpers.AtoiOrDefault(os.Getenv("PASSWORD_ARGON2_MEMORY_KIB"), int(password.DefaultParams.Memory)))
+	cfg.Password.Iterations = uint32(helpers.AtoiOrDefault(os.Getenv("PASSWORD_ARGON2_ITERATIONS"), int(password.DefaultParams.Iterations)))
+	cfg.Password.Parallelism = uint8(helpers.AtoiOrDefault(os.Getenv("PASSWORD_ARGON2_PARALLELISM"), int(password.DefaultParams.Parallelism)))
+	if cfg.Password.Memory < 8*uint32(cfg.Password.Parallelism) || cfg.Password.Iterations < 1 || cfg.Password.Parallelism < 1 {
+		panic("invalid PASSWORD_ARGON2_* settings")
+	}
+
+	// Password policy
+	cfg.PasswordPolicy.MinLength = helpers.AtoiOrDefault(os.Getenv("PASSWORD_MIN_LENGTH"), 8)
+	cfg.PasswordPolicy.MaxLength = helpers.AtoiOrDefault(os.Getenv("PASSWORD_MAX_LENGTH"), 128)
+	cfg.PasswordPolicy.MinScore = helpers.AtoiOrDefault(os.Getenv("PASSWORD_MIN_SCORE"), 2)
+	cfg.PasswordPolicy.BreachedPath = os.Getenv("BREACHED_PASSWORDS_PATH")
+
+	bannedWords := os.Getenv("PASSWORD_BANNED_WORDS")
+	if bannedWords == "" {
+		bannedWords = "film,manager,filmmanager,password"
+	}
+	for _, word := range strings.Split(bannedWords, ",") {
+		if word = strings.TrimSpace(word); word != "" {
+			cfg.PasswordPolicy.BannedWords = append(cfg.PasswordPolicy.BannedWords, word)
+		}
+	}
+
+	// Login brute-force protection
+	cfg.LoginProtection.Store = os.Getenv("LOGIN_LIMIT_STORE")
+	if cfg.LoginProtection.Store == "" {
+		cfg.LoginProtection.Store = "memory"
+	}
+	cfg.LoginProtection.IPLimit = helpers.AtoiOrDefault(os.Getenv("LOGIN_IP_LIMIT"), 20)
+	cfg.LoginProtection.IPWindow = time.Duration(helpers.AtoiOrDefault(os.Getenv("LOGIN_IP_WINDOW_MINUTES"), 15)) * time.Minute
+	cfg.LoginProtection.DelayAfter = helpers.AtoiOrDefault(os.Getenv("LOGIN_DELAY_AFTER_FAILURES"), 3)
+	cfg.LoginProtection.LockoutThreshold = helpers.AtoiOrDefault(os.Getenv("LOGIN_LOCKOUT_THRESHOLD"), 10)
+	cfg.LoginProtection.LockoutWindow = time.Duration(helpers.AtoiOrDefault(os.Getenv("LOGIN_LOCKOUT_WINDOW_MINUTES"), 15)) * time.Minute
+	cfg.LoginProtection.LockoutDuration = time.Duration(helpers.AtoiOrDefault(os.Getenv("LOGIN_LOCKOUT_MINUTES"), 15)) * time.Minute
+	cfg.LoginProtection.MailIPLimit = helpers.AtoiOrDefault(os.Getenv("MAIL_IP_LIMIT"), 10)
+	cfg.LoginProtection.MailAddressLimit = helpers.AtoiOrDefault(os.Getenv("MAIL_ADDRESS_LIMIT"), 3)
+	cfg.LoginProtection.MailWindow = time.Duration(helpers.AtoiOrDefault(os.Getenv("MAIL_LIMIT_WINDOW_MINUTES"), 60)) * time.Minute
+
+	// Data export and erasure jobs
+	cfg.DataRequests.ExportDir = os.Getenv("DATA_EXPORT_DIR")
+	if cfg.DataRequests.ExportDir == "" {
+		cfg.DataRequests.ExportDir = "./data/exports"
+	}
+	cfg.DataRequests.ExportTTL = time.Duration(helpers.AtoiOrDefault(os.Getenv("DATA_EXPORT_TTL_HOURS"), 72)) * time.Hour
+	cfg.DataRequests.ErasureGracePeriod = time.Duration(helpers.AtoiOrDefault(os.Getenv("ERASURE_GRACE_DAYS"), 30)) * 24 * time.Hour
+	cfg.DataRequests.PollInterval = time.Duration(helpers.AtoiOrDefault(os.Getenv("DATA_JOBS_POLL_SECONDS"), 30)) * time.Second
+	if cfg.DataRequests.PollInterval <= 0 {
+		panic("DATA_JOBS_POLL_SECONDS must be positive")
+	}
+
+	// OIDC providers, e.g. OIDC_PROVIDERS=company with OIDC_COMPANY_ISSUER etc.
+	cfg.OIDC.RedirectBaseURL = os.Getenv("OIDC_REDIRECT_BASE_URL")
+	if cfg.OIDC.RedirectBaseURL == "" {
+		cfg.OIDC.RedirectBaseURL = "http://localhost:" + port
+	}
+
+	for _, name := range strings.Split(os.Getenv("OIDC_PROVIDERS"), ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+
+		prefix := "OIDC_" + strings.ToUpper(name) + "_"
+
+		provider := core.OIDCProvider{
+			Name:         name,
+			DisplayName:  os.Getenv(prefix + "DISPLAY_NAME"),
+			Issuer:       os.Getenv(prefix + "ISSUER"),
+			ClientID:     os.Getenv(prefix + "CLIENT_ID"),
+			ClientSecret: os.Getenv(prefix + "CLIENT_SECRET"),
+			Scopes:       strings.Fields(os.Getenv(prefix + "SCOPES")),
+		}
+
+		if provider.Issuer == "" || provider.ClientID == "" {
+			panic(fmt.Sprintf("%sISSUER and %sCLIENT_ID are required", prefix, prefix))
+		}
+		if provider.DisplayName == "" {
+			provider.DisplayName = name
+		}
+		if len(provider.Scopes) == 0 {
+			provider.Scopes = []string{"openid", "email", "profile"}
+		}
+
+		cfg.OIDC.Providers = append(cfg.OIDC.Providers, provider)
+	}
+
 	// Flags to optionally override env vars
 	flag.IntVar(&cfg.Port, "port", helpers.AtoiOrDefault(port, 8080), "Server port")
 	flag.StringVar(&cfg.Env, "env", env, "Environment {dev|prod}")
@@ -85,15 +246,86 @@ func LoadConfig() core.Config {
 		"MySQL DSN",
 	)
 	flag.StringVar(&cfg.Api, "api", apiURL, "API URL")
+	flag.StringVar(&cfg.AppURL, "app-url", appURL, "Frontend URL used in emailed links")
 
 	flag.Parse()
 
-	// Allowed origins
+	// CORS. Defaults to the frontend's origin only.
+	cfg.CORS.AllowedOrigins = envList("CORS_ALLOWED_ORIGINS", cfg.AppURL)
+	cfg.CORS.AllowedMethods = envList("CORS_ALLOWED_METHODS", "GET,POST,PUT,PATCH,DELETE,OPTIONS")
+	cfg.CORS.AllowedHeaders = envList("CORS_ALLOWED_HEADERS", "Accept,Authorization,Content-Type,X-CSRF-Token")
+	cfg.CORS.ExposedHeaders = envList("CORS_EXPOSED_HEADERS", "Link,X-Request-ID")
+	cfg.CORS.MaxAge = helpers.AtoiOrDefault(os.Getenv("CORS_MAX_AGE_SECONDS"), 300)
+
+	for _, origin := range cfg.CORS.AllowedOrigins {
+		// Credentials are allowed, a bare wildcard would let any site use the session
+		if origin == "*" {
+			panic("CORS_ALLOWED_ORIGINS must list origins explicitly, \"*\" is not allowed")
+		}
+	}
+
+	// Cookies. Secure with the __Host- prefix by default outside dev, also when
+	// that is only set with the -env flag
+	cfg.Cookies.Secure = helpers.BoolOrDefault(os.Getenv("COOKIE_SECURE"), cfg.Env != "dev")
+	cfg.Cookies.Domain = os.Getenv("COOKIE_DOMAIN")
+	cfg.Cookies.HostPrefix = helpers.BoolOrDefault(os.Getenv("COOKIE_HOST_PREFIX"), cfg.Env != "dev" && cfg.Cookies.Domain == "")
+
+	switch strings.ToLower(os.Getenv("COOKIE_SAMESITE")) {
+	case "", "strict":
+		cfg.Cookies.SameSite = http.SameSiteStrictMode
+	case "lax":
+		cfg.Cookies.SameSite = http.SameSiteLaxMode
+	case "none":
+		cfg.Cookies.SameSite = http.SameSiteNoneMode
+	default:
+		panic("COOKIE_SAMESITE must be strict, lax or none")
+	}
+
+	if cfg.Cookies.HostPrefix && (!cfg.Cookies.Secure || cfg.Cookies.Domain != "") {
+		panic("COOKIE_HOST_PREFIX requires COOKIE_SECURE and no COOKIE_DOMAIN")
+	}
+	if cfg.Cookies.SameSite == http.SameSiteNoneMode && !cfg.Cookies.Secure {
+		panic("COOKIE_SAMESITE=none requires COOKIE_SECURE")
+	}
+
+	// Security headers. HSTS is off in dev, where the API runs on plain HTTP.
+	hstsDefault := 365 * 24 * 60 * 60
 	if cfg.Env == "dev" {
-		cfg.AllowedOrigins = []string{"*"}
-	} else {
-		cfg.AllowedOrigins = []string{"https://myapp.com"}
+		hstsDefault = 0
 	}
+	cfg.SecurityHeaders.HSTSMaxAge = time.Duration(helpers.AtoiOrDefault(os.Getenv("HSTS_MAX_AGE_SECONDS"), hstsDefault)) * time.Second
+	cfg.SecurityHeaders.HSTSIncludeSubdomains = helpers.BoolOrDefault(os.Getenv("HSTS_INCLUDE_SUBDOMAINS"), true)
+	cfg.SecurityHeaders.HSTSPreload = helpers.BoolOrDefault(os.Getenv("HSTS_PRELOAD"), false)
+	cfg.SecurityHeaders.ContentSecurityPolicy = envOrDefault("CONTENT_SECURITY_POLICY", "default-src 'none'; frame-ancestors 'none'; base-uri 'none'; form-action 'none'")
+	cfg.SecurityHeaders.PrintCSP = envOrDefault("PRINT_CONTENT_SECURITY_POLICY", "default-src 'none'; style-src 'unsafe-inline'; frame-ancestors 'none'; base-uri 'none'; form-action 'none'")
+	cfg.SecurityHeaders.ReferrerPolicy = envOrDefault("REFERRER_POLICY", "no-referrer")
+	cfg.SecurityHeaders.PermissionsPolicy = envOrDefault("PERMISSIONS_POLICY", "camera=(), microphone=(), geolocation=(), payment=(), usb=()")
 
 	return cfg
 }
+
+// envOrDefault returns the variable, or def when it is unset. Set to "-" to
+// turn an optional value off.
+func envOrDefault(name, def string) string {
+	value, ok := os.LookupEnv(name)
+	if !ok {
+		return def
+	}
+	if strings.TrimSpace(value) == "-" {
+		return ""
+	}
+
+	return value
+}
+
+// envList splits a comma separated variable, using def when it is unset
+func envList(name, def string) []string {
+	var list []string
+	for _, item := range strings.Split(envOrDefault(name, def), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+
+	return list
+}
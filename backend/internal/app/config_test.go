@@ -0,0 +1,54 @@
+package app
+
+import (
+	"flag"
+	"os"
+	"testing"
+)
+
+// setArgs makes the next LoadConfig parse args as if the binary was started with them
+func setArgs(t *testing.T, args ...string) {
+	t.Helper()
+
+	oldArgs, oldFlags := os.Args, flag.CommandLine
+	t.Cleanup(func() {
+		os.Args, flag.CommandLine = oldArgs, oldFlags
+	})
+
+	os.Args = append([]string{"api"}, args...)
+	flag.CommandLine = flag.NewFlagSet("api", flag.ContinueOnError)
+}
+
+func TestLoadConfigCookies(t *testing.T) {
+	tests := []struct {
+		name           string
+		env            string
+		args           []string
+		wantSecure     bool
+		wantHostPrefix bool
+	}{
+		{"dev by default", "", nil, false, false},
+		{"ENV=prod", "prod", nil, true, true},
+		{"-env prod without ENV", "", []string{"-env", "prod"}, true, true},
+		{"-env dev overrides ENV=prod", "prod", []string{"-env", "dev"}, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("ENV", tt.env)
+			t.Setenv("JWT_ACCESS_SECRET", "secret")
+			t.Setenv("COOKIE_SECURE", "")
+			t.Setenv("COOKIE_DOMAIN", "")
+			t.Setenv("COOKIE_HOST_PREFIX", "")
+			t.Setenv("COOKIE_SAMESITE", "")
+
+			setArgs(t, tt.args...)
+			cfg := LoadConfig()
+
+			if cfg.Cookies.Secure != tt.wantSecure || cfg.Cookies.HostPrefix != tt.wantHostPrefix {
+				t.Errorf("env %q: secure %v, host prefix %v; want %v, %v",
+					cfg.Env, cfg.Cookies.Secure, cfg.Cookies.HostPrefix, tt.wantSecure, tt.wantHostPrefix)
+			}
+		})
+	}
+}
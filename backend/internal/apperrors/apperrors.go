@@ -0,0 +1,80 @@
+// Package apperrors holds the typed errors services return for expected
+// failures. Each carries a Kind, which decides the HTTP status, and a message
+// that is safe to show to clients. Anything else is treated as internal.
+package apperrors
+
+import "errors"
+
+type Kind int
+
+const (
+	KindInternal Kind = iota
+	KindNotFound
+	KindForbidden
+	KindConflict
+	KindValidation
+	KindUnauthorized
+)
+
+const internalMessage = "internal server error"
+
+type Error struct {
+	Kind    Kind
+	Message string
+	Err     error // underlying cause, logged but never sent to clients
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+func NotFound(message string) *Error {
+	return &Error{Kind: KindNotFound, Message: message}
+}
+
+func Forbidden(message string) *Error {
+	return &Error{Kind: KindForbidden, Message: message}
+}
+
+func Conflict(message string) *Error {
+	return &Error{Kind: KindConflict, Message: message}
+}
+
+func Validation(message string) *Error {
+	return &Error{Kind: KindValidation, Message: message}
+}
+
+func Unauthorized(message string) *Error {
+	return &Error{Kind: KindUnauthorized, Message: message}
+}
+
+// Internal wraps an unexpected failure. Clients only get a generic message.
+func Internal(err error) *Error {
+	return &Error{Kind: KindInternal, Message: internalMessage, Err: err}
+}
+
+// KindOf returns the kind of the first domain error in err's chain, or
+// KindInternal when there is none
+func KindOf(err error) Kind {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Kind
+	}
+	return KindInternal
+}
+
+// Message returns what clients may see about err
+func Message(err error) string {
+	var e *Error
+	if errors.As(err, &e) && e.Kind != KindInternal {
+		return e.Message
+	}
+	return internalMessage
+}
@@ -0,0 +1,178 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"reflect"
+
+	"github.com/georgiev098/film-manager/backend/internal/dtos"
+	"github.com/georgiev098/film-manager/backend/internal/models"
+	"github.com/georgiev098/film-manager/backend/internal/repositories"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultLimit = 50
+	maxLimit     = 200
+)
+
+type (
+	clientKey struct{}
+	actorKey  struct{}
+)
+
+// Client is the device a request came from, already cut to the column sizes
+// of models.AuditEvent
+type Client struct {
+	UserAgent string
+	IPAddress string
+}
+
+// WithClient stores the calling device on the context, for audit events
+func WithClient(ctx context.Context, client Client) context.Context {
+	return context.WithValue(ctx, clientKey{}, client)
+}
+
+func ClientFromContext(ctx context.Context) Client {
+	client, _ := ctx.Value(clientKey{}).(Client)
+	return client
+}
+
+// WithActor stores the authenticated caller on the context. Audit events
+// recorded during the request name them as the actor.
+func WithActor(ctx context.Context, userID uint) context.Context {
+	return context.WithValue(ctx, actorKey{}, userID)
+}
+
+// Entry is an event to record. Zero IDs mean "none". The authenticated
+// caller from the context, if any, takes precedence over ActorID.
+type Entry struct {
+	ActorID  uint
+	UserID   uint
+	Action   string
+	Target   string
+	TargetID uint
+	Details  map[string]any
+}
+
+// Service records and searches the audit log. One instance is shared through
+// core.AppDeps.
+type Service struct {
+	repo   *repositories.AuditRepository
+	logger *log.Logger
+}
+
+func NewService(repo *repositories.AuditRepository, logger *log.Logger) *Service {
+	return &Service{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+// WithDB returns a service that records through db, so events written in a
+// transaction commit or roll back with it
+func (s *Service) WithDB(db *gorm.DB) *Service {
+	return &Service{
+		repo:   repositories.NewAuditRepo(db),
+		logger: s.logger,
+	}
+}
+
+// Record stores an event with the client from ctx. A failure is logged but
+// never fails the operation being audited.
+func (s *Service) Record(ctx context.Context, entry Entry) {
+	client := ClientFromContext(ctx)
+
+	event := &models.AuditEvent{
+		ActorID:   optionalID(entry.ActorID),
+		UserID:    optionalID(entry.UserID),
+		Action:    entry.Action,
+		Target:    entry.Target,
+		TargetID:  optionalID(entry.TargetID),
+		IPAddress: client.IPAddress,
+		UserAgent: client.UserAgent,
+	}
+
+	if actor, ok := ctx.Value(actorKey{}).(uint); ok {
+		event.ActorID = &actor
+	}
+
+	if len(entry.Details) > 0 {
+		details, err := json.Marshal(entry.Details)
+		if err != nil {
+			s.logger.Printf("audit: encoding details of %s failed: %v", entry.Action, err)
+		} else {
+			event.Details = details
+		}
+	}
+
+	// Still record the event if the client went away mid-request
+	err := s.repo.Create(context.WithoutCancel(ctx), event)
+	if err != nil {
+		s.logger.Printf("audit: recording %s failed: %v", entry.Action, err)
+	}
+}
+
+// Search returns events matching filter, newest first
+func (s *Service) Search(ctx context.Context, filter dtos.AuditFilter) ([]models.AuditEvent, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = defaultLimit
+	}
+	if filter.Limit > maxLimit {
+		filter.Limit = maxLimit
+	}
+
+	return s.repo.Search(ctx, filter)
+}
+
+// ListForUser returns the events concerning one user's account and data
+func (s *Service) ListForUser(ctx context.Context, userID uint, filter dtos.AuditFilter) ([]models.AuditEvent, error) {
+	filter.UserID = &userID
+	filter.ActorID = nil
+
+	return s.Search(ctx, filter)
+}
+
+// Diff pairs each value of a GORM updates map with the current value
+// of that column, leaving out columns that don't actually change
+func Diff(current, updates map[string]any) map[string]dtos.AuditChange {
+	changes := map[string]dtos.AuditChange{}
+
+	for column, value := range updates {
+		from := derefValue(current[column])
+		to := derefValue(value)
+
+		if reflect.DeepEqual(from, to) {
+			continue
+		}
+
+		changes[column] = dtos.AuditChange{From: from, To: to}
+	}
+
+	return changes
+}
+
+// derefValue unwraps pointers so *string and string compare and encode alike
+func derefValue(v any) any {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return nil
+		}
+		rv = rv.Elem()
+	}
+
+	if !rv.IsValid() {
+		return nil
+	}
+
+	return rv.Interface()
+}
+
+func optionalID(id uint) *uint {
+	if id == 0 {
+		return nil
+	}
+	return &id
+}
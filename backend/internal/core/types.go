@@ -2,32 +2,153 @@ package core
 
 import (
 	"log"
+	"net/http"
 	"time"
 
+	"github.com/georgiev098/film-manager/backend/internal/audit"
+	"github.com/georgiev098/film-manager/backend/internal/jwtkeys"
+	"github.com/georgiev098/film-manager/backend/internal/mailer"
+	"github.com/georgiev098/film-manager/backend/internal/password"
+	"github.com/georgiev098/film-manager/backend/internal/ratelimit"
 	"github.com/go-playground/validator/v10"
 	"gorm.io/gorm"
 )
 
 type Config struct {
-	Port           int
-	Env            string
-	Api            string
-	AllowedOrigins []string
+	Port   int
+	Env    string
+	Api    string
+	AppURL string // frontend base URL, used in emailed links
+
+	CORS CORS
+
+	SecurityHeaders SecurityHeaders
 
 	Db struct {
 		Dsn string
 	}
 
 	Auth struct {
-		AccessSecret string
-		AccessTTL    time.Duration
-		RefreshTTL   time.Duration
+		AccessSecret       string // HMAC key for internal tokens such as MFA challenges
+		Issuer             string // "iss" and "aud" of access tokens
+		Audience           string
+		SigningKeyFile     string   // PEM private key (RSA or Ed25519) that signs access tokens
+		VerificationKeys   []string // PEM files of previous keys, still accepted while rotating
+		AccessTTL          time.Duration
+		RefreshTTL         time.Duration
+		PasswordResetTTL   time.Duration
+		VerificationSecret string
+		VerificationTTL    time.Duration
+
+		// What unverified accounts may do, one of the UnverifiedPolicy* values
+		UnverifiedPolicy    string
+		UnverifiedItemLimit int
+	}
+
+	Mail mailer.Config
+
+	Cookies CookiePolicy
+
+	LoginProtection LoginProtection
+
+	DataRequests DataRequests
+
+	// argon2id cost for new password hashes
+	Password password.Params
+
+	PasswordPolicy struct {
+		MinLength    int
+		MaxLength    int
+		MinScore     int // zxcvbn score 0-4
+		BannedWords  []string
+		BreachedPath string // optional breached-password hash list, see password.BreachedList
 	}
+
+	// External identity providers for OpenID Connect login
+	OIDC struct {
+		RedirectBaseURL string // public URL of this API, callbacks are <base>/auth/oidc/<name>/callback
+		Providers       []OIDCProvider
+	}
+}
+
+// What accounts that haven't verified their email may do
+const (
+	UnverifiedPolicyNone     = "none"      // anything
+	UnverifiedPolicyReadOnly = "read_only" // no writes to gear or the collection
+	UnverifiedPolicyLimited  = "limited"   // own at most UnverifiedItemLimit cameras and lenses
+)
+
+// LoginProtection limits password guessing on POST /auth/login
+type LoginProtection struct {
+	Store            string // memory | sql
+	IPLimit          int    // login attempts per IP and IPWindow
+	IPWindow         time.Duration
+	DelayAfter       int // failed logins of an account before delays start, doubling from one second
+	LockoutThreshold int // failed logins within LockoutWindow that lock the account
+	LockoutWindow    time.Duration
+	LockoutDuration  time.Duration
+
+	// Requests that email someone, such as password resets, per IP and per
+	// address within MailWindow. Requests over either limit send nothing.
+	MailIPLimit      int
+	MailAddressLimit int
+	MailWindow       time.Duration
+}
+
+// CORS controls which browser origins may call the API. Credentials are always
+// allowed, so origins must be listed explicitly.
+type CORS struct {
+	AllowedOrigins []string // e.g. "https://app.example.com"; one "*" wildcard per origin, as in "https://*.example.com"
+	AllowedMethods []string
+	AllowedHeaders []string
+	ExposedHeaders []string
+	MaxAge         int // seconds browsers may cache a preflight response
+}
+
+// SecurityHeaders are sent with every response. Empty values leave a header out.
+type SecurityHeaders struct {
+	HSTSMaxAge            time.Duration // 0 disables Strict-Transport-Security
+	HSTSIncludeSubdomains bool
+	HSTSPreload           bool
+	ContentSecurityPolicy string
+	PrintCSP              string // replaces ContentSecurityPolicy on printable HTML pages
+	ReferrerPolicy        string
+	PermissionsPolicy     string
+}
+
+// CookiePolicy applies to every cookie the API sets
+type CookiePolicy struct {
+	Secure     bool
+	Domain     string // empty for host-only cookies
+	HostPrefix bool   // use the __Host- name prefix; implies Secure, path "/" and no Domain
+	SameSite   http.SameSite
+}
+
+// DataRequests configures the background jobs behind data exports and account erasure
+type DataRequests struct {
+	ExportDir          string        // where export archives are written
+	ExportTTL          time.Duration // how long an archive can be downloaded
+	ErasureGracePeriod time.Duration // time to cancel before an account is erased
+	PollInterval       time.Duration
+}
+
+type OIDCProvider struct {
+	Name         string // used in URLs, e.g. "company"
+	DisplayName  string
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	Scopes       []string
 }
 
 type AppDeps struct {
-	DB       *gorm.DB
-	Logger   *log.Logger
-	Config   Config
-	Validate *validator.Validate
+	DB        *gorm.DB
+	Logger    *log.Logger
+	Config    Config
+	Validate  *validator.Validate
+	Mailer    mailer.Mailer
+	Keys      *jwtkeys.KeySet
+	Limits    ratelimit.Store
+	Passwords *password.Hasher
+	Audit     *audit.Service
 }
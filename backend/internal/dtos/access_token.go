@@ -0,0 +1,26 @@
+package dtos
+
+import "time"
+
+type CreateAccessTokenRequest struct {
+	Name          string   `json:"name" validate:"required,max=100"`
+	Scopes        []string `json:"scopes" validate:"required,min=1,dive,oneof=cameras:read cameras:write lenses:read lenses:write collection:read collection:write light:read film:read film:write shoots:read shoots:write archive:read archive:write prints:read prints:write"`
+	ExpiresInDays int      `json:"expires_in_days" validate:"required,min=1,max=365"`
+}
+
+// AccessToken describes a personal access token without its secret
+type AccessToken struct {
+	ID         uint       `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+}
+
+// CreatedAccessToken is returned once, when the token is created
+type CreatedAccessToken struct {
+	AccessToken
+	Token string `json:"token"`
+}
@@ -0,0 +1,11 @@
+package dtos
+
+import "github.com/georgiev098/film-manager/backend/internal/models"
+
+type RoleUpdate struct {
+	Role models.Role `json:"role" validate:"required,oneof=user moderator admin"`
+}
+
+type PasswordReset struct {
+	Password string `json:"password" validate:"required,password"`
+}
@@ -0,0 +1,19 @@
+package dtos
+
+// ArchiveLocationUpdate can move a location, e.g. a binder to another box.
+// A parent_id of 0 takes a binder out of its box.
+type ArchiveLocationUpdate struct {
+	Label    *string `json:"label,omitempty" validate:"omitempty,min=1,max=100"`
+	Position *int    `json:"position,omitempty" validate:"omitempty,gte=0"`
+	ParentID *uint   `json:"parent_id,omitempty"`
+	Notes    *string `json:"notes,omitempty" validate:"omitempty,max=500"`
+}
+
+// FrameUpdate files a frame on a strip of its roll's page; a strip_id of 0
+// takes it off
+type FrameUpdate struct {
+	Number  *int    `json:"number,omitempty" validate:"omitempty,gte=0,lte=100"`
+	StripID *uint   `json:"strip_id,omitempty"`
+	Title   *string `json:"title,omitempty" validate:"omitempty,max=100"`
+	Notes   *string `json:"notes,omitempty" validate:"omitempty,max=500"`
+}
@@ -0,0 +1,24 @@
+package dtos
+
+import "time"
+
+// AuditFilter narrows an audit log query. Zero values don't filter.
+// Results are newest first; pass the last ID seen as BeforeID for the next page.
+type AuditFilter struct {
+	UserID    *uint
+	ActorID   *uint
+	Action    string
+	Target    string
+	TargetID  *uint
+	IPAddress string
+	From      *time.Time
+	To        *time.Time
+	BeforeID  uint
+	Limit     int
+}
+
+// AuditChange is one changed column in an update event
+type AuditChange struct {
+	From any `json:"from"`
+	To   any `json:"to"`
+}
@@ -0,0 +1,167 @@
+package dtos
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/georgiev098/film-manager/backend/internal/models"
+)
+
+// The records below refer to each other by Ref, a key that is only
+// meaningful inside one file. Exports use the database ID.
+
+// CameraRecord is the portable form of a camera used by export and import
+type CameraRecord struct {
+	Ref          string              `json:"ref,omitempty"`
+	Brand        string              `json:"brand"`
+	CameraModel  string              `json:"camera_model"`
+	CameraFormat models.CameraFormat `json:"camera_format"`
+	Year         *int                `json:"year,omitempty"`
+	SerialNumber *string             `json:"serial_number,omitempty"`
+	Notes        *string             `json:"notes,omitempty"`
+	ImageURL     *string             `json:"image_url,omitempty"`
+}
+
+// LensRecord is the portable form of a lens used by export and import
+type LensRecord struct {
+	Ref                string          `json:"ref,omitempty"`
+	Manufacturer       string          `json:"manufacturer"`
+	LensType           models.LensType `json:"lens_type"`
+	ImageStabilization bool            `json:"image_stabilization"`
+	FocalLengthMin     int             `json:"min_focal_length"`
+	FocalLengthMax     int             `json:"max_focal_length"`
+	MinApertureStr     string          `json:"min_aperture"`
+	MaxApertureStr     string          `json:"max_aperture"`
+	Mount              string          `json:"mount"`
+	ImageURL           *string         `json:"image_url,omitempty"`
+	Notes              *string         `json:"notes,omitempty"`
+}
+
+type FilmStockRecord struct {
+	Ref          string              `json:"ref,omitempty"`
+	Manufacturer string              `json:"manufacturer"`
+	Name         string              `json:"name"`
+	ISO          int                 `json:"iso"`
+	Format       models.CameraFormat `json:"format"`
+	Process      models.FilmProcess  `json:"process"`
+	Notes        *string             `json:"notes,omitempty"`
+}
+
+// ArchiveRecord is a box, binder, page or strip. Parents come before the
+// locations filed in them.
+type ArchiveRecord struct {
+	Ref      string             `json:"ref,omitempty"`
+	Kind     models.ArchiveKind `json:"kind"`
+	Label    string             `json:"label"`
+	Position int                `json:"position"`
+	Parent   *string            `json:"parent,omitempty"`
+	Notes    *string            `json:"notes,omitempty"`
+}
+
+type RollRecord struct {
+	Ref             string            `json:"ref,omitempty"`
+	FilmStock       string            `json:"film_stock"`
+	Camera          *string           `json:"camera,omitempty"`
+	Status          models.RollStatus `json:"status"`
+	LoadedAt        *time.Time        `json:"loaded_at,omitempty"`
+	ExposedAt       *time.Time        `json:"exposed_at,omitempty"`
+	DevelopedAt     *time.Time        `json:"developed_at,omitempty"`
+	ArchiveLocation *string           `json:"archive_location,omitempty"` // the page it is filed on
+	Notes           *string           `json:"notes,omitempty"`
+}
+
+type FrameRecord struct {
+	Ref    string  `json:"ref,omitempty"`
+	Roll   string  `json:"roll"`
+	Number int     `json:"number"`
+	Strip  *string `json:"strip,omitempty"`
+	Title  *string `json:"title,omitempty"`
+	Notes  *string `json:"notes,omitempty"`
+}
+
+// PrintRecord keeps its version and what changed, earlier versions come first
+type PrintRecord struct {
+	Ref              string          `json:"ref,omitempty"`
+	Frame            string          `json:"frame"`
+	Version          int             `json:"version"`
+	Previous         *string         `json:"previous,omitempty"`
+	Paper            string          `json:"paper"`
+	PaperSize        string          `json:"paper_size"`
+	Contrast         *string         `json:"contrast,omitempty"`
+	EnlargerHeightCM *float64        `json:"enlarger_height_cm,omitempty"`
+	Aperture         *string         `json:"aperture,omitempty"`
+	ExposureSeconds  float64         `json:"exposure_seconds"`
+	TestStrip        *string         `json:"test_strip,omitempty"`
+	DodgeBurn        *string         `json:"dodge_burn,omitempty"`
+	Toning           *string         `json:"toning,omitempty"`
+	Notes            *string         `json:"notes,omitempty"`
+	Changes          json.RawMessage `json:"changes,omitempty"`
+}
+
+type KitRecord struct {
+	Ref     string   `json:"ref,omitempty"`
+	Name    string   `json:"name"`
+	Notes   *string  `json:"notes,omitempty"`
+	Cameras []string `json:"cameras,omitempty"`
+	Lenses  []string `json:"lenses,omitempty"`
+}
+
+type ShootRecord struct {
+	Ref       string  `json:"ref,omitempty"`
+	Title     string  `json:"title"`
+	Date      string  `json:"date"`
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+	Timezone  string  `json:"timezone,omitempty"`
+	Kit       *string `json:"kit,omitempty"`
+	Notes     *string `json:"notes,omitempty"`
+}
+
+// Collection is everything a user owns, as exported by GET /export.
+// Version 1 files hold only cameras and lenses.
+type Collection struct {
+	Version    int               `json:"version"`
+	Cameras    []CameraRecord    `json:"cameras"`
+	Lenses     []LensRecord      `json:"lenses"`
+	FilmStocks []FilmStockRecord `json:"film_stocks"`
+	Archive    []ArchiveRecord   `json:"archive"`
+	Rolls      []RollRecord      `json:"rolls"`
+	Frames     []FrameRecord     `json:"frames"`
+	Prints     []PrintRecord     `json:"prints"`
+	Kits       []KitRecord       `json:"kits"`
+	Shoots     []ShootRecord     `json:"shoots"`
+}
+
+type ImportAction string
+
+const (
+	ImportCreate ImportAction = "create"
+	ImportUpdate ImportAction = "update"
+	ImportSkip   ImportAction = "skip"
+)
+
+// ImportItemResult describes what happened (or would happen) to one imported record
+type ImportItemResult struct {
+	Kind   string            `json:"kind"` // camera | lens | film_stock | archive | roll | frame | print | kit | shoot
+	Key    string            `json:"key"`
+	Action ImportAction      `json:"action"`
+	ID     uint              `json:"id,omitempty"`
+	Reason string            `json:"reason,omitempty"`
+	Errors map[string]string `json:"errors,omitempty"`
+}
+
+type ImportReport struct {
+	DryRun  bool               `json:"dry_run"`
+	Created int                `json:"created"`
+	Updated int                `json:"updated"`
+	Skipped int                `json:"skipped"`
+	Items   []ImportItemResult `json:"items"`
+}
+
+// ExternalImportReport is the result of importing another app's export
+type ExternalImportReport struct {
+	Source string `json:"source"`
+	ImportReport
+
+	UnmappedFields map[string]int `json:"unmapped_fields"`
+}
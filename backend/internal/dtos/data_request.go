@@ -0,0 +1,47 @@
+package dtos
+
+import "time"
+
+// ErasureRequest confirms an account erasure. The password is required for
+// accounts that have one.
+type ErasureRequest struct {
+	Password string `json:"password"`
+}
+
+// Records written to a data export archive. Secrets such as token hashes are left out.
+
+type ExportSession struct {
+	UserAgent  string     `json:"user_agent"`
+	IPAddress  string     `json:"ip_address"`
+	StartedAt  time.Time  `json:"started_at"`
+	LastUsedAt time.Time  `json:"last_used_at"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	Revoked    bool       `json:"revoked"`
+	DeletedAt  *time.Time `json:"deleted_at,omitempty"`
+}
+
+type ExportAccessToken struct {
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	DeletedAt  *time.Time `json:"deleted_at,omitempty"`
+}
+
+type ExportIdentity struct {
+	Provider  string     `json:"provider"`
+	Subject   string     `json:"subject"`
+	Email     string     `json:"email"`
+	LinkedAt  time.Time  `json:"linked_at"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+}
+
+// ExportImage is an image attached to a piece of gear. Images are stored as
+// external URLs, so the archive lists them rather than embedding the files.
+type ExportImage struct {
+	Item   string `json:"item"` // camera | lens
+	ItemID uint   `json:"item_id"`
+	URL    string `json:"url"`
+}
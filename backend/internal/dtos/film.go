@@ -0,0 +1,22 @@
+package dtos
+
+import "github.com/georgiev098/film-manager/backend/internal/models"
+
+type FilmStockUpdate struct {
+	Manufacturer *string              `json:"manufacturer,omitempty" validate:"omitempty,min=1"`
+	Name         *string              `json:"name,omitempty" validate:"omitempty,min=1"`
+	ISO          *int                 `json:"iso,omitempty" validate:"omitempty,gt=0,lte=25600"`
+	Format       *models.CameraFormat `json:"format,omitempty" validate:"omitempty,oneof=35mm 120mm"`
+	Process      *models.FilmProcess  `json:"process,omitempty" validate:"omitempty,oneof=C-41 E-6 BW"`
+	Notes        *string              `json:"notes,omitempty" validate:"omitempty,max=500"`
+}
+
+// RollUpdate moves a roll on, e.g. {"status": "exposed"} when it comes out of
+// the camera. A loaded roll can also be moved to another camera, and a
+// developed one filed on a page of the archive; 0 takes it off again.
+type RollUpdate struct {
+	CameraID          *uint              `json:"camera_id,omitempty"`
+	Status            *models.RollStatus `json:"status,omitempty" validate:"omitempty,oneof=loaded exposed developed"`
+	ArchiveLocationID *uint              `json:"archive_location_id,omitempty"`
+	Notes             *string            `json:"notes,omitempty" validate:"omitempty,max=500"`
+}
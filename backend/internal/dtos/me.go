@@ -0,0 +1,27 @@
+package dtos
+
+// ProfileUpdate is a partial update of the current user's name and preferences
+type ProfileUpdate struct {
+	FirstName *string `json:"first_name,omitempty" validate:"omitempty,min=2,max=50"`
+	LastName  *string `json:"last_name,omitempty" validate:"omitempty,min=2,max=50"`
+	Units     *string `json:"units,omitempty" validate:"omitempty,oneof=metric imperial"`
+	Currency  *string `json:"currency,omitempty" validate:"omitempty,iso4217"`
+	Timezone  *string `json:"timezone,omitempty" validate:"omitempty,timezone"`
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" validate:"required"`
+	NewPassword     string `json:"new_password" validate:"required,password"`
+}
+
+type ChangeEmailRequest struct {
+	Email    string `json:"email" validate:"required,email"`
+	Password string `json:"password" validate:"required"`
+}
+
+// DeleteAccountRequest confirms deleting the account. Accounts without a
+// password (identity provider only) may leave it empty, but must have
+// signed in within the last few minutes.
+type DeleteAccountRequest struct {
+	Password string `json:"password"`
+}
@@ -0,0 +1,22 @@
+package dtos
+
+type MFACodeRequest struct {
+	Code string `json:"code" validate:"required"`
+}
+
+// MFAEnrollRequest re-authenticates the user. Password is not needed by
+// accounts without one, if they signed in recently.
+type MFAEnrollRequest struct {
+	Password string `json:"password"`
+}
+
+type MFAConfirmRequest struct {
+	Code     string `json:"code" validate:"required"`
+	Password string `json:"password"`
+}
+
+// MFALoginRequest completes a login; Code is a TOTP code or a recovery code
+type MFALoginRequest struct {
+	MFAToken string `json:"mfa_token" validate:"required"`
+	Code     string `json:"code" validate:"required"`
+}
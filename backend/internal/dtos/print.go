@@ -0,0 +1,22 @@
+package dtos
+
+// Reprint holds the settings that change for the next attempt at a print;
+// everything left out is carried over from the previous one
+type Reprint struct {
+	Paper            *string  `json:"paper,omitempty" validate:"omitempty,min=1,max=100"`
+	PaperSize        *string  `json:"paper_size,omitempty" validate:"omitempty,min=1,max=20"`
+	Contrast         *string  `json:"contrast,omitempty" validate:"omitempty,max=20"`
+	EnlargerHeightCM *float64 `json:"enlarger_height_cm,omitempty" validate:"omitempty,gt=0,lte=300"`
+	Aperture         *string  `json:"aperture,omitempty" validate:"omitempty,contains=f/"`
+	ExposureSeconds  *float64 `json:"exposure_seconds,omitempty" validate:"omitempty,gt=0,lte=3600"`
+	TestStrip        *string  `json:"test_strip,omitempty" validate:"omitempty,max=500"`
+	DodgeBurn        *string  `json:"dodge_burn,omitempty" validate:"omitempty,max=1000"`
+	Toning           *string  `json:"toning,omitempty" validate:"omitempty,max=200"`
+	Notes            *string  `json:"notes,omitempty" validate:"omitempty,max=500"`
+}
+
+// PrintUpdate annotates a print. Its settings stay as they were printed;
+// changing them is a reprint.
+type PrintUpdate struct {
+	Notes *string `json:"notes" validate:"omitempty,max=500"`
+}
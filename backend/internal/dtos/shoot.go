@@ -0,0 +1,27 @@
+package dtos
+
+// KitRequest creates a kit from gear the user already has
+type KitRequest struct {
+	Name      string  `json:"name" validate:"required,max=100"`
+	Notes     *string `json:"notes" validate:"omitempty,max=500"`
+	CameraIDs []uint  `json:"camera_ids" validate:"max=50"`
+	LensIDs   []uint  `json:"lens_ids" validate:"max=50"`
+}
+
+// KitUpdate replaces the kit's cameras or lenses when their IDs are given
+type KitUpdate struct {
+	Name      *string `json:"name,omitempty" validate:"omitempty,min=1,max=100"`
+	Notes     *string `json:"notes,omitempty" validate:"omitempty,max=500"`
+	CameraIDs *[]uint `json:"camera_ids,omitempty" validate:"omitempty,max=50"`
+	LensIDs   *[]uint `json:"lens_ids,omitempty" validate:"omitempty,max=50"`
+}
+
+type ShootUpdate struct {
+	Title     *string  `json:"title,omitempty" validate:"omitempty,min=1,max=100"`
+	Date      *string  `json:"date,omitempty" validate:"omitempty,datetime=2006-01-02"`
+	Latitude  *float64 `json:"latitude,omitempty" validate:"omitempty,gte=-90,lte=90"`
+	Longitude *float64 `json:"longitude,omitempty" validate:"omitempty,gte=-180,lte=180"`
+	Timezone  *string  `json:"timezone,omitempty" validate:"omitempty,timezone"`
+	KitID     *uint    `json:"kit_id,omitempty"`
+	Notes     *string  `json:"notes,omitempty" validate:"omitempty,max=500"`
+}
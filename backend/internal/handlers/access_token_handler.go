@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/georgiev098/film-manager/backend/internal/core"
+	"github.com/georgiev098/film-manager/backend/internal/dtos"
+	"github.com/georgiev098/film-manager/backend/internal/helpers"
+	"github.com/georgiev098/film-manager/backend/internal/middlewares"
+	"github.com/georgiev098/film-manager/backend/internal/repositories"
+	"github.com/georgiev098/film-manager/backend/internal/services"
+	"github.com/go-chi/chi/v5"
+)
+
+type AccessTokenHandler struct {
+	deps    *core.AppDeps
+	service *services.AccessTokenService
+}
+
+func NewAccessTokenHandler(deps *core.AppDeps) *AccessTokenHandler {
+	service := services.NewAccessTokenService(
+		repositories.NewPersonalAccessTokenRepo(deps.DB),
+		repositories.NewUserRepo(deps.DB),
+	)
+
+	return &AccessTokenHandler{
+		deps:    deps,
+		service: service,
+	}
+}
+
+func (h *AccessTokenHandler) GetTokens(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middlewares.GetUserIDFromContext(r.Context())
+	if !ok {
+		helpers.WriteProblem(w, r, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	tokens, err := h.service.List(r.Context(), userID)
+	if err != nil {
+		helpers.WriteError(w, r, h.deps.Logger, err)
+		return
+	}
+
+	helpers.WriteJSON(w, http.StatusOK, tokens, nil)
+}
+
+// CreateToken returns the new token in plain text. It can't be retrieved again.
+func (h *AccessTokenHandler) CreateToken(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middlewares.GetUserIDFromContext(r.Context())
+	if !ok {
+		helpers.WriteProblem(w, r, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	var input dtos.CreateAccessTokenRequest
+
+	err := helpers.ReadJSON(w, r, &input)
+	if err != nil {
+		helpers.WriteProblem(w, r, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	err = h.deps.Validate.Struct(input)
+	if err != nil {
+		helpers.WriteValidationProblem(w, r, err)
+		return
+	}
+
+	token, err := h.service.Create(r.Context(), userID, input)
+	if err != nil {
+		helpers.WriteError(w, r, h.deps.Logger, err)
+		return
+	}
+
+	helpers.WriteJSON(w, http.StatusCreated, token, nil)
+}
+
+func (h *AccessTokenHandler) RevokeToken(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middlewares.GetUserIDFromContext(r.Context())
+	if !ok {
+		helpers.WriteProblem(w, r, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	tokenID, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		helpers.WriteProblem(w, r, http.StatusBadRequest, "invalid token id")
+		return
+	}
+
+	err = h.service.Revoke(r.Context(), userID, uint(tokenID))
+	if err != nil {
+		helpers.WriteError(w, r, h.deps.Logger, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,205 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/georgiev098/film-manager/backend/internal/core"
+	"github.com/georgiev098/film-manager/backend/internal/dtos"
+	"github.com/georgiev098/film-manager/backend/internal/helpers"
+	"github.com/georgiev098/film-manager/backend/internal/middlewares"
+	"github.com/georgiev098/film-manager/backend/internal/password"
+	"github.com/georgiev098/film-manager/backend/internal/repositories"
+	"github.com/georgiev098/film-manager/backend/internal/services"
+	"github.com/go-chi/chi/v5"
+)
+
+type AdminHandler struct {
+	deps        *core.AppDeps
+	userService *services.UserService
+	authService *services.AuthService
+	loginGuard  *services.LoginGuard
+}
+
+func NewAdminHandler(deps *core.AppDeps) *AdminHandler {
+	userRepo := repositories.NewUserRepo(deps.DB)
+	refreshTokenRepo := repositories.CreateRefreshTokenRepository(deps.DB)
+
+	userService := services.NewUserService(userRepo, deps.Passwords, deps.Audit)
+	authService := services.NewAuthService(refreshTokenRepo, userRepo, deps.Audit, deps.Logger, deps.Keys, deps.Config.Auth.Issuer, deps.Config.Auth.Audience, deps.Config.Auth.AccessTTL, deps.Config.Auth.RefreshTTL)
+
+	return &AdminHandler{
+		deps:        deps,
+		userService: userService,
+		authService: authService,
+		loginGuard:  services.NewLoginGuard(deps.Limits, deps.Config.LoginProtection),
+	}
+}
+
+func (h *AdminHandler) GetAllUsers(w http.ResponseWriter, r *http.Request) {
+	users, err := h.userService.GetAllUsers(r.Context())
+	if err != nil {
+		helpers.WriteError(w, r, h.deps.Logger, err)
+		return
+	}
+
+	helpers.WriteJSON(w, http.StatusOK, users, nil)
+}
+
+func (h *AdminHandler) DisableUser(w http.ResponseWriter, r *http.Request) {
+	h.setDisabled(w, r, true)
+}
+
+func (h *AdminHandler) EnableUser(w http.ResponseWriter, r *http.Request) {
+	h.setDisabled(w, r, false)
+}
+
+func (h *AdminHandler) setDisabled(w http.ResponseWriter, r *http.Request, disabled bool) {
+	ctx := r.Context()
+
+	targetID, ok := h.targetUserID(w, r)
+	if !ok {
+		return
+	}
+
+	user, err := h.userService.SetDisabled(ctx, targetID, disabled)
+	if err != nil {
+		helpers.WriteError(w, r, h.deps.Logger, err)
+		return
+	}
+
+	// A disabled user must not be able to refresh their way back in
+	if disabled {
+		err = h.authService.LogoutAll(ctx, targetID)
+		if err != nil {
+			helpers.WriteError(w, r, h.deps.Logger, err)
+			return
+		}
+	}
+
+	helpers.WriteJSON(w, http.StatusOK, user, nil)
+}
+
+func (h *AdminHandler) UpdateRole(w http.ResponseWriter, r *http.Request) {
+	targetID, ok := h.targetUserID(w, r)
+	if !ok {
+		return
+	}
+
+	var input dtos.RoleUpdate
+
+	err := helpers.ReadJSON(w, r, &input)
+	if err != nil {
+		helpers.WriteProblem(w, r, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	err = h.deps.Validate.Struct(input)
+	if err != nil {
+		helpers.WriteValidationProblem(w, r, err)
+		return
+	}
+
+	user, err := h.userService.SetRole(r.Context(), targetID, input.Role)
+	if err != nil {
+		helpers.WriteError(w, r, h.deps.Logger, err)
+		return
+	}
+
+	helpers.WriteJSON(w, http.StatusOK, user, nil)
+}
+
+// UnlockUser lifts a login lockout or delay before it runs out
+func (h *AdminHandler) UnlockUser(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	targetID, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		helpers.WriteProblem(w, r, http.StatusBadRequest, "invalid user id")
+		return
+	}
+
+	user, err := h.userService.GetUserByID(ctx, uint(targetID))
+	if err != nil {
+		helpers.WriteError(w, r, h.deps.Logger, err)
+		return
+	}
+
+	err = h.loginGuard.Unlock(ctx, user.ID, user.Email)
+	if err != nil {
+		helpers.WriteError(w, r, h.deps.Logger, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ResetPassword sets a new password chosen by the admin and logs the user out everywhere
+func (h *AdminHandler) ResetPassword(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// Admins change their own password through /me, which asks for the current one
+	targetID, ok := h.targetUserID(w, r)
+	if !ok {
+		return
+	}
+
+	var input dtos.PasswordReset
+
+	err := helpers.ReadJSON(w, r, &input)
+	if err != nil {
+		helpers.WriteProblem(w, r, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	user, err := h.userService.GetUserByID(ctx, targetID)
+	if err != nil {
+		helpers.WriteError(w, r, h.deps.Logger, err)
+		return
+	}
+
+	validateCtx := password.WithUserInputs(ctx, user.Email, user.FirstName, user.LastName)
+
+	err = h.deps.Validate.StructCtx(validateCtx, input)
+	if err != nil {
+		helpers.WriteValidationProblem(w, r, err)
+		return
+	}
+
+	err = h.userService.SetPassword(ctx, targetID, input.Password)
+	if err != nil {
+		helpers.WriteError(w, r, h.deps.Logger, err)
+		return
+	}
+
+	err = h.authService.LogoutAll(ctx, targetID)
+	if err != nil {
+		helpers.WriteError(w, r, h.deps.Logger, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// targetUserID parses {id} and stops admins from locking themselves out
+func (h *AdminHandler) targetUserID(w http.ResponseWriter, r *http.Request) (uint, bool) {
+	idParam := chi.URLParam(r, "id")
+	targetID, err := strconv.ParseUint(idParam, 10, 64)
+	if err != nil {
+		helpers.WriteProblem(w, r, http.StatusBadRequest, "invalid user id")
+		return 0, false
+	}
+
+	adminID, ok := middlewares.GetUserIDFromContext(r.Context())
+	if !ok {
+		helpers.WriteProblem(w, r, http.StatusUnauthorized, "unauthorized")
+		return 0, false
+	}
+
+	if uint(targetID) == adminID {
+		helpers.WriteProblem(w, r, http.StatusBadRequest, "cannot change your own account")
+		return 0, false
+	}
+
+	return uint(targetID), true
+}
@@ -0,0 +1,265 @@
+package handlers
+
+import (
+	"bytes"
+	"html/template"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/georgiev098/film-manager/backend/internal/core"
+	"github.com/georgiev098/film-manager/backend/internal/dtos"
+	"github.com/georgiev098/film-manager/backend/internal/helpers"
+	"github.com/georgiev098/film-manager/backend/internal/middlewares"
+	"github.com/georgiev098/film-manager/backend/internal/models"
+	"github.com/georgiev098/film-manager/backend/internal/services"
+	"github.com/go-chi/chi/v5"
+)
+
+type ArchiveHandler struct {
+	deps    *core.AppDeps
+	service *services.ArchiveService
+}
+
+func NewArchiveHandler(deps *core.AppDeps) *ArchiveHandler {
+	return &ArchiveHandler{
+		deps:    deps,
+		service: services.NewArchiveService(deps.DB),
+	}
+}
+
+func (h *ArchiveHandler) GetLocations(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middlewares.GetUserIDFromContext(r.Context())
+	if !ok {
+		helpers.WriteProblem(w, r, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	locations, err := h.service.GetAllForUser(r.Context(), userID)
+	if err != nil {
+		helpers.WriteError(w, r, h.deps.Logger, err)
+		return
+	}
+
+	helpers.WriteJSON(w, http.StatusOK, locations, nil)
+}
+
+func (h *ArchiveHandler) CreateLocation(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middlewares.GetUserIDFromContext(r.Context())
+	if !ok {
+		helpers.WriteProblem(w, r, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	var location models.ArchiveLocation
+
+	err := helpers.ReadJSON(w, r, &location)
+	if err != nil {
+		helpers.WriteProblem(w, r, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	location.UserID = userID
+
+	err = h.deps.Validate.Struct(location)
+	if err != nil {
+		helpers.WriteValidationProblem(w, r, err)
+		return
+	}
+
+	err = h.service.CreateLocation(r.Context(), &location)
+	if err != nil {
+		helpers.WriteError(w, r, h.deps.Logger, err)
+		return
+	}
+
+	helpers.WriteJSON(w, http.StatusCreated, location, nil)
+}
+
+func (h *ArchiveHandler) GetLocationByID(w http.ResponseWriter, r *http.Request) {
+	locationID, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		helpers.WriteProblem(w, r, http.StatusBadRequest, "invalid archive location id")
+		return
+	}
+
+	userID, ok := middlewares.GetUserIDFromContext(r.Context())
+	if !ok {
+		helpers.WriteProblem(w, r, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	location, err := h.service.GetLocationByID(r.Context(), uint(locationID), userID)
+	if err != nil {
+		helpers.WriteError(w, r, h.deps.Logger, err)
+		return
+	}
+
+	helpers.WriteJSON(w, http.StatusOK, location, nil)
+}
+
+func (h *ArchiveHandler) UpdateLocation(w http.ResponseWriter, r *http.Request) {
+	locationID, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		helpers.WriteProblem(w, r, http.StatusBadRequest, "invalid archive location id")
+		return
+	}
+
+	userID, ok := middlewares.GetUserIDFromContext(r.Context())
+	if !ok {
+		helpers.WriteProblem(w, r, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	var input dtos.ArchiveLocationUpdate
+
+	err = helpers.ReadJSON(w, r, &input)
+	if err != nil {
+		helpers.WriteProblem(w, r, http.StatusBadRequest, "invalid payload")
+		return
+	}
+
+	err = h.deps.Validate.Struct(input)
+	if err != nil {
+		helpers.WriteValidationProblem(w, r, err)
+		return
+	}
+
+	location, err := h.service.UpdateLocation(r.Context(), uint(locationID), userID, input)
+	if err != nil {
+		helpers.WriteError(w, r, h.deps.Logger, err)
+		return
+	}
+
+	helpers.WriteJSON(w, http.StatusOK, location, nil)
+}
+
+func (h *ArchiveHandler) DeleteLocation(w http.ResponseWriter, r *http.Request) {
+	locationID, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		helpers.WriteProblem(w, r, http.StatusBadRequest, "invalid archive location id")
+		return
+	}
+
+	userID, ok := middlewares.GetUserIDFromContext(r.Context())
+	if !ok {
+		helpers.WriteProblem(w, r, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	err = h.service.DeleteLocation(r.Context(), uint(locationID), userID)
+	if err != nil {
+		helpers.WriteError(w, r, h.deps.Logger, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// FindFrame tells where the negative of ?frame=<id> is filed
+func (h *ArchiveHandler) FindFrame(w http.ResponseWriter, r *http.Request) {
+	frameID, err := strconv.ParseUint(r.URL.Query().Get("frame"), 10, 64)
+	if err != nil {
+		helpers.WriteProblem(w, r, http.StatusBadRequest, "invalid frame id")
+		return
+	}
+
+	userID, ok := middlewares.GetUserIDFromContext(r.Context())
+	if !ok {
+		helpers.WriteProblem(w, r, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	location, err := h.service.FindFrame(r.Context(), uint(frameID), userID)
+	if err != nil {
+		helpers.WriteError(w, r, h.deps.Logger, err)
+		return
+	}
+
+	helpers.WriteJSON(w, http.StatusOK, location, nil)
+}
+
+// BinderIndex renders a printable HTML page listing what the binder holds
+func (h *ArchiveHandler) BinderIndex(w http.ResponseWriter, r *http.Request) {
+	binderID, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		helpers.WriteProblem(w, r, http.StatusBadRequest, "invalid archive location id")
+		return
+	}
+
+	userID, ok := middlewares.GetUserIDFromContext(r.Context())
+	if !ok {
+		helpers.WriteProblem(w, r, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	index, err := h.service.BinderIndex(r.Context(), uint(binderID), userID)
+	if err != nil {
+		helpers.WriteError(w, r, h.deps.Logger, err)
+		return
+	}
+
+	// Render into a buffer first so a failure can still produce a proper error response
+	var buf bytes.Buffer
+	if err := binderIndexPage.Execute(&buf, index); err != nil {
+		helpers.WriteError(w, r, h.deps.Logger, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	w.Write(buf.Bytes())
+}
+
+var binderIndexPage = template.Must(template.New("binder_index").Funcs(template.FuncMap{
+	"date": func(t *time.Time) string {
+		if t == nil {
+			return ""
+		}
+		return t.Format("2006-01-02")
+	},
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{if .Box}}Box {{.Box.Label}} / {{end}}Binder {{.Binder.Label}}</title>
+<style>
+body { font: 11pt/1.4 sans-serif; margin: 2em; }
+h1 { font-size: 16pt; margin: 0 0 .2em; }
+h2 { font-size: 12pt; margin: 1.2em 0 .3em; border-bottom: 1px solid #000; }
+table { border-collapse: collapse; width: 100%; }
+td, th { text-align: left; padding: .15em .6em .15em 0; vertical-align: top; }
+.muted { color: #555; font-size: 9pt; }
+section { break-inside: avoid; }
+</style>
+</head>
+<body>
+<h1>{{if .Box}}Box {{.Box.Label}} / {{end}}Binder {{.Binder.Label}}</h1>
+<p class="muted">Printed {{.PrintedAt.Format "2006-01-02"}}{{with .Binder.Notes}} &middot; {{.}}{{end}}</p>
+{{range .Pages}}
+<section>
+<h2>Page {{.Page.Label}}</h2>
+{{if .Rolls}}
+<table>
+<tr><th>Roll</th><th>Film</th><th>Camera</th><th>Developed</th></tr>
+{{range .Rolls}}
+<tr><td>#{{.ID}}</td><td>{{with .FilmStock}}{{.Manufacturer}} {{.Name}} ({{.ISO}}){{end}}</td><td>{{with .Camera}}{{.Brand}} {{.CameraModel}}{{end}}</td><td>{{date .DevelopedAt}}</td></tr>
+{{end}}
+</table>
+{{else}}
+<p class="muted">No rolls filed</p>
+{{end}}
+{{if .Strips}}
+<table>
+{{range .Strips}}
+<tr><th>Strip {{.Strip.Label}}</th><td>{{range $i, $f := .Frames}}{{if $i}}, {{end}}{{$f.Number}}{{with $f.Title}} {{.}}{{end}}{{else}}<span class="muted">empty</span>{{end}}</td></tr>
+{{end}}
+</table>
+{{end}}
+</section>
+{{else}}
+<p class="muted">This binder has no pages yet</p>
+{{end}}
+</body>
+</html>
+`))
@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/georgiev098/film-manager/backend/internal/apperrors"
+	"github.com/georgiev098/film-manager/backend/internal/audit"
+	"github.com/georgiev098/film-manager/backend/internal/core"
+	"github.com/georgiev098/film-manager/backend/internal/dtos"
+	"github.com/georgiev098/film-manager/backend/internal/helpers"
+	"github.com/georgiev098/film-manager/backend/internal/middlewares"
+)
+
+type AuditHandler struct {
+	deps    *core.AppDeps
+	service *audit.Service
+}
+
+func NewAuditHandler(deps *core.AppDeps) *AuditHandler {
+	return &AuditHandler{
+		deps:    deps,
+		service: deps.Audit,
+	}
+}
+
+// GetMyEvents lists events concerning the caller's account,
+// filtered by ?action=&target=&target_id=&from=&to=&before_id=&limit=
+func (h *AuditHandler) GetMyEvents(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middlewares.GetUserIDFromContext(r.Context())
+	if !ok {
+		helpers.WriteProblem(w, r, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	filter, err := parseAuditFilter(r.URL.Query())
+	if err != nil {
+		helpers.WriteError(w, r, h.deps.Logger, err)
+		return
+	}
+
+	events, err := h.service.ListForUser(r.Context(), userID, filter)
+	if err != nil {
+		helpers.WriteError(w, r, h.deps.Logger, err)
+		return
+	}
+
+	helpers.WriteJSON(w, http.StatusOK, events, nil)
+}
+
+// Search lists events across all users. Accepts the same filters as
+// GetMyEvents plus ?user_id=&actor_id=&ip=
+func (h *AuditHandler) Search(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	filter, err := parseAuditFilter(query)
+	if err != nil {
+		helpers.WriteError(w, r, h.deps.Logger, err)
+		return
+	}
+
+	filter.UserID, err = optionalUintParam(query, "user_id")
+	if err != nil {
+		helpers.WriteError(w, r, h.deps.Logger, err)
+		return
+	}
+
+	filter.ActorID, err = optionalUintParam(query, "actor_id")
+	if err != nil {
+		helpers.WriteError(w, r, h.deps.Logger, err)
+		return
+	}
+
+	filter.IPAddress = query.Get("ip")
+
+	events, err := h.service.Search(r.Context(), filter)
+	if err != nil {
+		helpers.WriteError(w, r, h.deps.Logger, err)
+		return
+	}
+
+	helpers.WriteJSON(w, http.StatusOK, events, nil)
+}
+
+func parseAuditFilter(query url.Values) (dtos.AuditFilter, error) {
+	filter := dtos.AuditFilter{
+		Action: query.Get("action"),
+		Target: query.Get("target"),
+	}
+
+	var err error
+
+	filter.TargetID, err = optionalUintParam(query, "target_id")
+	if err != nil {
+		return filter, err
+	}
+
+	beforeID, err := optionalUintParam(query, "before_id")
+	if err != nil {
+		return filter, err
+	}
+	if beforeID != nil {
+		filter.BeforeID = *beforeID
+	}
+
+	if v := query.Get("limit"); v != "" {
+		filter.Limit, err = strconv.Atoi(v)
+		if err != nil || filter.Limit < 1 {
+			return filter, apperrors.Validation("invalid limit")
+		}
+	}
+
+	filter.From, err = optionalTimeParam(query, "from")
+	if err != nil {
+		return filter, err
+	}
+
+	filter.To, err = optionalTimeParam(query, "to")
+	if err != nil {
+		return filter, err
+	}
+
+	return filter, nil
+}
+
+func optionalUintParam(query url.Values, name string) (*uint, error) {
+	v := query.Get(name)
+	if v == "" {
+		return nil, nil
+	}
+
+	n, err := strconv.ParseUint(v, 10, 64)
+	if err != nil {
+		return nil, apperrors.Validation("invalid " + name)
+	}
+
+	id := uint(n)
+	return &id, nil
+}
+
+// optionalTimeParam accepts RFC 3339 timestamps or plain YYYY-MM-DD dates (UTC)
+func optionalTimeParam(query url.Values, name string) (*time.Time, error) {
+	v := query.Get(name)
+	if v == "" {
+		return nil, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		t, err = time.Parse("2006-01-02", v)
+		if err != nil {
+			return nil, apperrors.Validation("invalid " + name + ", expected RFC 3339 or YYYY-MM-DD")
+		}
+	}
+
+	return &t, nil
+}
@@ -1,20 +1,30 @@
 package handlers
 
 import (
+	"errors"
+	"math"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/georgiev098/film-manager/backend/internal/core"
 	"github.com/georgiev098/film-manager/backend/internal/dtos"
 	"github.com/georgiev098/film-manager/backend/internal/helpers"
+	"github.com/georgiev098/film-manager/backend/internal/middlewares"
+	"github.com/georgiev098/film-manager/backend/internal/password"
 	"github.com/georgiev098/film-manager/backend/internal/repositories"
 	"github.com/georgiev098/film-manager/backend/internal/services"
+	"github.com/go-chi/chi/v5"
 )
 
 type AuthHandler struct {
-	deps        *core.AppDeps
-	userService *services.UserService
-	authService *services.AuthService
+	deps          *core.AppDeps
+	userService   *services.UserService
+	authService   *services.AuthService
+	passwordReset *services.PasswordResetService
+	verification  *services.EmailVerificationService
+	mfa           *services.MFAService
+	loginGuard    *services.LoginGuard
 }
 
 // constructor
@@ -22,13 +32,36 @@ func NewAuthHandler(deps *core.AppDeps) *AuthHandler {
 	userRepo := repositories.NewUserRepo(deps.DB)
 	refreshTokenRepo := repositories.CreateRefreshTokenRepository(deps.DB)
 
-	userService := services.NewUserService(userRepo)
-	authService := services.NewAuthService(refreshTokenRepo, deps.Config.Auth.AccessSecret, deps.Config.Auth.AccessTTL, deps.Config.Auth.RefreshTTL)
+	userService := services.NewUserService(userRepo, deps.Passwords, deps.Audit)
+	authService := services.NewAuthService(refreshTokenRepo, userRepo, deps.Audit, deps.Logger, deps.Keys, deps.Config.Auth.Issuer, deps.Config.Auth.Audience, deps.Config.Auth.AccessTTL, deps.Config.Auth.RefreshTTL)
+
+	passwordReset := services.NewPasswordResetService(
+		repositories.NewPasswordResetRepo(deps.DB),
+		userRepo,
+		deps.Passwords,
+		deps.Mailer,
+		deps.Logger,
+		deps.Config.AppURL,
+		deps.Config.Auth.PasswordResetTTL,
+	)
+
+	verification := services.NewEmailVerificationService(
+		userRepo,
+		deps.Mailer,
+		deps.Logger,
+		deps.Config.AppURL,
+		deps.Config.Auth.VerificationSecret,
+		deps.Config.Auth.VerificationTTL,
+	)
 
 	return &AuthHandler{
-		deps:        deps,
-		userService: userService,
-		authService: authService,
+		deps:          deps,
+		userService:   userService,
+		authService:   authService,
+		passwordReset: passwordReset,
+		verification:  verification,
+		mfa:           services.NewMFAService(userRepo, repositories.NewRecoveryCodeRepo(deps.DB), deps.Audit, deps.Passwords, deps.Limits, deps.Config.Auth.AccessSecret),
+		loginGuard:    services.NewLoginGuard(deps.Limits, deps.Config.LoginProtection),
 	}
 }
 
@@ -39,112 +72,214 @@ func (h *AuthHandler) SignUp(w http.ResponseWriter, r *http.Request) {
 
 	err := helpers.ReadJSON(w, r, &input)
 	if err != nil {
-		http.Error(w, "invalid request body", http.StatusBadRequest)
+		helpers.WriteProblem(w, r, http.StatusBadRequest, "invalid request body")
 		return
 	}
 
-	err = h.deps.Validate.Struct(input)
+	// The password must not be built from the user's own details
+	validateCtx := password.WithUserInputs(ctx, input.Email, input.FirstName, input.LastName)
+
+	err = h.deps.Validate.StructCtx(validateCtx, input)
 	if err != nil {
-		errMap := helpers.ParseValidationErrors(err)
-		helpers.WriteJSON(w, http.StatusBadRequest, map[string]any{"errors": errMap}, nil)
+		helpers.WriteValidationProblem(w, r, err)
 		return
 	}
 
 	user, err := h.userService.SignUp(ctx, input.Email, input.Password, input.FirstName, input.LastName)
 	if err != nil {
-		h.deps.Logger.Println("signup error:", err)
-		http.Error(w, "could not create user", http.StatusInternalServerError)
+		helpers.WriteError(w, r, h.deps.Logger, err)
 		return
 	}
 
-	accessToken, refreshToken, err := h.authService.Login(ctx, user.ID)
+	// The account is usable right away; a failed mail can be retried via resend
+	err = h.verification.SendVerification(ctx, user)
 	if err != nil {
-		h.deps.Logger.Println("token issue error:", err)
-		http.Error(w, "could not issue tokens", http.StatusInternalServerError)
+		h.deps.Logger.Println("verification mail error:", err)
+	}
+
+	accessToken, refreshToken, err := h.authService.Login(ctx, user, clientInfo(r))
+	if err != nil {
+		helpers.WriteError(w, r, h.deps.Logger, err)
 		return
 	}
 
-	http.SetCookie(w, &http.Cookie{
-		Name:     "refresh_token",
-		Value:    refreshToken,
-		Path:     "/auth",
-		HttpOnly: true,
-		Secure:   false, // true in production (HTTPS)
-		SameSite: http.SameSiteStrictMode,
-		Expires:  time.Now().Add(h.deps.Config.Auth.RefreshTTL),
-	})
+	csrfToken, err := setSessionCookies(w, h.deps, refreshToken)
+	if err != nil {
+		helpers.WriteError(w, r, h.deps.Logger, err)
+		return
+	}
 
 	helpers.WriteJSON(w, http.StatusCreated, map[string]any{
 		"user":         user,
 		"access_token": accessToken,
+		"csrf_token":   csrfToken,
 	}, nil)
 }
 
 func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
+	if !h.checkLoginIP(w, r) {
+		return
+	}
+
 	var input dtos.LoginRequest
 
 	err := helpers.ReadJSON(w, r, &input)
 	if err != nil {
-		http.Error(w, "invalid request body", http.StatusBadRequest)
+		helpers.WriteProblem(w, r, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	wait, err := h.loginGuard.BeginLogin(ctx, input.Email)
+	if err != nil {
+		helpers.WriteError(w, r, h.deps.Logger, err)
+		return
+	}
+	if wait > 0 {
+		writeTooManyRequests(w, r, wait)
 		return
 	}
 
 	user, err := h.userService.Login(ctx, input.Email, input.Password)
 	if err != nil {
-		http.Error(w, "invalid credentials", http.StatusUnauthorized)
+		// Only wrong credentials are guesses, not a disabled account or a failing database
+		if !errors.Is(err, services.ErrInvalidCredentials) {
+			if err := h.loginGuard.LoginAborted(ctx, input.Email); err != nil {
+				h.deps.Logger.Println("login guard error:", err)
+			}
+		}
+		helpers.WriteError(w, r, h.deps.Logger, err)
+		return
+	}
+
+	if err := h.loginGuard.LoginSucceeded(ctx, input.Email); err != nil {
+		h.deps.Logger.Println("login guard error:", err)
+	}
+
+	// With 2FA on, the password only earns a challenge for POST /auth/login/mfa
+	if user.TOTPEnabled {
+		challenge, err := h.mfa.CreateChallenge(user)
+		if err != nil {
+			helpers.WriteError(w, r, h.deps.Logger, err)
+			return
+		}
+
+		helpers.WriteJSON(w, http.StatusOK, map[string]any{
+			"mfa_required": true,
+			"mfa_token":    challenge,
+		}, nil)
 		return
 	}
 
-	accessToken, refreshToken, err := h.authService.Login(ctx, user.ID)
+	accessToken, refreshToken, err := h.authService.Login(ctx, user, clientInfo(r))
 	if err != nil {
-		h.deps.Logger.Println("token issue error:", err)
-		http.Error(w, "could not issue tokens", http.StatusInternalServerError)
+		helpers.WriteError(w, r, h.deps.Logger, err)
 		return
 	}
 
-	http.SetCookie(w, &http.Cookie{
-		Name:     "refresh_token",
-		Value:    refreshToken,
-		Path:     "/auth",
-		HttpOnly: true,
-		Secure:   false, // true in production (HTTPS)
-		SameSite: http.SameSiteStrictMode,
-		Expires:  time.Now().Add(7 * 24 * time.Hour), // match refreshTTL
-	})
+	csrfToken, err := setSessionCookies(w, h.deps, refreshToken)
+	if err != nil {
+		helpers.WriteError(w, r, h.deps.Logger, err)
+		return
+	}
 
 	helpers.WriteJSON(w, http.StatusOK, map[string]any{
 		"access_token": accessToken,
+		"csrf_token":   csrfToken,
 	}, nil)
 }
 
-func (h *AuthHandler) Refresh(w http.ResponseWriter, r *http.Request) {
+// LoginMFA completes a two-step login with a TOTP or recovery code
+func (h *AuthHandler) LoginMFA(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
-	cookie, err := r.Cookie("refresh_token")
+	if !h.checkLoginIP(w, r) {
+		return
+	}
+
+	var input dtos.MFALoginRequest
+
+	err := helpers.ReadJSON(w, r, &input)
 	if err != nil {
-		http.Error(w, "missing refresh token", http.StatusUnauthorized)
+		helpers.WriteProblem(w, r, http.StatusBadRequest, "invalid request body")
 		return
 	}
 
-	oldToken := cookie.Value
+	err = h.deps.Validate.Struct(input)
+	if err != nil {
+		helpers.WriteValidationProblem(w, r, err)
+		return
+	}
 
-	newAccessToken, newRefreshToken, err := h.authService.Refresh(ctx, oldToken)
+	userID, err := h.mfa.ChallengeUserID(input.MFAToken)
 	if err != nil {
-		http.Error(w, "refresh token required", http.StatusBadRequest)
+		helpers.WriteError(w, r, h.deps.Logger, err)
 		return
 	}
 
-	http.SetCookie(w, &http.Cookie{
-		Name:     "refresh_token",
-		Value:    newRefreshToken,
-		Path:     "/auth",
-		HttpOnly: true,
-		Secure:   false, // true in production
-		SameSite: http.SameSiteStrictMode,
-		Expires:  time.Now().Add(h.deps.Config.Auth.RefreshTTL),
-	})
+	// Second factor guesses are limited per account like passwords, not just per IP
+	wait, err := h.loginGuard.BeginSecondFactor(ctx, userID)
+	if err != nil {
+		helpers.WriteError(w, r, h.deps.Logger, err)
+		return
+	}
+	if wait > 0 {
+		writeTooManyRequests(w, r, wait)
+		return
+	}
+
+	user, err := h.mfa.CompleteChallenge(ctx, input.MFAToken, input.Code)
+	if err != nil {
+		if !errors.Is(err, services.ErrInvalidMFACode) {
+			if err := h.loginGuard.SecondFactorAborted(ctx, userID); err != nil {
+				h.deps.Logger.Println("login guard error:", err)
+			}
+		}
+		helpers.WriteError(w, r, h.deps.Logger, err)
+		return
+	}
+
+	if err := h.loginGuard.SecondFactorSucceeded(ctx, userID); err != nil {
+		h.deps.Logger.Println("login guard error:", err)
+	}
+
+	accessToken, refreshToken, err := h.authService.Login(ctx, user, clientInfo(r))
+	if err != nil {
+		helpers.WriteError(w, r, h.deps.Logger, err)
+		return
+	}
+
+	csrfToken, err := setSessionCookies(w, h.deps, refreshToken)
+	if err != nil {
+		helpers.WriteError(w, r, h.deps.Logger, err)
+		return
+	}
+
+	helpers.WriteJSON(w, http.StatusOK, map[string]any{
+		"access_token": accessToken,
+		"csrf_token":   csrfToken,
+	}, nil)
+}
+
+func (h *AuthHandler) Refresh(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	oldToken, err := readRefreshCookie(r, h.deps)
+	if err != nil {
+		helpers.WriteProblem(w, r, http.StatusUnauthorized, "missing refresh token")
+		return
+	}
+
+	newAccessToken, newRefreshToken, err := h.authService.Refresh(ctx, oldToken, clientInfo(r))
+	if err != nil {
+		helpers.WriteError(w, r, h.deps.Logger, err)
+		return
+	}
+
+	// The CSRF token stays the same, its cookie just lives as long as the new refresh token
+	setRefreshCookie(w, h.deps, newRefreshToken)
+	setCSRFCookie(w, h.deps, r.Header.Get(middlewares.CSRFHeader))
 
 	helpers.WriteJSON(w, http.StatusOK, map[string]any{
 		"access_token": newAccessToken,
@@ -155,29 +290,282 @@ func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
 	// Read refresh token from httpOnly cookie
-	cookie, err := r.Cookie("refresh_token")
+	refreshToken, err := readRefreshCookie(r, h.deps)
 	if err != nil {
-		http.Error(w, "refresh token missing", http.StatusBadRequest)
+		helpers.WriteProblem(w, r, http.StatusBadRequest, "refresh token missing")
 		return
 	}
-	refreshToken := cookie.Value
 
 	// Revoke the refresh token in the database
 	err = h.authService.Logout(ctx, refreshToken)
 	if err != nil {
-		http.Error(w, "could not logout", http.StatusInternalServerError)
+		helpers.WriteError(w, r, h.deps.Logger, err)
+		return
+	}
+
+	// Clear the cookies
+	clearSessionCookies(w, h.deps)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// CSRFToken returns the token to send as X-CSRF-Token to /auth/refresh and
+// /auth/logout, issuing one if needed. Clients call it when they don't have
+// the token from the login response, e.g. after a page reload or OIDC login.
+func (h *AuthHandler) CSRFToken(w http.ResponseWriter, r *http.Request) {
+	token, err := helpers.ReadCookie(r, h.deps.Config.Cookies, middlewares.CSRFCookie)
+	if err != nil || token == "" {
+		token, err = middlewares.NewCSRFToken()
+		if err != nil {
+			helpers.WriteError(w, r, h.deps.Logger, err)
+			return
+		}
+
+		setCSRFCookie(w, h.deps, token)
+	}
+
+	// Must not be cached, it's tied to the caller's cookie
+	headers := http.Header{}
+	headers.Set("Cache-Control", "no-store")
+
+	helpers.WriteJSON(w, http.StatusOK, map[string]any{
+		"csrf_token": token,
+	}, headers)
+}
+
+func (h *AuthHandler) GetSessions(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID, ok := middlewares.GetUserIDFromContext(ctx)
+	if !ok {
+		helpers.WriteProblem(w, r, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	// The refresh cookie only reaches /auth, so it is there to mark the current session
+	currentToken := ""
+	if token, err := readRefreshCookie(r, h.deps); err == nil {
+		currentToken = token
+	}
+
+	sessions, err := h.authService.ListSessions(ctx, userID, currentToken)
+	if err != nil {
+		helpers.WriteError(w, r, h.deps.Logger, err)
 		return
 	}
 
-	// Clear the cookie
-	http.SetCookie(w, &http.Cookie{
-		Name:     "refresh_token",
-		Value:    "",
-		Path:     "/auth",
-		HttpOnly: true,
-		Secure:   false, // true in production
-		Expires:  time.Unix(0, 0),
-	})
+	helpers.WriteJSON(w, http.StatusOK, sessions, nil)
+}
+
+func (h *AuthHandler) RevokeSession(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID, ok := middlewares.GetUserIDFromContext(ctx)
+	if !ok {
+		helpers.WriteProblem(w, r, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	err := h.authService.RevokeSession(ctx, userID, chi.URLParam(r, "id"))
+	if err != nil {
+		helpers.WriteError(w, r, h.deps.Logger, err)
+		return
+	}
 
 	w.WriteHeader(http.StatusNoContent)
 }
+
+func (h *AuthHandler) LogoutAll(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID, ok := middlewares.GetUserIDFromContext(ctx)
+	if !ok {
+		helpers.WriteProblem(w, r, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	err := h.authService.LogoutAll(ctx, userID)
+	if err != nil {
+		helpers.WriteError(w, r, h.deps.Logger, err)
+		return
+	}
+
+	// Clear the cookies
+	clearSessionCookies(w, h.deps)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ForgotPassword emails a reset link. It always answers 202 so it can't be used to find accounts.
+func (h *AuthHandler) ForgotPassword(w http.ResponseWriter, r *http.Request) {
+	var input dtos.ForgotPasswordRequest
+
+	err := helpers.ReadJSON(w, r, &input)
+	if err != nil {
+		helpers.WriteProblem(w, r, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	err = h.deps.Validate.Struct(input)
+	if err != nil {
+		helpers.WriteValidationProblem(w, r, err)
+		return
+	}
+
+	if !h.allowMail(w, r, "password_reset", input.Email) {
+		return
+	}
+
+	err = h.passwordReset.RequestReset(r.Context(), input.Email)
+	if err != nil {
+		helpers.WriteError(w, r, h.deps.Logger, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (h *AuthHandler) ResetPassword(w http.ResponseWriter, r *http.Request) {
+	var input dtos.ResetPasswordRequest
+
+	err := helpers.ReadJSON(w, r, &input)
+	if err != nil {
+		helpers.WriteProblem(w, r, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	// An invalid token is reported by ResetPassword below
+	validateCtx := r.Context()
+	if user, err := h.passwordReset.UserForToken(validateCtx, input.Token); err == nil {
+		validateCtx = password.WithUserInputs(validateCtx, user.Email, user.FirstName, user.LastName)
+	}
+
+	err = h.deps.Validate.StructCtx(validateCtx, input)
+	if err != nil {
+		helpers.WriteValidationProblem(w, r, err)
+		return
+	}
+
+	err = h.passwordReset.ResetPassword(r.Context(), input.Token, input.Password)
+	if err != nil {
+		helpers.WriteError(w, r, h.deps.Logger, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *AuthHandler) VerifyEmail(w http.ResponseWriter, r *http.Request) {
+	var input dtos.VerifyEmailRequest
+
+	err := helpers.ReadJSON(w, r, &input)
+	if err != nil {
+		helpers.WriteProblem(w, r, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	err = h.deps.Validate.Struct(input)
+	if err != nil {
+		helpers.WriteValidationProblem(w, r, err)
+		return
+	}
+
+	user, err := h.verification.Verify(r.Context(), input.Token)
+	if err != nil {
+		helpers.WriteError(w, r, h.deps.Logger, err)
+		return
+	}
+
+	// Access tokens carry the verified flag, clients should refresh to pick it up
+	helpers.WriteJSON(w, http.StatusOK, user, nil)
+}
+
+// ResendVerification sends a new link to the logged-in user
+func (h *AuthHandler) ResendVerification(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID, ok := middlewares.GetUserIDFromContext(ctx)
+	if !ok {
+		helpers.WriteProblem(w, r, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	err := h.verification.Resend(ctx, userID)
+	if err != nil {
+		helpers.WriteError(w, r, h.deps.Logger, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// ResendVerificationByEmail is the logged-out variant. It always answers 202.
+func (h *AuthHandler) ResendVerificationByEmail(w http.ResponseWriter, r *http.Request) {
+	var input dtos.ResendVerificationRequest
+
+	err := helpers.ReadJSON(w, r, &input)
+	if err != nil {
+		helpers.WriteProblem(w, r, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	err = h.deps.Validate.Struct(input)
+	if err != nil {
+		helpers.WriteValidationProblem(w, r, err)
+		return
+	}
+
+	if !h.allowMail(w, r, "verification", input.Email) {
+		return
+	}
+
+	err = h.verification.ResendByEmail(r.Context(), input.Email)
+	if err != nil {
+		helpers.WriteError(w, r, h.deps.Logger, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// allowMail throttles requests that email an address, per IP and per address.
+// Over the limit it answers 202 like a sent mail, so it doesn't tell whether
+// the address has an account, and returns false.
+func (h *AuthHandler) allowMail(w http.ResponseWriter, r *http.Request, purpose, address string) bool {
+	ok, err := h.loginGuard.AllowMail(r.Context(), helpers.ClientIP(r), purpose, address)
+	if err != nil {
+		helpers.WriteError(w, r, h.deps.Logger, err)
+		return false
+	}
+
+	if !ok {
+		w.WriteHeader(http.StatusAccepted)
+		return false
+	}
+
+	return true
+}
+
+// checkLoginIP counts a login attempt from the client's IP and answers 429 when it is over the limit
+func (h *AuthHandler) checkLoginIP(w http.ResponseWriter, r *http.Request) bool {
+	wait, err := h.loginGuard.CheckIP(r.Context(), helpers.ClientIP(r))
+	if err != nil {
+		helpers.WriteError(w, r, h.deps.Logger, err)
+		return false
+	}
+
+	if wait > 0 {
+		writeTooManyRequests(w, r, wait)
+		return false
+	}
+
+	return true
+}
+
+func writeTooManyRequests(w http.ResponseWriter, r *http.Request, wait time.Duration) {
+	w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+	helpers.WriteProblem(w, r, http.StatusTooManyRequests, "too many login attempts, try again later")
+}
+
+func clientInfo(r *http.Request) services.ClientInfo {
+	return services.ClientInfo{
+		UserAgent: r.UserAgent(),
+		IPAddress: helpers.ClientIP(r),
+	}
+}
@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/georgiev098/film-manager/backend/internal/audit"
+	"github.com/georgiev098/film-manager/backend/internal/core"
+	"github.com/georgiev098/film-manager/backend/internal/helpers"
+	"github.com/georgiev098/film-manager/backend/internal/jwtkeys"
+	"github.com/georgiev098/film-manager/backend/internal/mailer"
+	"github.com/georgiev098/film-manager/backend/internal/models"
+	"github.com/georgiev098/film-manager/backend/internal/password"
+	"github.com/georgiev098/film-manager/backend/internal/ratelimit"
+	"github.com/georgiev098/film-manager/backend/internal/repositories"
+	"github.com/glebarez/sqlite"
+	"github.com/go-playground/validator/v10"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func newTestDeps(t *testing.T) *core.AppDeps {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{
+		Logger: logger.Discard,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = db.AutoMigrate(&models.User{}, &models.RefreshToken{}, &models.AuditEvent{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	validate := validator.New()
+	validate.RegisterTagNameFunc(helpers.JSONFieldName)
+
+	policy := &password.Policy{MinLength: 8, MaxLength: 128, MinScore: 2}
+	if err := policy.RegisterValidations(validate); err != nil {
+		t.Fatal(err)
+	}
+
+	keys, err := jwtkeys.Generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	discard := log.New(io.Discard, "", 0)
+
+	deps := &core.AppDeps{
+		DB:        db,
+		Logger:    discard,
+		Validate:  validate,
+		Mailer:    mailer.NewLogMailer(discard),
+		Keys:      keys,
+		Limits:    ratelimit.NewMemoryStore(),
+		Passwords: password.NewHasher(password.Params{Memory: 1024, Iterations: 1, Parallelism: 1}),
+		Audit:     audit.NewService(repositories.NewAuditRepo(db), discard),
+	}
+	deps.Config.Auth.Issuer = "film-manager"
+	deps.Config.Auth.Audience = "film-manager"
+	deps.Config.Auth.VerificationSecret = "secret"
+
+	return deps
+}
+
+func TestSignUpEmailTaken(t *testing.T) {
+	deps := newTestDeps(t)
+	h := NewAuthHandler(deps)
+
+	signUp := func(email string) int {
+		body := `{"email": "` + email + `", "first_name": "Ansel", "last_name": "Adams", "password": "moonrise over hernandez"}`
+		req := httptest.NewRequest(http.MethodPost, "/auth/signup", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+
+		rec := httptest.NewRecorder()
+		h.SignUp(rec, req)
+		return rec.Code
+	}
+
+	if code := signUp("ansel@example.com"); code != http.StatusCreated {
+		t.Fatalf("first signup: status %d, want %d", code, http.StatusCreated)
+	}
+
+	if code := signUp("Ansel@Example.com"); code != http.StatusConflict {
+		t.Errorf("same address again: status %d, want %d", code, http.StatusConflict)
+	}
+
+	// A deleted account keeps its address until it is erased
+	if err := deps.DB.Where("email = ?", "ansel@example.com").Delete(&models.User{}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	if code := signUp("ansel@example.com"); code != http.StatusConflict {
+		t.Errorf("address of a deleted account: status %d, want %d", code, http.StatusConflict)
+	}
+}
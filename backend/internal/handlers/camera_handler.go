@@ -1,7 +1,6 @@
 package handlers
 
 import (
-	"errors"
 	"net/http"
 	"strconv"
 
@@ -13,7 +12,6 @@ import (
 	"github.com/georgiev098/film-manager/backend/internal/repositories"
 	"github.com/georgiev098/film-manager/backend/internal/services"
 	"github.com/go-chi/chi/v5"
-	"gorm.io/gorm"
 )
 
 type CameraHandler struct {
@@ -23,7 +21,7 @@ type CameraHandler struct {
 
 func NewCameraHandler(deps *core.AppDeps) *CameraHandler {
 	repo := repositories.NewCameraRepo(deps.DB)
-	service := services.NewCameraService(repo)
+	service := services.NewCameraService(repo, itemQuota(deps), deps.Audit)
 
 	return &CameraHandler{
 		deps:    deps,
@@ -34,8 +32,7 @@ func NewCameraHandler(deps *core.AppDeps) *CameraHandler {
 func (h *CameraHandler) GetAllCameras(w http.ResponseWriter, r *http.Request) {
 	cameras, err := h.service.GetAllCameras(r.Context())
 	if err != nil {
-		h.deps.Logger.Println("error fetching cameras:", err)
-		http.Error(w, "internal server error", http.StatusInternalServerError)
+		helpers.WriteError(w, r, h.deps.Logger, err)
 		return
 	}
 
@@ -45,13 +42,12 @@ func (h *CameraHandler) GetAllCameras(w http.ResponseWriter, r *http.Request) {
 func (h *CameraHandler) GetAllCamerasForUser(w http.ResponseWriter, r *http.Request) {
 	userID, ok := middlewares.GetUserIDFromContext(r.Context())
 	if !ok {
-		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		helpers.WriteProblem(w, r, http.StatusUnauthorized, "unauthorized")
 		return
 	}
 	cameras, err := h.service.GetAllForUser(r.Context(), userID)
 	if err != nil {
-		h.deps.Logger.Println("error fetching cameras:", err)
-		http.Error(w, "internal server error", http.StatusInternalServerError)
+		helpers.WriteError(w, r, h.deps.Logger, err)
 		return
 	}
 
@@ -62,7 +58,7 @@ func (h *CameraHandler) CreateCamera(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	userID, ok := middlewares.GetUserIDFromContext(r.Context())
 	if !ok {
-		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		helpers.WriteProblem(w, r, http.StatusUnauthorized, "unauthorized")
 		return
 	}
 	var camera models.Camera
@@ -70,7 +66,7 @@ func (h *CameraHandler) CreateCamera(w http.ResponseWriter, r *http.Request) {
 	err := helpers.ReadJSON(w, r, &camera)
 	if err != nil {
 		h.deps.Logger.Println("invalid json:", err)
-		http.Error(w, "invalid request body", http.StatusBadRequest)
+		helpers.WriteProblem(w, r, http.StatusBadRequest, "invalid request body")
 		return
 	}
 
@@ -79,16 +75,13 @@ func (h *CameraHandler) CreateCamera(w http.ResponseWriter, r *http.Request) {
 
 	err = h.deps.Validate.Struct(camera)
 	if err != nil {
-		// map errors with helper
-		errMap := helpers.ParseValidationErrors(err)
-		helpers.WriteJSON(w, http.StatusBadRequest, map[string]any{"errors": errMap}, nil)
+		helpers.WriteValidationProblem(w, r, err)
 		return
 	}
 
 	err = h.service.CreateCamera(ctx, &camera)
 	if err != nil {
-		h.deps.Logger.Println("error creating camera:", err)
-		http.Error(w, "could not create camera", http.StatusInternalServerError)
+		helpers.WriteError(w, r, h.deps.Logger, err)
 		return
 	}
 
@@ -101,24 +94,19 @@ func (h *CameraHandler) GetCameraByID(w http.ResponseWriter, r *http.Request) {
 	idParam := chi.URLParam(r, "id")
 	cameraID, err := strconv.ParseUint(idParam, 10, 64)
 	if err != nil {
-		http.Error(w, "invalid camera id", http.StatusBadRequest)
+		helpers.WriteProblem(w, r, http.StatusBadRequest, "invalid camera id")
 		return
 	}
 
 	userID, ok := middlewares.GetUserIDFromContext(r.Context())
 	if !ok {
-		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		helpers.WriteProblem(w, r, http.StatusUnauthorized, "unauthorized")
 		return
 	}
 
 	camera, err := h.service.GetCameraByID(ctx, uint(cameraID), userID)
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			http.Error(w, "camera not found", http.StatusNotFound)
-		} else {
-			h.deps.Logger.Println("error fetching camera:", err)
-			http.Error(w, "internal server error", http.StatusInternalServerError)
-		}
+		helpers.WriteError(w, r, h.deps.Logger, err)
 		return
 	}
 
@@ -131,13 +119,13 @@ func (h *CameraHandler) UpdateCamera(w http.ResponseWriter, r *http.Request) {
 	idParam := chi.URLParam(r, "id")
 	cameraID, err := strconv.ParseUint(idParam, 10, 64)
 	if err != nil {
-		http.Error(w, "invalid camera id", http.StatusBadRequest)
+		helpers.WriteProblem(w, r, http.StatusBadRequest, "invalid camera id")
 		return
 	}
 
 	userID, ok := middlewares.GetUserIDFromContext(r.Context())
 	if !ok {
-		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		helpers.WriteProblem(w, r, http.StatusUnauthorized, "unauthorized")
 		return
 	}
 
@@ -145,21 +133,19 @@ func (h *CameraHandler) UpdateCamera(w http.ResponseWriter, r *http.Request) {
 
 	err = helpers.ReadJSON(w, r, &inputCamera)
 	if err != nil {
-		http.Error(w, "invalid payload", http.StatusBadRequest)
+		helpers.WriteProblem(w, r, http.StatusBadRequest, "invalid payload")
 		return
 	}
 
 	err = h.deps.Validate.Struct(inputCamera)
 	if err != nil {
-		// map errors with helper
-		errMap := helpers.ParseValidationErrors(err)
-		helpers.WriteJSON(w, http.StatusBadRequest, map[string]any{"errors": errMap}, nil)
+		helpers.WriteValidationProblem(w, r, err)
 		return
 	}
 
 	updatedCamera, err := h.service.UpdateCamera(ctx, uint(cameraID), userID, inputCamera)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusForbidden)
+		helpers.WriteError(w, r, h.deps.Logger, err)
 		return
 	}
 
@@ -172,19 +158,19 @@ func (h *CameraHandler) DeleteCamera(w http.ResponseWriter, r *http.Request) {
 	idParam := chi.URLParam(r, "id")
 	cameraID, err := strconv.ParseUint(idParam, 10, 64)
 	if err != nil {
-		http.Error(w, "invalid camera id", http.StatusBadRequest)
+		helpers.WriteProblem(w, r, http.StatusBadRequest, "invalid camera id")
 		return
 	}
 
 	userID, ok := middlewares.GetUserIDFromContext(r.Context())
 	if !ok {
-		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		helpers.WriteProblem(w, r, http.StatusUnauthorized, "unauthorized")
 		return
 	}
 
 	err = h.service.DeleteCamera(ctx, uint(cameraID), userID)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusForbidden)
+		helpers.WriteError(w, r, h.deps.Logger, err)
 		return
 	}
 
@@ -0,0 +1,218 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/georgiev098/film-manager/backend/internal/core"
+	"github.com/georgiev098/film-manager/backend/internal/helpers"
+	"github.com/georgiev098/film-manager/backend/internal/importers"
+	"github.com/georgiev098/film-manager/backend/internal/middlewares"
+	"github.com/georgiev098/film-manager/backend/internal/services"
+	"github.com/go-chi/chi/v5"
+)
+
+const maxImportBytes = 10 << 20 // 10MB
+
+var collectionContentTypes = map[string]string{
+	services.FormatJSON: "application/json",
+	services.FormatCSV:  "text/csv",
+	services.FormatZIP:  "application/zip",
+}
+
+type CollectionHandler struct {
+	deps    *core.AppDeps
+	service *services.CollectionService
+}
+
+func NewCollectionHandler(deps *core.AppDeps) *CollectionHandler {
+	service := services.NewCollectionService(deps.DB, deps.Validate, itemQuota(deps), deps.Audit)
+
+	return &CollectionHandler{
+		deps:    deps,
+		service: service,
+	}
+}
+
+// Export streams the user's gear and film records as ?format=json|csv|zip
+func (h *CollectionHandler) Export(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middlewares.GetUserIDFromContext(r.Context())
+	if !ok {
+		helpers.WriteProblem(w, r, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = services.FormatJSON
+	}
+
+	contentType, ok := collectionContentTypes[format]
+	if !ok {
+		helpers.WriteProblem(w, r, http.StatusBadRequest, "format must be one of: json csv zip")
+		return
+	}
+
+	collection, err := h.service.Export(r.Context(), userID)
+	if err != nil {
+		helpers.WriteError(w, r, h.deps.Logger, err)
+		return
+	}
+
+	// Encode into a buffer first so a failure can still produce a proper error response
+	var buf bytes.Buffer
+	if err := services.EncodeCollection(&buf, format, collection); err != nil {
+		helpers.WriteError(w, r, h.deps.Logger, err)
+		return
+	}
+
+	filename := fmt.Sprintf("film-manager-export-%s.%s", time.Now().Format("2006-01-02"), format)
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	w.WriteHeader(http.StatusOK)
+	w.Write(buf.Bytes())
+}
+
+// Import ingests an export file sent as the raw request body.
+// ?format=json|csv|zip (defaults to json), ?dry_run=true reports without writing.
+func (h *CollectionHandler) Import(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middlewares.GetUserIDFromContext(r.Context())
+	if !ok {
+		helpers.WriteProblem(w, r, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	query := r.URL.Query()
+
+	format := query.Get("format")
+	if format == "" {
+		format = services.FormatJSON
+	}
+	if _, ok := collectionContentTypes[format]; !ok {
+		helpers.WriteProblem(w, r, http.StatusBadRequest, "format must be one of: json csv zip")
+		return
+	}
+
+	dryRun := false
+	if v := query.Get("dry_run"); v != "" {
+		parsed, err := strconv.ParseBool(v)
+		if err != nil {
+			helpers.WriteProblem(w, r, http.StatusBadRequest, "invalid dry_run value")
+			return
+		}
+		dryRun = parsed
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportBytes)
+	defer r.Body.Close()
+
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			helpers.WriteProblem(w, r, http.StatusRequestEntityTooLarge, "import file too large")
+			return
+		}
+		helpers.WriteProblem(w, r, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	collection, err := services.DecodeCollection(format, data)
+	if err != nil {
+		helpers.WriteProblem(w, r, http.StatusBadRequest, "could not parse import file: "+err.Error())
+		return
+	}
+
+	report, err := h.service.Import(r.Context(), userID, collection, dryRun)
+	if err != nil {
+		helpers.WriteError(w, r, h.deps.Logger, err)
+		return
+	}
+
+	helpers.WriteJSON(w, http.StatusOK, report, nil)
+}
+
+// ListSources lists the apps POST /import/{source} understands
+func (h *CollectionHandler) ListSources(w http.ResponseWriter, r *http.Request) {
+	helpers.WriteJSON(w, http.StatusOK, map[string]any{"sources": importers.Names()}, nil)
+}
+
+// ImportExternal ingests another app's export, sent as multipart form data:
+// "file" holds the export and the optional "lens_map" field is a JSON object
+// mapping source lens names to existing lens IDs. ?dry_run=true reports without writing.
+func (h *CollectionHandler) ImportExternal(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middlewares.GetUserIDFromContext(r.Context())
+	if !ok {
+		helpers.WriteProblem(w, r, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	parser, err := importers.Get(chi.URLParam(r, "source"))
+	if err != nil {
+		helpers.WriteProblem(w, r, http.StatusNotFound, "unknown import source")
+		return
+	}
+
+	dryRun := false
+	if v := r.URL.Query().Get("dry_run"); v != "" {
+		parsed, err := strconv.ParseBool(v)
+		if err != nil {
+			helpers.WriteProblem(w, r, http.StatusBadRequest, "invalid dry_run value")
+			return
+		}
+		dryRun = parsed
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportBytes)
+	if err := r.ParseMultipartForm(maxImportBytes); err != nil {
+		helpers.WriteProblem(w, r, http.StatusBadRequest, "invalid multipart form")
+		return
+	}
+
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		helpers.WriteProblem(w, r, http.StatusBadRequest, "file is required")
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		helpers.WriteProblem(w, r, http.StatusBadRequest, "invalid file")
+		return
+	}
+
+	lensMap := map[string]uint{}
+	if raw := r.FormValue("lens_map"); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &lensMap); err != nil {
+			helpers.WriteProblem(w, r, http.StatusBadRequest, "lens_map must be a JSON object of lens name to lens id")
+			return
+		}
+	}
+
+	result, err := parser.Parse(data)
+	if err != nil {
+		helpers.WriteProblem(w, r, http.StatusBadRequest, "could not parse import file: "+err.Error())
+		return
+	}
+
+	report, err := h.service.ImportExternal(r.Context(), userID, parser.Name(), result, lensMap, dryRun)
+	if err != nil {
+		helpers.WriteError(w, r, h.deps.Logger, err)
+		return
+	}
+
+	helpers.WriteJSON(w, http.StatusOK, report, nil)
+}
+
+// itemQuota limits the gear of unverified users, shared by every service that creates items
+func itemQuota(deps *core.AppDeps) *services.ItemQuota {
+	return services.NewItemQuota(deps.DB, deps.Config.Auth.UnverifiedPolicy, deps.Config.Auth.UnverifiedItemLimit)
+}
@@ -0,0 +1,198 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+
+	"github.com/georgiev098/film-manager/backend/internal/core"
+	"github.com/georgiev098/film-manager/backend/internal/dtos"
+	"github.com/georgiev098/film-manager/backend/internal/helpers"
+	"github.com/georgiev098/film-manager/backend/internal/middlewares"
+	"github.com/georgiev098/film-manager/backend/internal/repositories"
+	"github.com/georgiev098/film-manager/backend/internal/services"
+	"github.com/go-chi/chi/v5"
+)
+
+// DataRequestHandler serves data exports and account erasure under /me
+type DataRequestHandler struct {
+	deps    *core.AppDeps
+	service *services.DataRequestService
+}
+
+func NewDataRequestHandler(deps *core.AppDeps) *DataRequestHandler {
+
+	service := services.NewDataRequestService(
+		repositories.NewDataRequestRepo(deps.DB),
+		repositories.NewUserDataRepo(deps.DB),
+		services.NewCollectionService(deps.DB, deps.Validate, itemQuota(deps), deps.Audit),
+		services.NewLoginGuard(deps.Limits, deps.Config.LoginProtection),
+		deps.Passwords,
+		deps.Mailer,
+		deps.Logger,
+		deps.Config.AppURL,
+		deps.Config.DataRequests,
+	)
+
+	return &DataRequestHandler{
+		deps:    deps,
+		service: service,
+	}
+}
+
+// RequestExport queues an archive of all the user's data
+func (h *DataRequestHandler) RequestExport(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middlewares.GetUserIDFromContext(r.Context())
+	if !ok {
+		helpers.WriteProblem(w, r, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	req, err := h.service.RequestExport(r.Context(), userID)
+	if err != nil {
+		helpers.WriteError(w, r, h.deps.Logger, err)
+		return
+	}
+
+	helpers.WriteJSON(w, http.StatusAccepted, req, nil)
+}
+
+func (h *DataRequestHandler) GetExports(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middlewares.GetUserIDFromContext(r.Context())
+	if !ok {
+		helpers.WriteProblem(w, r, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	reqs, err := h.service.ListExports(r.Context(), userID)
+	if err != nil {
+		helpers.WriteError(w, r, h.deps.Logger, err)
+		return
+	}
+
+	helpers.WriteJSON(w, http.StatusOK, reqs, nil)
+}
+
+func (h *DataRequestHandler) GetExport(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middlewares.GetUserIDFromContext(r.Context())
+	if !ok {
+		helpers.WriteProblem(w, r, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	exportID, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		helpers.WriteProblem(w, r, http.StatusBadRequest, "invalid export id")
+		return
+	}
+
+	req, err := h.service.GetExport(r.Context(), userID, uint(exportID))
+	if err != nil {
+		helpers.WriteError(w, r, h.deps.Logger, err)
+		return
+	}
+
+	helpers.WriteJSON(w, http.StatusOK, req, nil)
+}
+
+func (h *DataRequestHandler) DownloadExport(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middlewares.GetUserIDFromContext(r.Context())
+	if !ok {
+		helpers.WriteProblem(w, r, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	exportID, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		helpers.WriteProblem(w, r, http.StatusBadRequest, "invalid export id")
+		return
+	}
+
+	req, err := h.service.ExportFile(r.Context(), userID, uint(exportID))
+	if err != nil {
+		helpers.WriteError(w, r, h.deps.Logger, err)
+		return
+	}
+
+	f, err := os.Open(req.FilePath)
+	if err != nil {
+		helpers.WriteError(w, r, h.deps.Logger, err)
+		return
+	}
+	defer f.Close()
+
+	filename := fmt.Sprintf("film-manager-data-%s.zip", req.CompletedAt.Format("2006-01-02"))
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	http.ServeContent(w, r, filename, *req.CompletedAt, f)
+}
+
+// RequestErasure schedules the account for permanent deletion
+func (h *DataRequestHandler) RequestErasure(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middlewares.GetUserIDFromContext(r.Context())
+	if !ok {
+		helpers.WriteProblem(w, r, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	var input dtos.ErasureRequest
+
+	err := helpers.ReadJSON(w, r, &input)
+	if err != nil {
+		helpers.WriteProblem(w, r, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	req, err := h.service.RequestErasure(r.Context(), userID, input.Password)
+	if err != nil {
+		helpers.WriteError(w, r, h.deps.Logger, err)
+		return
+	}
+
+	helpers.WriteJSON(w, http.StatusAccepted, req, nil)
+}
+
+func (h *DataRequestHandler) GetErasure(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middlewares.GetUserIDFromContext(r.Context())
+	if !ok {
+		helpers.WriteProblem(w, r, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	req, err := h.service.GetErasure(r.Context(), userID)
+	if err != nil {
+		helpers.WriteError(w, r, h.deps.Logger, err)
+		return
+	}
+
+	helpers.WriteJSON(w, http.StatusOK, req, nil)
+}
+
+func (h *DataRequestHandler) CancelErasure(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middlewares.GetUserIDFromContext(r.Context())
+	if !ok {
+		helpers.WriteProblem(w, r, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	err := h.service.CancelErasure(r.Context(), userID)
+	if err != nil {
+		helpers.WriteError(w, r, h.deps.Logger, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetErasureLog lists all erasure requests and their outcome, for admins
+func (h *DataRequestHandler) GetErasureLog(w http.ResponseWriter, r *http.Request) {
+	reqs, err := h.service.ErasureLog(r.Context())
+	if err != nil {
+		helpers.WriteError(w, r, h.deps.Logger, err)
+		return
+	}
+
+	helpers.WriteJSON(w, http.StatusOK, reqs, nil)
+}
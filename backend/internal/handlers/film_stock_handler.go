@@ -0,0 +1,155 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/georgiev098/film-manager/backend/internal/core"
+	"github.com/georgiev098/film-manager/backend/internal/dtos"
+	"github.com/georgiev098/film-manager/backend/internal/helpers"
+	"github.com/georgiev098/film-manager/backend/internal/middlewares"
+	"github.com/georgiev098/film-manager/backend/internal/models"
+	"github.com/georgiev098/film-manager/backend/internal/repositories"
+	"github.com/georgiev098/film-manager/backend/internal/services"
+	"github.com/go-chi/chi/v5"
+)
+
+type FilmStockHandler struct {
+	deps    *core.AppDeps
+	service *services.FilmStockService
+}
+
+func NewFilmStockHandler(deps *core.AppDeps) *FilmStockHandler {
+	return &FilmStockHandler{
+		deps:    deps,
+		service: services.NewFilmStockService(repositories.NewFilmStockRepo(deps.DB)),
+	}
+}
+
+func (h *FilmStockHandler) GetFilmStocks(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middlewares.GetUserIDFromContext(r.Context())
+	if !ok {
+		helpers.WriteProblem(w, r, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	stocks, err := h.service.GetAllForUser(r.Context(), userID)
+	if err != nil {
+		helpers.WriteError(w, r, h.deps.Logger, err)
+		return
+	}
+
+	helpers.WriteJSON(w, http.StatusOK, stocks, nil)
+}
+
+func (h *FilmStockHandler) CreateFilmStock(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middlewares.GetUserIDFromContext(r.Context())
+	if !ok {
+		helpers.WriteProblem(w, r, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	var stock models.FilmStock
+
+	err := helpers.ReadJSON(w, r, &stock)
+	if err != nil {
+		helpers.WriteProblem(w, r, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	stock.UserID = userID
+
+	err = h.deps.Validate.Struct(stock)
+	if err != nil {
+		helpers.WriteValidationProblem(w, r, err)
+		return
+	}
+
+	err = h.service.CreateFilmStock(r.Context(), &stock)
+	if err != nil {
+		helpers.WriteError(w, r, h.deps.Logger, err)
+		return
+	}
+
+	helpers.WriteJSON(w, http.StatusCreated, stock, nil)
+}
+
+func (h *FilmStockHandler) GetFilmStockByID(w http.ResponseWriter, r *http.Request) {
+	stockID, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		helpers.WriteProblem(w, r, http.StatusBadRequest, "invalid film stock id")
+		return
+	}
+
+	userID, ok := middlewares.GetUserIDFromContext(r.Context())
+	if !ok {
+		helpers.WriteProblem(w, r, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	stock, err := h.service.GetFilmStockByID(r.Context(), uint(stockID), userID)
+	if err != nil {
+		helpers.WriteError(w, r, h.deps.Logger, err)
+		return
+	}
+
+	helpers.WriteJSON(w, http.StatusOK, stock, nil)
+}
+
+func (h *FilmStockHandler) UpdateFilmStock(w http.ResponseWriter, r *http.Request) {
+	stockID, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		helpers.WriteProblem(w, r, http.StatusBadRequest, "invalid film stock id")
+		return
+	}
+
+	userID, ok := middlewares.GetUserIDFromContext(r.Context())
+	if !ok {
+		helpers.WriteProblem(w, r, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	var input dtos.FilmStockUpdate
+
+	err = helpers.ReadJSON(w, r, &input)
+	if err != nil {
+		helpers.WriteProblem(w, r, http.StatusBadRequest, "invalid payload")
+		return
+	}
+
+	err = h.deps.Validate.Struct(input)
+	if err != nil {
+		helpers.WriteValidationProblem(w, r, err)
+		return
+	}
+
+	stock, err := h.service.UpdateFilmStock(r.Context(), uint(stockID), userID, input)
+	if err != nil {
+		helpers.WriteError(w, r, h.deps.Logger, err)
+		return
+	}
+
+	helpers.WriteJSON(w, http.StatusOK, stock, nil)
+}
+
+func (h *FilmStockHandler) DeleteFilmStock(w http.ResponseWriter, r *http.Request) {
+	stockID, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		helpers.WriteProblem(w, r, http.StatusBadRequest, "invalid film stock id")
+		return
+	}
+
+	userID, ok := middlewares.GetUserIDFromContext(r.Context())
+	if !ok {
+		helpers.WriteProblem(w, r, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	err = h.service.DeleteFilmStock(r.Context(), uint(stockID), userID)
+	if err != nil {
+		helpers.WriteError(w, r, h.deps.Logger, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,169 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/georgiev098/film-manager/backend/internal/core"
+	"github.com/georgiev098/film-manager/backend/internal/dtos"
+	"github.com/georgiev098/film-manager/backend/internal/helpers"
+	"github.com/georgiev098/film-manager/backend/internal/middlewares"
+	"github.com/georgiev098/film-manager/backend/internal/models"
+	"github.com/georgiev098/film-manager/backend/internal/services"
+	"github.com/go-chi/chi/v5"
+)
+
+type FrameHandler struct {
+	deps    *core.AppDeps
+	service *services.FrameService
+}
+
+func NewFrameHandler(deps *core.AppDeps) *FrameHandler {
+	return &FrameHandler{
+		deps:    deps,
+		service: services.NewFrameService(deps.DB),
+	}
+}
+
+// GetFrames lists the frames of the roll in the URL
+func (h *FrameHandler) GetFrames(w http.ResponseWriter, r *http.Request) {
+	rollID, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		helpers.WriteProblem(w, r, http.StatusBadRequest, "invalid roll id")
+		return
+	}
+
+	userID, ok := middlewares.GetUserIDFromContext(r.Context())
+	if !ok {
+		helpers.WriteProblem(w, r, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	frames, err := h.service.GetAllForRoll(r.Context(), uint(rollID), userID)
+	if err != nil {
+		helpers.WriteError(w, r, h.deps.Logger, err)
+		return
+	}
+
+	helpers.WriteJSON(w, http.StatusOK, frames, nil)
+}
+
+// CreateFrame adds a frame to the roll in the URL
+func (h *FrameHandler) CreateFrame(w http.ResponseWriter, r *http.Request) {
+	rollID, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		helpers.WriteProblem(w, r, http.StatusBadRequest, "invalid roll id")
+		return
+	}
+
+	userID, ok := middlewares.GetUserIDFromContext(r.Context())
+	if !ok {
+		helpers.WriteProblem(w, r, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	var frame models.Frame
+
+	err = helpers.ReadJSON(w, r, &frame)
+	if err != nil {
+		helpers.WriteProblem(w, r, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	frame.RollID = uint(rollID)
+	frame.UserID = userID
+
+	err = h.deps.Validate.Struct(frame)
+	if err != nil {
+		helpers.WriteValidationProblem(w, r, err)
+		return
+	}
+
+	err = h.service.CreateFrame(r.Context(), &frame)
+	if err != nil {
+		helpers.WriteError(w, r, h.deps.Logger, err)
+		return
+	}
+
+	helpers.WriteJSON(w, http.StatusCreated, frame, nil)
+}
+
+func (h *FrameHandler) GetFrameByID(w http.ResponseWriter, r *http.Request) {
+	frameID, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		helpers.WriteProblem(w, r, http.StatusBadRequest, "invalid frame id")
+		return
+	}
+
+	userID, ok := middlewares.GetUserIDFromContext(r.Context())
+	if !ok {
+		helpers.WriteProblem(w, r, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	frame, err := h.service.GetFrameByID(r.Context(), uint(frameID), userID)
+	if err != nil {
+		helpers.WriteError(w, r, h.deps.Logger, err)
+		return
+	}
+
+	helpers.WriteJSON(w, http.StatusOK, frame, nil)
+}
+
+func (h *FrameHandler) UpdateFrame(w http.ResponseWriter, r *http.Request) {
+	frameID, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		helpers.WriteProblem(w, r, http.StatusBadRequest, "invalid frame id")
+		return
+	}
+
+	userID, ok := middlewares.GetUserIDFromContext(r.Context())
+	if !ok {
+		helpers.WriteProblem(w, r, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	var input dtos.FrameUpdate
+
+	err = helpers.ReadJSON(w, r, &input)
+	if err != nil {
+		helpers.WriteProblem(w, r, http.StatusBadRequest, "invalid payload")
+		return
+	}
+
+	err = h.deps.Validate.Struct(input)
+	if err != nil {
+		helpers.WriteValidationProblem(w, r, err)
+		return
+	}
+
+	frame, err := h.service.UpdateFrame(r.Context(), uint(frameID), userID, input)
+	if err != nil {
+		helpers.WriteError(w, r, h.deps.Logger, err)
+		return
+	}
+
+	helpers.WriteJSON(w, http.StatusOK, frame, nil)
+}
+
+func (h *FrameHandler) DeleteFrame(w http.ResponseWriter, r *http.Request) {
+	frameID, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		helpers.WriteProblem(w, r, http.StatusBadRequest, "invalid frame id")
+		return
+	}
+
+	userID, ok := middlewares.GetUserIDFromContext(r.Context())
+	if !ok {
+		helpers.WriteProblem(w, r, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	err = h.service.DeleteFrame(r.Context(), uint(frameID), userID)
+	if err != nil {
+		helpers.WriteError(w, r, h.deps.Logger, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
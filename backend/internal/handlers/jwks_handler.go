@@ -0,0 +1,24 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/georgiev098/film-manager/backend/internal/core"
+	"github.com/georgiev098/film-manager/backend/internal/helpers"
+)
+
+type JWKSHandler struct {
+	deps *core.AppDeps
+}
+
+func NewJWKSHandler(deps *core.AppDeps) *JWKSHandler {
+	return &JWKSHandler{deps: deps}
+}
+
+// Keys publishes the public keys access tokens can be verified with
+func (h *JWKSHandler) Keys(w http.ResponseWriter, r *http.Request) {
+	headers := http.Header{}
+	headers.Set("Cache-Control", "public, max-age=300")
+
+	helpers.WriteJSON(w, http.StatusOK, h.deps.Keys.JWKS(), headers)
+}
@@ -1,7 +1,6 @@
 package handlers
 
 import (
-	"errors"
 	"net/http"
 	"strconv"
 
@@ -13,7 +12,6 @@ import (
 	"github.com/georgiev098/film-manager/backend/internal/repositories"
 	"github.com/georgiev098/film-manager/backend/internal/services"
 	"github.com/go-chi/chi/v5"
-	"gorm.io/gorm"
 )
 
 type LensHandler struct {
@@ -23,7 +21,7 @@ type LensHandler struct {
 
 func NewLensHandler(deps *core.AppDeps) *LensHandler {
 	repo := repositories.NewLensRepo(deps.DB)
-	service := services.NewLensService(repo)
+	service := services.NewLensService(repo, itemQuota(deps), deps.Audit)
 
 	return &LensHandler{
 		deps:    deps,
@@ -35,8 +33,7 @@ func (h *LensHandler) GetAllLenses(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	lenses, err := h.service.GetAllLenses(ctx)
 	if err != nil {
-		h.deps.Logger.Println("error fetching lenses:", err)
-		http.Error(w, "internal server error", http.StatusInternalServerError)
+		helpers.WriteError(w, r, h.deps.Logger, err)
 		return
 	}
 
@@ -47,14 +44,13 @@ func (h *LensHandler) GetAllLensesForUser(w http.ResponseWriter, r *http.Request
 	ctx := r.Context()
 	userID, ok := middlewares.GetUserIDFromContext(r.Context())
 	if !ok {
-		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		helpers.WriteProblem(w, r, http.StatusUnauthorized, "unauthorized")
 		return
 	}
 
 	lenses, err := h.service.GetAllForUser(ctx, userID)
 	if err != nil {
-		h.deps.Logger.Println("error fetching lenses:", err)
-		http.Error(w, "internal server error", http.StatusInternalServerError)
+		helpers.WriteError(w, r, h.deps.Logger, err)
 		return
 	}
 
@@ -65,7 +61,7 @@ func (h *LensHandler) CreateLens(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	userID, ok := middlewares.GetUserIDFromContext(r.Context())
 	if !ok {
-		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		helpers.WriteProblem(w, r, http.StatusUnauthorized, "unauthorized")
 		return
 	}
 	var lens models.Lens
@@ -73,7 +69,7 @@ func (h *LensHandler) CreateLens(w http.ResponseWriter, r *http.Request) {
 	err := helpers.ReadJSON(w, r, &lens)
 	if err != nil {
 		h.deps.Logger.Println("invalid json:", err)
-		http.Error(w, "invalid request body", http.StatusBadRequest)
+		helpers.WriteProblem(w, r, http.StatusBadRequest, "invalid request body")
 		return
 	}
 
@@ -81,16 +77,13 @@ func (h *LensHandler) CreateLens(w http.ResponseWriter, r *http.Request) {
 
 	err = h.deps.Validate.Struct(lens)
 	if err != nil {
-		// map errors with helper
-		errMap := helpers.ParseValidationErrors(err)
-		helpers.WriteJSON(w, http.StatusBadRequest, map[string]any{"errors": errMap}, nil)
+		helpers.WriteValidationProblem(w, r, err)
 		return
 	}
 
 	err = h.service.CreateLens(ctx, &lens)
 	if err != nil {
-		h.deps.Logger.Println("error creating lens:", err)
-		http.Error(w, "could not create lens", http.StatusInternalServerError)
+		helpers.WriteError(w, r, h.deps.Logger, err)
 		return
 	}
 
@@ -103,24 +96,19 @@ func (h *LensHandler) GetLensByID(w http.ResponseWriter, r *http.Request) {
 	idParam := chi.URLParam(r, "id")
 	lensID, err := strconv.ParseUint(idParam, 10, 64)
 	if err != nil {
-		http.Error(w, "invalid lens id", http.StatusBadRequest)
+		helpers.WriteProblem(w, r, http.StatusBadRequest, "invalid lens id")
 		return
 	}
 
 	userID, ok := middlewares.GetUserIDFromContext(r.Context())
 	if !ok {
-		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		helpers.WriteProblem(w, r, http.StatusUnauthorized, "unauthorized")
 		return
 	}
 
 	lens, err := h.service.GetLensByID(ctx, uint(lensID), userID)
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			http.Error(w, "lens not found", http.StatusNotFound)
-		} else {
-			h.deps.Logger.Println("error fetching lens:", err)
-			http.Error(w, "internal server error", http.StatusInternalServerError)
-		}
+		helpers.WriteError(w, r, h.deps.Logger, err)
 		return
 	}
 
@@ -131,34 +119,33 @@ func (h *LensHandler) UpdateLens(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	userID, ok := middlewares.GetUserIDFromContext(r.Context())
 	if !ok {
-		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		helpers.WriteProblem(w, r, http.StatusUnauthorized, "unauthorized")
 		return
 	}
 
 	idParam := chi.URLParam(r, "id")
 	lensID, err := strconv.ParseUint(idParam, 10, 64)
 	if err != nil {
-		http.Error(w, "invalid lens id", http.StatusBadRequest)
+		helpers.WriteProblem(w, r, http.StatusBadRequest, "invalid lens id")
 		return
 	}
 
 	var inputLens dtos.LensUpdate
 	err = helpers.ReadJSON(w, r, &inputLens)
 	if err != nil {
-		http.Error(w, "invalid payload", http.StatusBadRequest)
+		helpers.WriteProblem(w, r, http.StatusBadRequest, "invalid payload")
 		return
 	}
 
 	err = h.deps.Validate.Struct(inputLens)
 	if err != nil {
-		errMap := helpers.ParseValidationErrors(err)
-		helpers.WriteJSON(w, http.StatusBadRequest, map[string]any{"errors": errMap}, nil)
+		helpers.WriteValidationProblem(w, r, err)
 		return
 	}
 
 	updatedLens, err := h.service.UpdateLens(ctx, uint(lensID), userID, inputLens)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusForbidden)
+		helpers.WriteError(w, r, h.deps.Logger, err)
 		return
 	}
 
@@ -170,20 +157,20 @@ func (h *LensHandler) DeleteLens(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	userID, ok := middlewares.GetUserIDFromContext(r.Context())
 	if !ok {
-		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		helpers.WriteProblem(w, r, http.StatusUnauthorized, "unauthorized")
 		return
 	}
 
 	idParam := chi.URLParam(r, "id")
 	lensID, err := strconv.ParseUint(idParam, 10, 64)
 	if err != nil {
-		http.Error(w, "invalid lens id", http.StatusBadRequest)
+		helpers.WriteProblem(w, r, http.StatusBadRequest, "invalid lens id")
 		return
 	}
 
 	err = h.service.DeleteLens(ctx, uint(lensID), userID)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusForbidden)
+		helpers.WriteError(w, r, h.deps.Logger, err)
 		return
 	}
 
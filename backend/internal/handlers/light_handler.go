@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/georgiev098/film-manager/backend/internal/core"
+	"github.com/georgiev098/film-manager/backend/internal/helpers"
+	"github.com/georgiev098/film-manager/backend/internal/services"
+)
+
+type LightHandler struct {
+	deps    *core.AppDeps
+	service *services.LightService
+}
+
+func NewLightHandler(deps *core.AppDeps) *LightHandler {
+	return &LightHandler{
+		deps:    deps,
+		service: services.NewLightService(),
+	}
+}
+
+// GetLight returns sunrise, sunset, golden and blue hour for ?lat=&lng=&date=YYYY-MM-DD&tz=Europe/Sofia
+func (h *LightHandler) GetLight(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	lat, err := strconv.ParseFloat(query.Get("lat"), 64)
+	if err != nil || lat < -90 || lat > 90 {
+		helpers.WriteProblem(w, r, http.StatusBadRequest, "invalid latitude")
+		return
+	}
+
+	lng, err := strconv.ParseFloat(query.Get("lng"), 64)
+	if err != nil || lng < -180 || lng > 180 {
+		helpers.WriteProblem(w, r, http.StatusBadRequest, "invalid longitude")
+		return
+	}
+
+	loc := time.UTC
+	if tz := query.Get("tz"); tz != "" {
+		loc, err = time.LoadLocation(tz)
+		if err != nil {
+			helpers.WriteProblem(w, r, http.StatusBadRequest, "invalid timezone")
+			return
+		}
+	}
+
+	day := time.Now().In(loc)
+	if date := query.Get("date"); date != "" {
+		day, err = time.ParseInLocation("2006-01-02", date, loc)
+		if err != nil {
+			helpers.WriteProblem(w, r, http.StatusBadRequest, "invalid date, expected YYYY-MM-DD")
+			return
+		}
+	}
+
+	windows := h.service.GetLightWindows(lat, lng, day, loc)
+
+	helpers.WriteJSON(w, http.StatusOK, windows, nil)
+}
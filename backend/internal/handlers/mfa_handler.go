@@ -0,0 +1,193 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/georgiev098/film-manager/backend/internal/core"
+	"github.com/georgiev098/film-manager/backend/internal/dtos"
+	"github.com/georgiev098/film-manager/backend/internal/helpers"
+	"github.com/georgiev098/film-manager/backend/internal/middlewares"
+	"github.com/georgiev098/film-manager/backend/internal/repositories"
+	"github.com/georgiev098/film-manager/backend/internal/services"
+)
+
+type MFAHandler struct {
+	deps       *core.AppDeps
+	service    *services.MFAService
+	loginGuard *services.LoginGuard
+}
+
+func NewMFAHandler(deps *core.AppDeps) *MFAHandler {
+
+	service := services.NewMFAService(
+		repositories.NewUserRepo(deps.DB),
+		repositories.NewRecoveryCodeRepo(deps.DB),
+		deps.Audit,
+		deps.Passwords,
+		deps.Limits,
+		deps.Config.Auth.AccessSecret,
+	)
+
+	return &MFAHandler{
+		deps:       deps,
+		service:    service,
+		loginGuard: services.NewLoginGuard(deps.Limits, deps.Config.LoginProtection),
+	}
+}
+
+// Enroll starts TOTP setup and returns the secret, otpauth URI and a QR code PNG
+func (h *MFAHandler) Enroll(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middlewares.GetUserIDFromContext(r.Context())
+	if !ok {
+		helpers.WriteProblem(w, r, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	var input dtos.MFAEnrollRequest
+	err := helpers.ReadJSON(w, r, &input)
+	if err != nil {
+		helpers.WriteProblem(w, r, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	enrollment, err := h.service.Enroll(r.Context(), userID, reauth(r, input.Password))
+	if err != nil {
+		helpers.WriteError(w, r, h.deps.Logger, err)
+		return
+	}
+
+	helpers.WriteJSON(w, http.StatusOK, enrollment, nil)
+}
+
+// Confirm enables TOTP with a first code and returns the recovery codes
+func (h *MFAHandler) Confirm(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middlewares.GetUserIDFromContext(r.Context())
+	if !ok {
+		helpers.WriteProblem(w, r, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	var input dtos.MFAConfirmRequest
+	err := helpers.ReadJSON(w, r, &input)
+	if err != nil {
+		helpers.WriteProblem(w, r, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	err = h.deps.Validate.Struct(input)
+	if err != nil {
+		helpers.WriteValidationProblem(w, r, err)
+		return
+	}
+
+	codes, err := h.service.Confirm(r.Context(), userID, input.Code, reauth(r, input.Password))
+	if err != nil {
+		helpers.WriteError(w, r, h.deps.Logger, err)
+		return
+	}
+
+	helpers.WriteJSON(w, http.StatusOK, map[string]any{"recovery_codes": codes}, nil)
+}
+
+func (h *MFAHandler) Disable(w http.ResponseWriter, r *http.Request) {
+	userID, input, ok := h.readCode(w, r)
+	if !ok {
+		return
+	}
+
+	ok = h.guardCode(w, r, userID, func() error {
+		return h.service.Disable(r.Context(), userID, input.Code)
+	})
+	if !ok {
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *MFAHandler) RegenerateRecoveryCodes(w http.ResponseWriter, r *http.Request) {
+	userID, input, ok := h.readCode(w, r)
+	if !ok {
+		return
+	}
+
+	var codes []string
+
+	ok = h.guardCode(w, r, userID, func() error {
+		var err error
+		codes, err = h.service.RegenerateRecoveryCodes(r.Context(), userID, input.Code)
+		return err
+	})
+	if !ok {
+		return
+	}
+
+	helpers.WriteJSON(w, http.StatusOK, map[string]any{"recovery_codes": codes}, nil)
+}
+
+func (h *MFAHandler) readCode(w http.ResponseWriter, r *http.Request) (uint, dtos.MFACodeRequest, bool) {
+	var input dtos.MFACodeRequest
+
+	userID, ok := middlewares.GetUserIDFromContext(r.Context())
+	if !ok {
+		helpers.WriteProblem(w, r, http.StatusUnauthorized, "unauthorized")
+		return 0, input, false
+	}
+
+	err := helpers.ReadJSON(w, r, &input)
+	if err != nil {
+		helpers.WriteProblem(w, r, http.StatusBadRequest, "invalid request body")
+		return 0, input, false
+	}
+
+	err = h.deps.Validate.Struct(input)
+	if err != nil {
+		helpers.WriteValidationProblem(w, r, err)
+		return 0, input, false
+	}
+
+	return userID, input, true
+}
+
+// guardCode runs check, which verifies a second factor code, under the same
+// attempt limit as the MFA login step, so a stolen access token can't be used
+// to guess codes. Writes the error response and returns false on failure.
+func (h *MFAHandler) guardCode(w http.ResponseWriter, r *http.Request, userID uint, check func() error) bool {
+	ctx := r.Context()
+
+	wait, err := h.loginGuard.BeginSecondFactor(ctx, userID)
+	if err != nil {
+		helpers.WriteError(w, r, h.deps.Logger, err)
+		return false
+	}
+	if wait > 0 {
+		writeTooManyRequests(w, r, wait)
+		return false
+	}
+
+	err = check()
+	if err != nil {
+		if !errors.Is(err, services.ErrInvalidMFACode) {
+			if err := h.loginGuard.SecondFactorAborted(ctx, userID); err != nil {
+				h.deps.Logger.Println("login guard error:", err)
+			}
+		}
+		helpers.WriteError(w, r, h.deps.Logger, err)
+		return false
+	}
+
+	if err := h.loginGuard.SecondFactorSucceeded(ctx, userID); err != nil {
+		h.deps.Logger.Println("login guard error:", err)
+	}
+
+	return true
+}
+
+// reauth pairs the password a user sent with when their session started
+func reauth(r *http.Request, password string) services.Reauth {
+	return services.Reauth{
+		Password: password,
+		AuthTime: middlewares.GetAuthTimeFromContext(r.Context()),
+	}
+}
@@ -0,0 +1,187 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/georgiev098/film-manager/backend/internal/core"
+	"github.com/georgiev098/film-manager/backend/internal/helpers"
+	"github.com/georgiev098/film-manager/backend/internal/repositories"
+	"github.com/georgiev098/film-manager/backend/internal/services"
+	"github.com/go-chi/chi/v5"
+)
+
+const (
+	oidcFlowCookie     = "oidc_flow"
+	oidcFlowCookiePath = "/auth/oidc"
+	oidcFlowTTL        = 10 * time.Minute
+)
+
+// OIDCHandler runs the browser side of OpenID Connect logins. Results are sent
+// back to the frontend as redirects: on success the refresh cookie is set and the
+// frontend calls POST /auth/refresh for an access token.
+type OIDCHandler struct {
+	deps        *core.AppDeps
+	oidc        *services.OIDCService
+	authService *services.AuthService
+	mfa         *services.MFAService
+}
+
+func NewOIDCHandler(deps *core.AppDeps) *OIDCHandler {
+	userRepo := repositories.NewUserRepo(deps.DB)
+	refreshTokenRepo := repositories.CreateRefreshTokenRepository(deps.DB)
+
+	return &OIDCHandler{
+		deps: deps,
+		oidc: services.NewOIDCService(
+			userRepo,
+			repositories.NewUserIdentityRepo(deps.DB),
+			deps.Validate,
+			deps.Config.OIDC.Providers,
+			deps.Config.OIDC.RedirectBaseURL,
+		),
+		authService: services.NewAuthService(refreshTokenRepo, userRepo, deps.Audit, deps.Logger, deps.Keys, deps.Config.Auth.Issuer, deps.Config.Auth.Audience, deps.Config.Auth.AccessTTL, deps.Config.Auth.RefreshTTL),
+		mfa:         services.NewMFAService(userRepo, repositories.NewRecoveryCodeRepo(deps.DB), deps.Audit, deps.Passwords, deps.Limits, deps.Config.Auth.AccessSecret),
+	}
+}
+
+func (h *OIDCHandler) Providers(w http.ResponseWriter, r *http.Request) {
+	helpers.WriteJSON(w, http.StatusOK, h.oidc.Providers(), nil)
+}
+
+// Login redirects the browser to the identity provider
+func (h *OIDCHandler) Login(w http.ResponseWriter, r *http.Request) {
+	authURL, flow, err := h.oidc.Begin(r.Context(), chi.URLParam(r, "provider"))
+	if err != nil {
+		if errors.Is(err, services.ErrUnknownOIDCProvider) {
+			helpers.WriteError(w, r, h.deps.Logger, err)
+			return
+		}
+		h.deps.Logger.Println("oidc login error:", err)
+		helpers.WriteProblem(w, r, http.StatusBadGateway, "identity provider unavailable")
+		return
+	}
+
+	value, err := json.Marshal(flow)
+	if err != nil {
+		helpers.WriteError(w, r, h.deps.Logger, err)
+		return
+	}
+
+	// Lax, so the cookie comes back on the provider's top-level redirect
+	helpers.SetCookie(w, h.deps.Config.Cookies, &http.Cookie{
+		Name:     oidcFlowCookie,
+		Value:    base64.RawURLEncoding.EncodeToString(value),
+		Path:     oidcFlowCookiePath,
+		SameSite: http.SameSiteLaxMode,
+		Expires:  time.Now().Add(oidcFlowTTL),
+	})
+
+	http.Redirect(w, r, authURL, http.StatusFound)
+}
+
+// Callback is where the identity provider sends the browser back to
+func (h *OIDCHandler) Callback(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	flow := h.readFlow(r)
+
+	// The flow is single use
+	helpers.ClearCookie(w, h.deps.Config.Cookies, oidcFlowCookie, oidcFlowCookiePath)
+
+	query := r.URL.Query()
+	if providerErr := query.Get("error"); providerErr != "" {
+		h.redirectToApp(w, r, "/login", url.Values{"error": {"oidc_" + providerErr}}, "")
+		return
+	}
+
+	if flow == nil || flow.Provider != chi.URLParam(r, "provider") {
+		h.redirectToApp(w, r, "/login", url.Values{"error": {"oidc_failed"}}, "")
+		return
+	}
+
+	user, err := h.oidc.Complete(ctx, flow, query.Get("state"), query.Get("code"))
+	if err != nil {
+		code := "oidc_failed"
+		switch {
+		case errors.Is(err, services.ErrUserDisabled):
+			code = "account_disabled"
+		case errors.Is(err, services.ErrOIDCEmailNotVerified):
+			code = "oidc_email_not_verified"
+		case errors.Is(err, services.ErrOIDCAccountNotLinked):
+			code = "oidc_account_not_linked"
+		case errors.Is(err, services.ErrOIDCProfileInvalid):
+			code = "oidc_profile_invalid"
+		}
+		h.deps.Logger.Println("oidc callback error:", err)
+		h.redirectToApp(w, r, "/login", url.Values{"error": {code}}, "")
+		return
+	}
+
+	// The identity provider doesn't replace our own second factor
+	if user.TOTPEnabled {
+		challenge, err := h.mfa.CreateChallenge(user)
+		if err != nil {
+			h.deps.Logger.Println("mfa challenge error:", err)
+			h.redirectToApp(w, r, "/login", url.Values{"error": {"oidc_failed"}}, "")
+			return
+		}
+
+		// In the fragment, so it never reaches server logs
+		h.redirectToApp(w, r, "/login/mfa", nil, "mfa_token="+url.QueryEscape(challenge))
+		return
+	}
+
+	_, refreshToken, err := h.authService.Login(ctx, user, clientInfo(r))
+	if err != nil {
+		h.deps.Logger.Println("token issue error:", err)
+		h.redirectToApp(w, r, "/login", url.Values{"error": {"oidc_failed"}}, "")
+		return
+	}
+
+	// The frontend gets the CSRF token from GET /auth/csrf before refreshing
+	_, err = setSessionCookies(w, h.deps, refreshToken)
+	if err != nil {
+		h.deps.Logger.Println("csrf token error:", err)
+		h.redirectToApp(w, r, "/login", url.Values{"error": {"oidc_failed"}}, "")
+		return
+	}
+
+	h.redirectToApp(w, r, "/login/callback", nil, "")
+}
+
+func (h *OIDCHandler) readFlow(r *http.Request) *services.OIDCFlow {
+	cookie, err := helpers.ReadCookie(r, h.deps.Config.Cookies, oidcFlowCookie)
+	if err != nil {
+		return nil
+	}
+
+	value, err := base64.RawURLEncoding.DecodeString(cookie)
+	if err != nil {
+		return nil
+	}
+
+	var flow services.OIDCFlow
+	if err := json.Unmarshal(value, &flow); err != nil {
+		return nil
+	}
+
+	return &flow
+}
+
+func (h *OIDCHandler) redirectToApp(w http.ResponseWriter, r *http.Request, path string, query url.Values, fragment string) {
+	target := strings.TrimRight(h.deps.Config.AppURL, "/") + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+	if fragment != "" {
+		target += "#" + fragment
+	}
+
+	http.Redirect(w, r, target, http.StatusFound)
+}
@@ -0,0 +1,206 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/georgiev098/film-manager/backend/internal/core"
+	"github.com/georgiev098/film-manager/backend/internal/dtos"
+	"github.com/georgiev098/film-manager/backend/internal/helpers"
+	"github.com/georgiev098/film-manager/backend/internal/middlewares"
+	"github.com/georgiev098/film-manager/backend/internal/models"
+	"github.com/georgiev098/film-manager/backend/internal/services"
+	"github.com/go-chi/chi/v5"
+)
+
+type PrintHandler struct {
+	deps    *core.AppDeps
+	service *services.PrintService
+}
+
+func NewPrintHandler(deps *core.AppDeps) *PrintHandler {
+	return &PrintHandler{
+		deps:    deps,
+		service: services.NewPrintService(deps.DB),
+	}
+}
+
+// GetPrints lists the user's prints, those of one frame with ?frame=
+func (h *PrintHandler) GetPrints(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middlewares.GetUserIDFromContext(r.Context())
+	if !ok {
+		helpers.WriteProblem(w, r, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	var frameID *uint
+
+	if value := r.URL.Query().Get("frame"); value != "" {
+		id, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			helpers.WriteProblem(w, r, http.StatusBadRequest, "invalid frame id")
+			return
+		}
+
+		frame := uint(id)
+		frameID = &frame
+	}
+
+	prints, err := h.service.GetAllForUser(r.Context(), userID, frameID)
+	if err != nil {
+		helpers.WriteError(w, r, h.deps.Logger, err)
+		return
+	}
+
+	helpers.WriteJSON(w, http.StatusOK, prints, nil)
+}
+
+// CreatePrint logs a first attempt at printing a frame
+func (h *PrintHandler) CreatePrint(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middlewares.GetUserIDFromContext(r.Context())
+	if !ok {
+		helpers.WriteProblem(w, r, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	var print models.Print
+
+	err := helpers.ReadJSON(w, r, &print)
+	if err != nil {
+		helpers.WriteProblem(w, r, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	print.UserID = userID
+
+	err = h.deps.Validate.Struct(print)
+	if err != nil {
+		helpers.WriteValidationProblem(w, r, err)
+		return
+	}
+
+	err = h.service.CreatePrint(r.Context(), &print)
+	if err != nil {
+		helpers.WriteError(w, r, h.deps.Logger, err)
+		return
+	}
+
+	helpers.WriteJSON(w, http.StatusCreated, print, nil)
+}
+
+func (h *PrintHandler) GetPrintByID(w http.ResponseWriter, r *http.Request) {
+	printID, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		helpers.WriteProblem(w, r, http.StatusBadRequest, "invalid print id")
+		return
+	}
+
+	userID, ok := middlewares.GetUserIDFromContext(r.Context())
+	if !ok {
+		helpers.WriteProblem(w, r, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	print, err := h.service.GetPrintByID(r.Context(), uint(printID), userID)
+	if err != nil {
+		helpers.WriteError(w, r, h.deps.Logger, err)
+		return
+	}
+
+	helpers.WriteJSON(w, http.StatusOK, print, nil)
+}
+
+func (h *PrintHandler) UpdatePrint(w http.ResponseWriter, r *http.Request) {
+	printID, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		helpers.WriteProblem(w, r, http.StatusBadRequest, "invalid print id")
+		return
+	}
+
+	userID, ok := middlewares.GetUserIDFromContext(r.Context())
+	if !ok {
+		helpers.WriteProblem(w, r, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	var input dtos.PrintUpdate
+
+	err = helpers.ReadJSON(w, r, &input)
+	if err != nil {
+		helpers.WriteProblem(w, r, http.StatusBadRequest, "invalid payload")
+		return
+	}
+
+	err = h.deps.Validate.Struct(input)
+	if err != nil {
+		helpers.WriteValidationProblem(w, r, err)
+		return
+	}
+
+	print, err := h.service.UpdatePrint(r.Context(), uint(printID), userID, input)
+	if err != nil {
+		helpers.WriteError(w, r, h.deps.Logger, err)
+		return
+	}
+
+	helpers.WriteJSON(w, http.StatusOK, print, nil)
+}
+
+// Reprint logs the next version of the print in the URL, recording what changed
+func (h *PrintHandler) Reprint(w http.ResponseWriter, r *http.Request) {
+	printID, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		helpers.WriteProblem(w, r, http.StatusBadRequest, "invalid print id")
+		return
+	}
+
+	userID, ok := middlewares.GetUserIDFromContext(r.Context())
+	if !ok {
+		helpers.WriteProblem(w, r, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	var input dtos.Reprint
+
+	err = helpers.ReadJSON(w, r, &input)
+	if err != nil {
+		helpers.WriteProblem(w, r, http.StatusBadRequest, "invalid payload")
+		return
+	}
+
+	err = h.deps.Validate.Struct(input)
+	if err != nil {
+		helpers.WriteValidationProblem(w, r, err)
+		return
+	}
+
+	print, err := h.service.Reprint(r.Context(), uint(printID), userID, input)
+	if err != nil {
+		helpers.WriteError(w, r, h.deps.Logger, err)
+		return
+	}
+
+	helpers.WriteJSON(w, http.StatusCreated, print, nil)
+}
+
+func (h *PrintHandler) DeletePrint(w http.ResponseWriter, r *http.Request) {
+	printID, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		helpers.WriteProblem(w, r, http.StatusBadRequest, "invalid print id")
+		return
+	}
+
+	userID, ok := middlewares.GetUserIDFromContext(r.Context())
+	if !ok {
+		helpers.WriteProblem(w, r, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	err = h.service.DeletePrint(r.Context(), uint(printID), userID)
+	if err != nil {
+		helpers.WriteError(w, r, h.deps.Logger, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
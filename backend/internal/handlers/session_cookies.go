@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/georgiev098/film-manager/backend/internal/core"
+	"github.com/georgiev098/film-manager/backend/internal/helpers"
+	"github.com/georgiev098/film-manager/backend/internal/middlewares"
+)
+
+const (
+	refreshCookie     = "refresh_token"
+	refreshCookiePath = "/auth" // only sent to the endpoints that use it
+)
+
+// setSessionCookies stores a new refresh token together with a fresh CSRF
+// token, which is returned so the client can send it back as X-CSRF-Token
+func setSessionCookies(w http.ResponseWriter, deps *core.AppDeps, refreshToken string) (string, error) {
+	csrfToken, err := middlewares.NewCSRFToken()
+	if err != nil {
+		return "", err
+	}
+
+	setRefreshCookie(w, deps, refreshToken)
+	setCSRFCookie(w, deps, csrfToken)
+
+	return csrfToken, nil
+}
+
+func setRefreshCookie(w http.ResponseWriter, deps *core.AppDeps, refreshToken string) {
+	helpers.SetCookie(w, deps.Config.Cookies, &http.Cookie{
+		Name:    refreshCookie,
+		Value:   refreshToken,
+		Path:    refreshCookiePath,
+		Expires: time.Now().Add(deps.Config.Auth.RefreshTTL),
+	})
+}
+
+func setCSRFCookie(w http.ResponseWriter, deps *core.AppDeps, csrfToken string) {
+	helpers.SetCookie(w, deps.Config.Cookies, &http.Cookie{
+		Name:    middlewares.CSRFCookie,
+		Value:   csrfToken,
+		Path:    middlewares.CSRFCookiePath,
+		Expires: time.Now().Add(deps.Config.Auth.RefreshTTL),
+	})
+}
+
+func clearSessionCookies(w http.ResponseWriter, deps *core.AppDeps) {
+	helpers.ClearCookie(w, deps.Config.Cookies, refreshCookie, refreshCookiePath)
+	helpers.ClearCookie(w, deps.Config.Cookies, middlewares.CSRFCookie, middlewares.CSRFCookiePath)
+}
+
+func readRefreshCookie(r *http.Request, deps *core.AppDeps) (string, error) {
+	return helpers.ReadCookie(r, deps.Config.Cookies, refreshCookie)
+}
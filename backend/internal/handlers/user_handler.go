@@ -6,78 +6,232 @@ import (
 	"github.com/georgiev098/film-manager/backend/internal/core"
 	"github.com/georgiev098/film-manager/backend/internal/dtos"
 	"github.com/georgiev098/film-manager/backend/internal/helpers"
+	"github.com/georgiev098/film-manager/backend/internal/middlewares"
+	"github.com/georgiev098/film-manager/backend/internal/password"
 	"github.com/georgiev098/film-manager/backend/internal/repositories"
 	"github.com/georgiev098/film-manager/backend/internal/services"
 )
 
+// UserHandler serves the current user's own account under /me
 type UserHandler struct {
-	service *services.UserService
-	deps    *core.AppDeps
+	service      *services.UserService
+	account      *services.AccountService
+	authService  *services.AuthService
+	accessTokens *services.AccessTokenService
+	verification *services.EmailVerificationService
+	deps         *core.AppDeps
 }
 
 func NewUserHandler(deps *core.AppDeps) *UserHandler {
 	repo := repositories.NewUserRepo(deps.DB)
-	service := services.NewUserService(repo)
+	refreshTokenRepo := repositories.CreateRefreshTokenRepository(deps.DB)
+
+	service := services.NewUserService(repo, deps.Passwords, deps.Audit)
+	authService := services.NewAuthService(refreshTokenRepo, repo, deps.Audit, deps.Logger, deps.Keys, deps.Config.Auth.Issuer, deps.Config.Auth.Audience, deps.Config.Auth.AccessTTL, deps.Config.Auth.RefreshTTL)
+
+	accessTokens := services.NewAccessTokenService(repositories.NewPersonalAccessTokenRepo(deps.DB), repo)
+
+	account := services.NewAccountService(deps.DB, deps.Audit, deps.Passwords)
+
+	verification := services.NewEmailVerificationService(
+		repo,
+		deps.Mailer,
+		deps.Logger,
+		deps.Config.AppURL,
+		deps.Config.Auth.VerificationSecret,
+		deps.Config.Auth.VerificationTTL,
+	)
 
 	return &UserHandler{
-		service: service,
-		deps:    deps,
+		service:      service,
+		account:      account,
+		authService:  authService,
+		accessTokens: accessTokens,
+		verification: verification,
+		deps:         deps,
 	}
 }
 
-func (h *UserHandler) SignUp(w http.ResponseWriter, r *http.Request) {
+func (h *UserHandler) GetMe(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middlewares.GetUserIDFromContext(r.Context())
+	if !ok {
+		helpers.WriteProblem(w, r, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	user, err := h.service.GetUserByID(r.Context(), userID)
+	if err != nil {
+		helpers.WriteError(w, r, h.deps.Logger, err)
+		return
+	}
+
+	helpers.WriteJSON(w, http.StatusOK, user, nil)
+}
+
+func (h *UserHandler) UpdateMe(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middlewares.GetUserIDFromContext(r.Context())
+	if !ok {
+		helpers.WriteProblem(w, r, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	var input dtos.ProfileUpdate
+
+	err := helpers.ReadJSON(w, r, &input)
+	if err != nil {
+		helpers.WriteProblem(w, r, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	err = h.deps.Validate.Struct(input)
+	if err != nil {
+		helpers.WriteValidationProblem(w, r, err)
+		return
+	}
+
+	user, err := h.service.UpdateProfile(r.Context(), userID, input)
+	if err != nil {
+		helpers.WriteError(w, r, h.deps.Logger, err)
+		return
+	}
+
+	helpers.WriteJSON(w, http.StatusOK, user, nil)
+}
+
+// ChangePassword logs out every session, revokes the personal access tokens
+// and starts a new session for the caller
+func (h *UserHandler) ChangePassword(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
-	var input dtos.SignUpRequest
+	userID, ok := middlewares.GetUserIDFromContext(ctx)
+	if !ok {
+		helpers.WriteProblem(w, r, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	var input dtos.ChangePasswordRequest
 
 	err := helpers.ReadJSON(w, r, &input)
 	if err != nil {
-		http.Error(w, "Invalid request", http.StatusBadRequest)
+		helpers.WriteProblem(w, r, http.StatusBadRequest, "invalid request body")
 		return
 	}
 
-	// --- VALIDATION START ---
-	if err := h.deps.Validate.Struct(input); err != nil {
-		errMap := helpers.ParseValidationErrors(err)
-		helpers.WriteJSON(w, http.StatusBadRequest, map[string]any{"errors": errMap}, nil)
+	user, err := h.service.GetUserByID(ctx, userID)
+	if err != nil {
+		helpers.WriteError(w, r, h.deps.Logger, err)
 		return
 	}
-	// --- VALIDATION END ---
 
-	user, err := h.service.SignUp(ctx, input.Email, input.Password, input.FirstName, input.LastName)
+	validateCtx := password.WithUserInputs(ctx, user.Email, user.FirstName, user.LastName)
 
+	err = h.deps.Validate.StructCtx(validateCtx, input)
 	if err != nil {
-		http.Error(w, "could not create user", http.StatusBadRequest)
+		helpers.WriteValidationProblem(w, r, err)
 		return
 	}
 
-	helpers.WriteJSON(w, http.StatusCreated, user, nil)
+	err = h.service.ChangePassword(ctx, userID, input.CurrentPassword, input.NewPassword)
+	if err != nil {
+		helpers.WriteError(w, r, h.deps.Logger, err)
+		return
+	}
+
+	err = h.authService.LogoutAll(ctx, userID)
+	if err != nil {
+		helpers.WriteError(w, r, h.deps.Logger, err)
+		return
+	}
+
+	// Tokens made with the old password shouldn't outlive it
+	err = h.accessTokens.RevokeAll(ctx, userID)
+	if err != nil {
+		helpers.WriteError(w, r, h.deps.Logger, err)
+		return
+	}
+
+	accessToken, refreshToken, err := h.authService.Login(ctx, user, clientInfo(r))
+	if err != nil {
+		helpers.WriteError(w, r, h.deps.Logger, err)
+		return
+	}
+
+	csrfToken, err := setSessionCookies(w, h.deps, refreshToken)
+	if err != nil {
+		helpers.WriteError(w, r, h.deps.Logger, err)
+		return
+	}
+
+	helpers.WriteJSON(w, http.StatusOK, map[string]any{
+		"access_token": accessToken,
+		"csrf_token":   csrfToken,
+	}, nil)
 }
 
-func (h *UserHandler) Login(w http.ResponseWriter, r *http.Request) {
+// ChangeEmail switches to a new address and sends a verification link to it
+func (h *UserHandler) ChangeEmail(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
-	var input dtos.LoginRequest
+
+	userID, ok := middlewares.GetUserIDFromContext(ctx)
+	if !ok {
+		helpers.WriteProblem(w, r, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	var input dtos.ChangeEmailRequest
 
 	err := helpers.ReadJSON(w, r, &input)
 	if err != nil {
-		http.Error(w, "invalid request", http.StatusBadRequest)
+		helpers.WriteProblem(w, r, http.StatusBadRequest, "invalid request body")
 		return
 	}
 
-	// --- VALIDATION START ---
-	if err := h.deps.Validate.Struct(input); err != nil {
-		errMap := helpers.ParseValidationErrors(err)
-		helpers.WriteJSON(w, http.StatusBadRequest, map[string]any{"errors": errMap}, nil)
+	err = h.deps.Validate.Struct(input)
+	if err != nil {
+		helpers.WriteValidationProblem(w, r, err)
 		return
 	}
-	// --- VALIDATION END ---
 
-	user, err := h.service.Login(ctx, input.Email, input.Password)
+	user, err := h.service.ChangeEmail(ctx, userID, input.Email, input.Password)
 	if err != nil {
-		http.Error(w, "invalid credentials", http.StatusUnauthorized)
+		helpers.WriteError(w, r, h.deps.Logger, err)
 		return
 	}
 
-	// TODO: JWT at this point
+	if user.EmailVerifiedAt == nil {
+		err = h.verification.SendVerification(ctx, user)
+		if err != nil {
+			h.deps.Logger.Println("verification mail error:", err)
+		}
+	}
+
+	// Access tokens carry the verified flag, clients should refresh to pick it up
 	helpers.WriteJSON(w, http.StatusOK, user, nil)
 }
+
+func (h *UserHandler) DeleteMe(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middlewares.GetUserIDFromContext(r.Context())
+	if !ok {
+		helpers.WriteProblem(w, r, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	var input dtos.DeleteAccountRequest
+
+	err := helpers.ReadJSON(w, r, &input)
+	if err != nil {
+		helpers.WriteProblem(w, r, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	err = h.account.DeleteAccount(r.Context(), userID, reauth(r, input.Password))
+	if err != nil {
+		helpers.WriteError(w, r, h.deps.Logger, err)
+		return
+	}
+
+	// Clear the cookies
+	clearSessionCookies(w, h.deps)
+
+	w.WriteHeader(http.StatusNoContent)
+}
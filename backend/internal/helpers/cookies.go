@@ -0,0 +1,61 @@
+package helpers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/georgiev098/film-manager/backend/internal/core"
+)
+
+const hostPrefix = "__Host-"
+
+// CookieName returns the name a cookie is stored under with the given policy
+func CookieName(policy core.CookiePolicy, name string) string {
+	if policy.HostPrefix {
+		return hostPrefix + name
+	}
+	return name
+}
+
+// SetCookie sets c with the name, Secure, Domain and (unless c sets its own)
+// SameSite from policy. __Host- cookies must use path "/", so Path is
+// overridden when the prefix is on. Cookies are always HttpOnly.
+func SetCookie(w http.ResponseWriter, policy core.CookiePolicy, c *http.Cookie) {
+	cookie := *c
+
+	cookie.Name = CookieName(policy, c.Name)
+	cookie.Secure = policy.Secure
+	cookie.Domain = policy.Domain
+	cookie.HttpOnly = true
+
+	if cookie.SameSite == 0 {
+		cookie.SameSite = policy.SameSite
+	}
+
+	if policy.HostPrefix {
+		cookie.Path = "/"
+		cookie.Domain = ""
+	}
+
+	http.SetCookie(w, &cookie)
+}
+
+// ClearCookie removes a cookie set by SetCookie with the same name and path
+func ClearCookie(w http.ResponseWriter, policy core.CookiePolicy, name, path string) {
+	SetCookie(w, policy, &http.Cookie{
+		Name:    name,
+		Value:   "",
+		Path:    path,
+		Expires: time.Unix(0, 0),
+		MaxAge:  -1,
+	})
+}
+
+// ReadCookie returns the value of a cookie set by SetCookie
+func ReadCookie(r *http.Request, policy core.CookiePolicy, name string) (string, error) {
+	cookie, err := r.Cookie(CookieName(policy, name))
+	if err != nil {
+		return "", err
+	}
+	return cookie.Value, nil
+}
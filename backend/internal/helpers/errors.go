@@ -0,0 +1,40 @@
+package helpers
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/georgiev098/film-manager/backend/internal/apperrors"
+)
+
+var errorStatus = map[apperrors.Kind]int{
+	apperrors.KindNotFound:     http.StatusNotFound,
+	apperrors.KindForbidden:    http.StatusForbidden,
+	apperrors.KindConflict:     http.StatusConflict,
+	apperrors.KindValidation:   http.StatusBadRequest,
+	apperrors.KindUnauthorized: http.StatusUnauthorized,
+}
+
+// WriteError answers with the problem for err's kind. Errors that aren't domain
+// errors are logged and answered with a plain 500, their text never reaches the client.
+func WriteError(w http.ResponseWriter, r *http.Request, logger *log.Logger, err error) {
+	kind := apperrors.KindOf(err)
+
+	status, ok := errorStatus[kind]
+	if !ok {
+		logger.Printf("%s %s [%s]: %v", r.Method, r.URL.Path, RequestIDFromContext(r.Context()), err)
+		status = http.StatusInternalServerError
+	}
+
+	if kind == apperrors.KindValidation {
+		writeProblem(w, r, Problem{
+			Type:   ProblemValidation,
+			Title:  "Validation failed",
+			Status: status,
+			Detail: apperrors.Message(err),
+		})
+		return
+	}
+
+	WriteProblem(w, r, status, apperrors.Message(err))
+}
@@ -5,11 +5,16 @@ import (
 	"errors"
 	"io"
 	"maps"
+	"net"
 	"net/http"
+	"reflect"
 	"strconv"
+	"strings"
+	"unicode/utf8"
 
+	"github.com/georgiev098/film-manager/backend/internal/i18n"
+	ut "github.com/go-playground/universal-translator"
 	"github.com/go-playground/validator/v10"
-	"golang.org/x/crypto/bcrypt"
 )
 
 // helper to convert string env vars to int
@@ -21,6 +26,30 @@ func AtoiOrDefault(s string, def int) int {
 	return val
 }
 
+// BoolOrDefault parses "true"/"false"/"1"/"0" style env values
+func BoolOrDefault(s string, def bool) bool {
+	val, err := strconv.ParseBool(s)
+	if err != nil {
+		return def
+	}
+	return val
+}
+
+// Truncate shortens s to at most maxBytes bytes for a sized column, without
+// splitting a multi-byte UTF-8 character
+func Truncate(s string, maxBytes int) string {
+	if len(s) <= maxBytes {
+		return s
+	}
+
+	cut := maxBytes
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+
+	return s[:cut]
+}
+
 // ReadJSON decodes JSON from an HTTP request body into dst.
 // dst must be a pointer.
 func ReadJSON(w http.ResponseWriter, r *http.Request, dst any) error {
@@ -59,16 +88,37 @@ func WriteJSON(
 	return json.NewEncoder(w).Encode(data)
 }
 
-func HasPassword(password string) (string, error) {
-	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
-	return string(hash), err
+// ClientIP returns the IP of the directly connected client, without the port
+func ClientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
 }
 
-func CompareHashAndPassword(hash, password string) error {
-	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+// JSONFieldName makes validator report fields by their JSON name, so error
+// keys match the request body. Register it with RegisterTagNameFunc.
+func JSONFieldName(field reflect.StructField) string {
+	tag := field.Tag.Get("json")
+
+	// Left out of JSON, so there is no JSON name to report
+	if tag == "-" {
+		return field.Name
+	}
+
+	// The name is everything before options such as ",omitempty". As in
+	// encoding/json, "-," names the field "-".
+	name, _, _ := strings.Cut(tag, ",")
+	if name == "" {
+		return field.Name
+	}
+
+	return name
 }
 
-func ParseValidationErrors(err error) map[string]string {
+// ParseValidationErrors maps each failed field to a message in t's language
+func ParseValidationErrors(t ut.Translator, err error) map[string]string {
 	errs := make(map[string]string)
 	vErrs, ok := err.(validator.ValidationErrors)
 	if !ok {
@@ -76,38 +126,42 @@ func ParseValidationErrors(err error) map[string]string {
 	}
 
 	for _, e := range vErrs {
-		field := e.Field()
-
-		var message string
-		switch e.Tag() {
-		case "required":
-			message = "This field is required"
-		case "min":
-			message = "Value is too short (minimum " + e.Param() + " characters)"
-		case "max":
-			message = "Value is too long (maximum " + e.Param() + " characters)"
-		case "email":
-			message = "Invalid email format"
-		case "oneof":
-			message = "Must be one of: " + e.Param()
-		case "url":
-			message = "Must be a valid URL"
-		case "gt":
-			message = "Must be greater than " + e.Param()
-		case "lte":
-			message = "Must be " + e.Param() + " or earlier"
-		case "contains":
-			if e.Field() == "min_aperture" || e.Field() == "max_aperture" {
-				message = "Aperture must follow the format 'f/number' (e.g., f/2.8)"
-			} else {
-				message = "Value must contain: " + e.Param()
-			}
-		default:
-			message = "Invalid value (failed " + e.Tag() + ")"
-		}
-
-		errs[field] = message
+		errs[e.Field()] = validationMessage(t, e)
 	}
 
 	return errs
 }
+
+func validationMessage(t ut.Translator, e validator.FieldError) string {
+	// ActualTag resolves aliases such as "password" to the rule that failed
+	switch tag := e.ActualTag(); tag {
+	case "min":
+		return i18n.T(t, sizeKey(e, i18n.MinString, i18n.MinItems, i18n.MinNumber), e.Param())
+	case "max":
+		return i18n.T(t, sizeKey(e, i18n.MaxString, i18n.MaxItems, i18n.MaxNumber), e.Param())
+	case "contains":
+		if e.Field() == "min_aperture" || e.Field() == "max_aperture" {
+			return i18n.T(t, i18n.Aperture)
+		}
+		return i18n.T(t, i18n.Contains, e.Param())
+	case "required", "email", "url", "gtefield", "alphanum", "iso4217", "timezone",
+		"password_words", "password_strength", "password_breached":
+		return i18n.T(t, tag)
+	case "oneof", "gt", "lte":
+		return i18n.T(t, tag, e.Param())
+	default:
+		return i18n.T(t, i18n.InvalidValue, e.Tag())
+	}
+}
+
+// sizeKey picks the min/max wording for what is being measured
+func sizeKey(e validator.FieldError, text, items, number string) string {
+	switch e.Kind() {
+	case reflect.String:
+		return text
+	case reflect.Slice, reflect.Map, reflect.Array:
+		return items
+	default:
+		return number
+	}
+}
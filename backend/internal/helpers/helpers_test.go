@@ -0,0 +1,36 @@
+package helpers
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestJSONFieldName(t *testing.T) {
+	type input struct {
+		Plain       string `json:"plain"`
+		OmitEmpty   string `json:"min_aperture,omitempty"`
+		OnlyOptions string `json:",omitempty"`
+		Hidden      string `json:"-"`
+		Dash        string `json:"-,"`
+		NoTag       string
+	}
+
+	tests := []struct {
+		field string
+		want  string
+	}{
+		{"Plain", "plain"},
+		{"OmitEmpty", "min_aperture"},
+		{"OnlyOptions", "OnlyOptions"},
+		{"Hidden", "Hidden"},
+		{"Dash", "-"},
+		{"NoTag", "NoTag"},
+	}
+
+	for _, tt := range tests {
+		field, _ := reflect.TypeOf(input{}).FieldByName(tt.field)
+		if got := JSONFieldName(field); got != tt.want {
+			t.Errorf("JSONFieldName(%s) = %q, want %q", tt.field, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package helpers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/georgiev098/film-manager/backend/internal/i18n"
+)
+
+// Problem types, relative to the API's base URL
+const (
+	ProblemBadRequest       = "/problems/bad-request"
+	ProblemValidation       = "/problems/validation"
+	ProblemUnauthorized     = "/problems/unauthorized"
+	ProblemForbidden        = "/problems/forbidden"
+	ProblemNotFound         = "/problems/not-found"
+	ProblemMethodNotAllowed = "/problems/method-not-allowed"
+	ProblemConflict         = "/problems/conflict"
+	ProblemTooLarge         = "/problems/payload-too-large"
+	ProblemTooManyRequests  = "/problems/too-many-requests"
+	ProblemInternal         = "/problems/internal"
+	ProblemUpstream         = "/problems/upstream-unavailable"
+)
+
+var problemTypes = map[int]string{
+	http.StatusBadRequest:            ProblemBadRequest,
+	http.StatusUnauthorized:          ProblemUnauthorized,
+	http.StatusForbidden:             ProblemForbidden,
+	http.StatusNotFound:              ProblemNotFound,
+	http.StatusMethodNotAllowed:      ProblemMethodNotAllowed,
+	http.StatusConflict:              ProblemConflict,
+	http.StatusRequestEntityTooLarge: ProblemTooLarge,
+	http.StatusTooManyRequests:       ProblemTooManyRequests,
+	http.StatusInternalServerError:   ProblemInternal,
+	http.StatusBadGateway:            ProblemUpstream,
+}
+
+// Problem is an RFC 7807 error response body. Errors holds per-field messages
+// for validation failures.
+type Problem struct {
+	Type      string            `json:"type"`
+	Title     string            `json:"title"`
+	Status    int               `json:"status"`
+	Detail    string            `json:"detail,omitempty"`
+	Instance  string            `json:"instance,omitempty"`
+	RequestID string            `json:"request_id,omitempty"`
+	Errors    map[string]string `json:"errors,omitempty"`
+}
+
+// WriteProblem answers with an application/problem+json body for status
+func WriteProblem(w http.ResponseWriter, r *http.Request, status int, detail string) {
+	problemType, ok := problemTypes[status]
+	if !ok {
+		problemType = "about:blank"
+	}
+
+	writeProblem(w, r, Problem{
+		Type:   problemType,
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+	})
+}
+
+// WriteValidationProblem answers 400 with the failed fields of a validator
+// error, in the language negotiated by middlewares.Locale
+func WriteValidationProblem(w http.ResponseWriter, r *http.Request, err error) {
+	t := i18n.FromContext(r.Context())
+
+	writeProblem(w, r, Problem{
+		Type:   ProblemValidation,
+		Title:  i18n.T(t, i18n.ValidationTitle),
+		Status: http.StatusBadRequest,
+		Detail: i18n.T(t, i18n.ValidationDetail),
+		Errors: ParseValidationErrors(t, err),
+	})
+}
+
+func writeProblem(w http.ResponseWriter, r *http.Request, problem Problem) {
+	problem.Instance = r.URL.Path
+	problem.RequestID = RequestIDFromContext(r.Context())
+
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.Header().Del("Content-Length")
+	w.WriteHeader(problem.Status)
+
+	json.NewEncoder(w).Encode(problem)
+}
@@ -0,0 +1,107 @@
+// Package i18n translates user-facing messages. Locales are negotiated from
+// the Accept-Language header, English is the fallback.
+package i18n
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-playground/locales/bg"
+	"github.com/go-playground/locales/de"
+	"github.com/go-playground/locales/en"
+	ut "github.com/go-playground/universal-translator"
+)
+
+const DefaultLocale = "en"
+
+var universal = newUniversal()
+
+func newUniversal() *ut.UniversalTranslator {
+	fallback := en.New()
+	uni := ut.New(fallback, fallback, de.New(), bg.New())
+
+	for locale, messages := range bundles {
+		t, ok := uni.GetTranslator(locale)
+		if !ok {
+			panic("i18n: no locale data for " + locale)
+		}
+
+		for key, text := range messages {
+			if err := t.Add(key, text, false); err != nil {
+				panic("i18n: " + locale + "." + key + ": " + err.Error())
+			}
+		}
+	}
+
+	return uni
+}
+
+// Negotiate picks the best supported translator for an Accept-Language header,
+// e.g. "bg-BG,bg;q=0.9,en;q=0.8"
+func Negotiate(acceptLanguage string) ut.Translator {
+	type weighted struct {
+		tag string
+		q   float64
+	}
+
+	var tags []weighted
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if tag == "" || tag == "*" {
+			continue
+		}
+
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		if q <= 0 {
+			continue
+		}
+
+		// Only the language matters, regional variants share a bundle
+		lang, _, _ := strings.Cut(tag, "-")
+		tags = append(tags, weighted{tag: strings.ToLower(lang), q: q})
+	}
+
+	sort.SliceStable(tags, func(i, j int) bool { return tags[i].q > tags[j].q })
+
+	locales := make([]string, len(tags))
+	for i, t := range tags {
+		locales[i] = t.tag
+	}
+
+	t, _ := universal.FindTranslator(locales...)
+	return t
+}
+
+type translatorKey struct{}
+
+func WithTranslator(ctx context.Context, t ut.Translator) context.Context {
+	return context.WithValue(ctx, translatorKey{}, t)
+}
+
+// FromContext returns the translator set by middlewares.Locale, or English
+func FromContext(ctx context.Context) ut.Translator {
+	if t, ok := ctx.Value(translatorKey{}).(ut.Translator); ok {
+		return t
+	}
+	return universal.GetFallback()
+}
+
+// T translates key, falling back to English when the locale lacks it
+func T(t ut.Translator, key string, params ...string) string {
+	if msg, err := t.T(key, params...); err == nil {
+		return msg
+	}
+	if msg, err := universal.GetFallback().T(key, params...); err == nil {
+		return msg
+	}
+	return key
+}
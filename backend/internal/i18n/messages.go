@@ -0,0 +1,116 @@
+package i18n
+
+// Message keys. Validation keys are named after the validator tag, with a
+// suffix where the wording depends on the field's type.
+const (
+	ValidationTitle  = "validation_title"
+	ValidationDetail = "validation_detail"
+
+	Required         = "required"
+	MinString        = "min_string"
+	MaxString        = "max_string"
+	MinItems         = "min_items"
+	MaxItems         = "max_items"
+	MinNumber        = "min_number"
+	MaxNumber        = "max_number"
+	Email            = "email"
+	OneOf            = "oneof"
+	URL              = "url"
+	GreaterThan      = "gt"
+	AtMost           = "lte"
+	AtLeastField     = "gtefield"
+	Contains         = "contains"
+	Aperture         = "aperture"
+	Alphanumeric     = "alphanum"
+	Currency         = "iso4217"
+	Timezone         = "timezone"
+	PasswordWords    = "password_words"
+	PasswordStrength = "password_strength"
+	PasswordBreached = "password_breached"
+	InvalidValue     = "invalid_value"
+)
+
+// {0} is replaced with the rule's parameter, e.g. the minimum length
+var bundles = map[string]map[string]string{
+	"en": {
+		ValidationTitle:  "Validation failed",
+		ValidationDetail: "One or more fields are invalid",
+
+		Required:         "This field is required",
+		MinString:        "Value is too short (minimum {0} characters)",
+		MaxString:        "Value is too long (maximum {0} characters)",
+		MinItems:         "Must contain at least {0} items",
+		MaxItems:         "Must contain at most {0} items",
+		MinNumber:        "Must be at least {0}",
+		MaxNumber:        "Must be at most {0}",
+		Email:            "Invalid email format",
+		OneOf:            "Must be one of: {0}",
+		URL:              "Must be a valid URL",
+		GreaterThan:      "Must be greater than {0}",
+		AtMost:           "Must be {0} or less",
+		AtLeastField:     "Must not be less than the minimum",
+		Contains:         "Value must contain: {0}",
+		Aperture:         "Aperture must follow the format 'f/number' (e.g., f/2.8)",
+		Alphanumeric:     "Must contain only letters and digits",
+		Currency:         "Must be an ISO 4217 currency code, e.g. EUR",
+		Timezone:         "Must be an IANA time zone, e.g. Europe/Berlin",
+		PasswordWords:    "Password must not contain your name, email address or common words",
+		PasswordStrength: "Password is too easy to guess, try a longer passphrase or mix in more words",
+		PasswordBreached: "This password has appeared in a data breach, please choose another",
+		InvalidValue:     "Invalid value (failed {0})",
+	},
+	"de": {
+		ValidationTitle:  "Validierung fehlgeschlagen",
+		ValidationDetail: "Ein oder mehrere Felder sind ungültig",
+
+		Required:         "Dieses Feld ist erforderlich",
+		MinString:        "Der Wert ist zu kurz (mindestens {0} Zeichen)",
+		MaxString:        "Der Wert ist zu lang (höchstens {0} Zeichen)",
+		MinItems:         "Muss mindestens {0} Einträge enthalten",
+		MaxItems:         "Darf höchstens {0} Einträge enthalten",
+		MinNumber:        "Muss mindestens {0} sein",
+		MaxNumber:        "Darf höchstens {0} sein",
+		Email:            "Ungültiges E-Mail-Format",
+		OneOf:            "Muss einer der folgenden Werte sein: {0}",
+		URL:              "Muss eine gültige URL sein",
+		GreaterThan:      "Muss größer als {0} sein",
+		AtMost:           "Muss {0} oder kleiner sein",
+		AtLeastField:     "Darf nicht kleiner als das Minimum sein",
+		Contains:         "Der Wert muss {0} enthalten",
+		Aperture:         "Die Blende muss das Format 'f/Zahl' haben (z. B. f/2.8)",
+		Alphanumeric:     "Darf nur Buchstaben und Ziffern enthalten",
+		Currency:         "Muss ein ISO-4217-Währungscode sein, z. B. EUR",
+		Timezone:         "Muss eine IANA-Zeitzone sein, z. B. Europe/Berlin",
+		PasswordWords:    "Das Passwort darf weder Ihren Namen, Ihre E-Mail-Adresse noch gängige Wörter enthalten",
+		PasswordStrength: "Das Passwort ist zu leicht zu erraten, versuchen Sie eine längere Passphrase oder mehr Wörter",
+		PasswordBreached: "Dieses Passwort ist bei einem Datenleck aufgetaucht, bitte wählen Sie ein anderes",
+		InvalidValue:     "Ungültiger Wert (Regel {0} nicht erfüllt)",
+	},
+	"bg": {
+		ValidationTitle:  "Невалидни данни",
+		ValidationDetail: "Едно или повече полета са невалидни",
+
+		Required:         "Това поле е задължително",
+		MinString:        "Стойността е твърде кратка (минимум {0} символа)",
+		MaxString:        "Стойността е твърде дълга (максимум {0} символа)",
+		MinItems:         "Трябва да съдържа поне {0} елемента",
+		MaxItems:         "Може да съдържа най-много {0} елемента",
+		MinNumber:        "Трябва да бъде поне {0}",
+		MaxNumber:        "Трябва да бъде най-много {0}",
+		Email:            "Невалиден формат на имейл адрес",
+		OneOf:            "Трябва да бъде едно от: {0}",
+		URL:              "Трябва да бъде валиден URL адрес",
+		GreaterThan:      "Трябва да бъде по-голямо от {0}",
+		AtMost:           "Трябва да бъде {0} или по-малко",
+		AtLeastField:     "Не може да бъде по-малко от минимума",
+		Contains:         "Стойността трябва да съдържа: {0}",
+		Aperture:         "Блендата трябва да е във формат 'f/число' (напр. f/2.8)",
+		Alphanumeric:     "Може да съдържа само букви и цифри",
+		Currency:         "Трябва да бъде валутен код по ISO 4217, напр. EUR",
+		Timezone:         "Трябва да бъде часова зона по IANA, напр. Europe/Sofia",
+		PasswordWords:    "Паролата не трябва да съдържа вашето име, имейл адрес или често срещани думи",
+		PasswordStrength: "Паролата е твърде лесна за отгатване, опитайте по-дълга фраза или добавете още думи",
+		PasswordBreached: "Тази парола е изтекла при пробив на данни, моля, изберете друга",
+		InvalidValue:     "Невалидна стойност (правило {0})",
+	},
+}
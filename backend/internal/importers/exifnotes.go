@@ -0,0 +1,211 @@
+package importers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/georgiev098/film-manager/backend/internal/dtos"
+	"github.com/georgiev098/film-manager/backend/internal/models"
+)
+
+// ExifNotesParser reads the JSON roll export of Exif Notes. A file holds one
+// roll object, an array of rolls, or {"rolls": [...]}; each roll embeds its
+// camera, film stock and frames, and each frame embeds its lens.
+type ExifNotesParser struct{}
+
+func (ExifNotesParser) Name() string { return "exif-notes" }
+
+func (ExifNotesParser) Parse(data []byte) (*Result, error) {
+	var raw any
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+
+	var rolls []any
+	switch v := raw.(type) {
+	case []any:
+		rolls = v
+	case map[string]any:
+		if list, ok := v["rolls"].([]any); ok {
+			rolls = list
+		} else {
+			rolls = []any{v}
+		}
+	default:
+		return nil, errors.New("expected a roll object or an array of rolls")
+	}
+
+	result := newResult()
+	gear := newGearSet(result)
+
+	for i, r := range rolls {
+		roll, ok := r.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("roll %d is not an object", i+1)
+		}
+
+		var rec dtos.RollRecord
+		var cameraRef string
+		var stock map[string]any
+		var frames []any
+		var format models.CameraFormat
+
+		for key, value := range roll {
+			switch key {
+			case "camera":
+				if cam, ok := value.(map[string]any); ok {
+					cameraRef = gear.addCamera(exifNotesCamera(cam, result))
+				}
+			case "filmStock":
+				stock, _ = value.(map[string]any)
+			case "frames":
+				frames, _ = value.([]any)
+			case "format":
+				format = cameraFormat(stringValue(value))
+			case "date":
+				rec.LoadedAt = timeValue(stringValue(value))
+			case "unloaded":
+				rec.ExposedAt = timeValue(stringValue(value))
+			case "developed":
+				rec.DevelopedAt = timeValue(stringValue(value))
+			case "note":
+				rec.Notes = optional(stringValue(value))
+			case "id":
+			default:
+				result.unmapped("roll." + key)
+			}
+		}
+
+		if format == "" {
+			format = gear.cameraFormat(cameraRef)
+		}
+		if stock != nil {
+			rec.FilmStock = gear.addFilmStock(exifNotesFilmStock(stock, format, result))
+		}
+		rec.Camera = optionalRef(cameraRef)
+		rec.Status = rollStatus(rec.LoadedAt, rec.ExposedAt, rec.DevelopedAt)
+		rollRef := gear.addRoll(rec)
+
+		for n, f := range frames {
+			if frame, ok := f.(map[string]any); ok {
+				exifNotesFrame(frame, rollRef, n+1, gear)
+			}
+		}
+	}
+
+	return result, nil
+}
+
+func exifNotesFilmStock(stock map[string]any, format models.CameraFormat, result *Result) dtos.FilmStockRecord {
+	rec := dtos.FilmStockRecord{
+		Manufacturer: stringValue(stock["make"]),
+		Name:         stringValue(stock["model"]),
+		ISO:          intValue(stock["iso"]),
+		Format:       format,
+		Process:      filmProcess(stringValue(stock["process"])),
+	}
+
+	reportUnmapped(result, "filmStock.", stock, "id", "make", "model", "iso", "process")
+
+	return rec
+}
+
+func exifNotesCamera(cam map[string]any, result *Result) dtos.CameraRecord {
+	var rec dtos.CameraRecord
+
+	for key, value := range cam {
+		s := stringValue(value)
+		switch key {
+		case "make":
+			rec.Brand = s
+		case "model":
+			rec.CameraModel = s
+		case "serialNumber":
+			rec.SerialNumber = optional(s)
+		case "format":
+			rec.CameraFormat = cameraFormat(s)
+		case "id":
+		default:
+			result.unmapped("camera." + key)
+		}
+	}
+
+	if rec.CameraFormat == "" {
+		rec.CameraFormat = models.Format35mm
+	}
+
+	return rec
+}
+
+// exifNotesFrame adds the frame and its lens. Frames without a count are
+// numbered by their position on the roll.
+func exifNotesFrame(frame map[string]any, rollRef string, position int, gear *gearSet) {
+	rec := dtos.FrameRecord{Roll: rollRef, Number: position}
+
+	for key, value := range frame {
+		switch key {
+		case "count":
+			if v, ok := value.(float64); ok {
+				rec.Number = int(v)
+			}
+		case "note":
+			rec.Notes = optional(stringValue(value))
+		case "lens":
+			if lens, ok := value.(map[string]any); ok {
+				exifNotesLens(lens, gear)
+			}
+		case "id":
+		default:
+			gear.result.unmapped("frame." + key)
+		}
+	}
+
+	gear.addFrame(rec)
+}
+
+func exifNotesLens(lens map[string]any, gear *gearSet) {
+	lensMake := stringValue(lens["make"])
+	model := stringValue(lens["model"])
+	name := strings.TrimSpace(lensMake + " " + model)
+
+	rec := lensFromName(name)
+	if lensMake != "" {
+		rec.Manufacturer = lensMake
+	}
+	if v := intValue(lens["minFocalLength"]); v > 0 {
+		rec.FocalLengthMin = v
+	}
+	if v := intValue(lens["maxFocalLength"]); v > 0 {
+		rec.FocalLengthMax = v
+	}
+	if v := stringValue(lens["maxAperture"]); v != "" {
+		rec.MaxApertureStr = apertureString(v)
+	}
+	if v := stringValue(lens["minAperture"]); v != "" {
+		rec.MinApertureStr = apertureString(v)
+	}
+
+	reportUnmapped(gear.result, "lens.", lens, "id", "make", "model", "minFocalLength", "maxFocalLength", "minAperture", "maxAperture")
+
+	gear.addLens(name, rec)
+}
+
+func stringValue(v any) string {
+	switch t := v.(type) {
+	case string:
+		return strings.TrimSpace(t)
+	case float64:
+		return strings.TrimSuffix(strings.TrimRight(fmt.Sprintf("%f", t), "0"), ".")
+	default:
+		return ""
+	}
+}
+
+func intValue(v any) int {
+	if f, ok := v.(float64); ok {
+		return int(f)
+	}
+	return 0
+}
@@ -0,0 +1,189 @@
+package importers
+
+import (
+	"bytes"
+	"encoding/json"
+	"slices"
+	"strings"
+
+	"github.com/georgiev098/film-manager/backend/internal/dtos"
+	"github.com/georgiev098/film-manager/backend/internal/models"
+)
+
+// FilmLogbookParser reads Film Logbook exports. The JSON backup lists cameras,
+// lenses and rolls separately, each roll with its film and frames; the CSV
+// export is one row per frame.
+type FilmLogbookParser struct{}
+
+func (FilmLogbookParser) Name() string { return "film-logbook" }
+
+func (FilmLogbookParser) Parse(data []byte) (*Result, error) {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) > 0 && trimmed[0] == '{' {
+		return parseFilmLogbookJSON(trimmed)
+	}
+
+	return parseFrameCSV(data, frameCSVColumns{
+		Roll:         "Roll",
+		Film:         "Film",
+		ISO:          "ISO",
+		Process:      "Process",
+		Frame:        "Frame",
+		Camera:       "Camera",
+		CameraSerial: "Camera Serial",
+		Format:       "Format",
+		Lens:         "Lens",
+		Notes:        "Notes",
+	})
+}
+
+func parseFilmLogbookJSON(data []byte) (*Result, error) {
+	var backup map[string]any
+	if err := json.Unmarshal(data, &backup); err != nil {
+		return nil, err
+	}
+
+	result := newResult()
+	gear := newGearSet(result)
+
+	// Rolls refer to cameras by id, so they are read once the cameras are known
+	cameraRefs := map[string]string{}
+	var rolls []any
+
+	for key, value := range backup {
+		list, _ := value.([]any)
+
+		switch key {
+		case "cameras":
+			for _, c := range list {
+				cam, ok := c.(map[string]any)
+				if !ok {
+					continue
+				}
+
+				format := cameraFormat(stringValue(cam["format"]))
+				if format == "" {
+					format = models.Format35mm
+				}
+
+				ref := gear.addCamera(dtos.CameraRecord{
+					Brand:        stringValue(cam["brand"]),
+					CameraModel:  stringValue(cam["model"]),
+					CameraFormat: format,
+					SerialNumber: optional(stringValue(cam["serial"])),
+					Notes:        optional(stringValue(cam["notes"])),
+				})
+				if id := stringValue(cam["id"]); id != "" {
+					cameraRefs[id] = ref
+				}
+
+				reportUnmapped(result, "camera.", cam, "id", "brand", "model", "format", "serial", "notes")
+			}
+		case "lenses":
+			for _, l := range list {
+				lens, ok := l.(map[string]any)
+				if !ok {
+					continue
+				}
+
+				name := strings.TrimSpace(stringValue(lens["brand"]) + " " + stringValue(lens["model"]))
+
+				rec := lensFromName(name)
+				if brand := stringValue(lens["brand"]); brand != "" {
+					rec.Manufacturer = brand
+				}
+				if v := intValue(lens["focal_length_min"]); v > 0 {
+					rec.FocalLengthMin = v
+				}
+				if v := intValue(lens["focal_length_max"]); v > 0 {
+					rec.FocalLengthMax = v
+				}
+				if v := stringValue(lens["max_aperture"]); v != "" {
+					rec.MaxApertureStr = apertureString(v)
+				}
+				if v := stringValue(lens["min_aperture"]); v != "" {
+					rec.MinApertureStr = apertureString(v)
+				}
+				rec.Mount = stringValue(lens["mount"])
+				rec.Notes = optional(stringValue(lens["notes"]))
+
+				gear.addLens(name, rec)
+
+				reportUnmapped(result, "lens.", lens, "id", "brand", "model", "focal_length_min", "focal_length_max", "max_aperture", "min_aperture", "mount", "notes")
+			}
+		case "rolls":
+			rolls = list
+		default:
+			result.unmapped(key)
+		}
+	}
+
+	for _, r := range rolls {
+		if roll, ok := r.(map[string]any); ok {
+			filmLogbookRoll(roll, cameraRefs, gear)
+		}
+	}
+
+	return result, nil
+}
+
+func filmLogbookRoll(roll map[string]any, cameraRefs map[string]string, gear *gearSet) {
+	cameraRef := cameraRefs[stringValue(roll["camera_id"])]
+
+	format := cameraFormat(stringValue(roll["format"]))
+	if format == "" {
+		format = gear.cameraFormat(cameraRef)
+	}
+
+	stock := dtos.FilmStockRecord{
+		Manufacturer: stringValue(roll["film_brand"]),
+		Name:         stringValue(roll["film_name"]),
+		ISO:          intValue(roll["iso"]),
+		Format:       format,
+		Process:      filmProcess(stringValue(roll["process"])),
+	}
+
+	rec := dtos.RollRecord{
+		FilmStock:   gear.addFilmStock(stock),
+		Camera:      optionalRef(cameraRef),
+		LoadedAt:    timeValue(stringValue(roll["loaded"])),
+		ExposedAt:   timeValue(stringValue(roll["finished"])),
+		DevelopedAt: timeValue(stringValue(roll["developed"])),
+		Notes:       optional(stringValue(roll["notes"])),
+	}
+	rec.Status = rollStatus(rec.LoadedAt, rec.ExposedAt, rec.DevelopedAt)
+	rollRef := gear.addRoll(rec)
+
+	reportUnmapped(gear.result, "roll.", roll, "id", "camera_id", "format", "film_brand", "film_name", "iso", "process", "loaded", "finished", "developed", "notes", "frames")
+
+	frames, _ := roll["frames"].([]any)
+	for n, f := range frames {
+		frame, ok := f.(map[string]any)
+		if !ok {
+			continue
+		}
+
+		number := n + 1
+		if v, ok := frame["number"].(float64); ok {
+			number = int(v)
+		}
+
+		gear.addFrame(dtos.FrameRecord{
+			Roll:   rollRef,
+			Number: number,
+			Title:  optional(stringValue(frame["title"])),
+			Notes:  optional(stringValue(frame["notes"])),
+		})
+
+		reportUnmapped(gear.result, "frame.", frame, "id", "number", "title", "notes")
+	}
+}
+
+// reportUnmapped records every key of obj that is not in known
+func reportUnmapped(result *Result, prefix string, obj map[string]any, known ...string) {
+	for key := range obj {
+		if !slices.Contains(known, key) {
+			result.unmapped(prefix + key)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package importers
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/georgiev098/film-manager/backend/internal/dtos"
+	"github.com/georgiev098/film-manager/backend/internal/models"
+)
+
+// frameCSVColumns names the columns of a one-row-per-frame CSV log.
+// Header matching is case-insensitive; empty names mean the app has no such column.
+type frameCSVColumns struct {
+	Roll         string
+	Film         string // "Kodak Portra 400"
+	ISO          string
+	Process      string
+	Frame        string // frame number, rows are numbered in order without it
+	Camera       string
+	CameraSerial string
+	Format       string
+	Lens         string
+	Notes        string
+}
+
+// parseFrameCSV handles the common "one row per exposure" log layout
+func parseFrameCSV(data []byte, cols frameCSVColumns) (*Result, error) {
+	cr := csv.NewReader(bytes.NewReader(data))
+	cr.FieldsPerRecord = -1
+
+	header, err := cr.Read()
+	if err != nil {
+		return nil, err
+	}
+
+	index := map[string]int{}
+	for i, name := range header {
+		index[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+
+	if _, ok := index[strings.ToLower(cols.Camera)]; !ok {
+		return nil, errors.New("csv header has no " + cols.Camera + " column")
+	}
+
+	mapped := map[string]bool{}
+	for _, c := range []string{cols.Roll, cols.Film, cols.ISO, cols.Process, cols.Frame, cols.Camera, cols.CameraSerial, cols.Format, cols.Lens, cols.Notes} {
+		if c != "" {
+			mapped[strings.ToLower(c)] = true
+		}
+	}
+
+	result := newResult()
+	gear := newGearSet(result)
+
+	// Ref and frame count of each roll, by its name in the log
+	type csvRoll struct {
+		ref    string
+		frames int
+	}
+	rolls := map[string]*csvRoll{}
+
+	for {
+		row, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		get := func(col string) string {
+			i, ok := index[strings.ToLower(col)]
+			if col == "" || !ok || i >= len(row) {
+				return ""
+			}
+			return strings.TrimSpace(row[i])
+		}
+
+		brand, model := splitCameraName(get(cols.Camera))
+		format := cameraFormat(get(cols.Format))
+		if format == "" {
+			format = models.Format35mm
+		}
+		cameraRef := gear.addCamera(dtos.CameraRecord{
+			Brand:        brand,
+			CameraModel:  model,
+			CameraFormat: format,
+			SerialNumber: optional(get(cols.CameraSerial)),
+		})
+
+		lensName := get(cols.Lens)
+		gear.addLens(lensName, lensFromName(lensName))
+
+		// Rows without a roll can't be told apart by roll, so only their gear is kept
+		if name := get(cols.Roll); name != "" {
+			roll, ok := rolls[name]
+			if !ok {
+				stock := filmStockFromName(get(cols.Film), format)
+				if iso, err := strconv.Atoi(get(cols.ISO)); err == nil {
+					stock.ISO = iso
+				}
+				stock.Process = filmProcess(get(cols.Process))
+
+				roll = &csvRoll{ref: gear.addRoll(dtos.RollRecord{
+					FilmStock: gear.addFilmStock(stock),
+					Camera:    optionalRef(cameraRef),
+					Status:    rollStatus(nil, nil, nil),
+				})}
+				rolls[name] = roll
+			}
+
+			roll.frames++
+			number, err := strconv.Atoi(get(cols.Frame))
+			if err != nil {
+				number = roll.frames
+			}
+
+			gear.addFrame(dtos.FrameRecord{Roll: roll.ref, Number: number, Notes: optional(get(cols.Notes))})
+		}
+
+		for i, name := range header {
+			key := strings.ToLower(strings.TrimSpace(name))
+			if !mapped[key] && i < len(row) && strings.TrimSpace(row[i]) != "" {
+				result.unmapped(strings.TrimSpace(name))
+			}
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,238 @@
+package importers
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/georgiev098/film-manager/backend/internal/dtos"
+	"github.com/georgiev098/film-manager/backend/internal/models"
+)
+
+var (
+	focalRe    = regexp.MustCompile(`(\d+)(?:\s*-\s*(\d+))?\s*mm`)
+	apertureRe = regexp.MustCompile(`(?i)f\s*/?\s*(\d+(?:\.\d+)?)`)
+	isoRe      = regexp.MustCompile(`\b(\d{2,5})\b`)
+)
+
+// Layouts of the dates other apps write, tried in order
+var timeLayouts = []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02 15:04:05", "2006-01-02 15:04", time.DateOnly}
+
+// gearSet deduplicates cameras, lenses and film stocks referenced many times
+// across rolls and frames, and hands out the refs rolls use for them
+type gearSet struct {
+	result  *Result
+	cameras map[string]string
+	lenses  map[string]bool
+	stocks  map[string]string
+}
+
+func newGearSet(result *Result) *gearSet {
+	return &gearSet{result: result, cameras: map[string]string{}, lenses: map[string]bool{}, stocks: map[string]string{}}
+}
+
+// addCamera returns the ref of the camera, or "" when the record names none
+func (g *gearSet) addCamera(rec dtos.CameraRecord) string {
+	if rec.Brand == "" && rec.CameraModel == "" {
+		return ""
+	}
+
+	key := strings.ToLower(rec.Brand + "|" + rec.CameraModel)
+	if rec.SerialNumber != nil {
+		key += "|" + strings.ToLower(*rec.SerialNumber)
+	}
+	if ref, ok := g.cameras[key]; ok {
+		return ref
+	}
+
+	rec.Ref = fmt.Sprintf("camera-%d", len(g.result.Cameras)+1)
+	g.cameras[key] = rec.Ref
+	g.result.Cameras = append(g.result.Cameras, rec)
+	return rec.Ref
+}
+
+// cameraFormat returns the format of the camera with ref, 35mm when unknown
+func (g *gearSet) cameraFormat(ref string) models.CameraFormat {
+	for _, cam := range g.result.Cameras {
+		if cam.Ref == ref && cam.CameraFormat != "" {
+			return cam.CameraFormat
+		}
+	}
+	return models.Format35mm
+}
+
+// addFilmStock returns the ref of the film stock, or "" when the record names none
+func (g *gearSet) addFilmStock(rec dtos.FilmStockRecord) string {
+	if rec.Manufacturer == "" && rec.Name == "" {
+		return ""
+	}
+
+	key := strings.ToLower(rec.Manufacturer + "|" + rec.Name + "|" + string(rec.Format))
+	if ref, ok := g.stocks[key]; ok {
+		return ref
+	}
+
+	rec.Ref = fmt.Sprintf("film-%d", len(g.result.FilmStocks)+1)
+	g.stocks[key] = rec.Ref
+	g.result.FilmStocks = append(g.result.FilmStocks, rec)
+	return rec.Ref
+}
+
+// addRoll returns the ref its frames are added with
+func (g *gearSet) addRoll(rec dtos.RollRecord) string {
+	rec.Ref = fmt.Sprintf("roll-%d", len(g.result.Rolls)+1)
+	g.result.Rolls = append(g.result.Rolls, rec)
+	return rec.Ref
+}
+
+func (g *gearSet) addFrame(rec dtos.FrameRecord) {
+	g.result.Frames = append(g.result.Frames, rec)
+}
+
+func (g *gearSet) addLens(sourceName string, rec dtos.LensRecord) {
+	sourceName = strings.TrimSpace(sourceName)
+	if sourceName == "" {
+		return
+	}
+
+	key := strings.ToLower(sourceName)
+	if g.lenses[key] {
+		return
+	}
+
+	g.lenses[key] = true
+	g.result.Lenses = append(g.result.Lenses, LensCandidate{SourceName: sourceName, Record: rec})
+}
+
+// splitCameraName splits "Nikon FM2" into brand and model
+func splitCameraName(name string) (brand, model string) {
+	name = strings.TrimSpace(name)
+	brand, model, _ = strings.Cut(name, " ")
+	return brand, strings.TrimSpace(model)
+}
+
+// lensFromName extracts what it can from names like "Canon FD 50mm f/1.8".
+// Fields the name does not carry (mount, minimum aperture) are left empty so
+// the record fails validation and the user is asked to map it instead.
+func lensFromName(name string) dtos.LensRecord {
+	manufacturer, _ := splitCameraName(name)
+
+	rec := dtos.LensRecord{
+		Manufacturer: manufacturer,
+		LensType:     models.LensAnalog,
+	}
+
+	if m := focalRe.FindStringSubmatch(name); m != nil {
+		rec.FocalLengthMin, _ = strconv.Atoi(m[1])
+		rec.FocalLengthMax = rec.FocalLengthMin
+		if m[2] != "" {
+			rec.FocalLengthMax, _ = strconv.Atoi(m[2])
+		}
+	}
+
+	if m := apertureRe.FindStringSubmatch(name); m != nil {
+		rec.MaxApertureStr = "f/" + m[1]
+	}
+
+	return rec
+}
+
+// filmStockFromName extracts what it can from names like "Kodak Portra 400".
+// The process is not part of the name; a stock without one fails validation
+// and its rolls are skipped, which the import report shows.
+func filmStockFromName(name string, format models.CameraFormat) dtos.FilmStockRecord {
+	manufacturer, model := splitCameraName(name)
+
+	rec := dtos.FilmStockRecord{
+		Manufacturer: manufacturer,
+		Name:         model,
+		Format:       format,
+	}
+
+	if m := isoRe.FindAllStringSubmatch(model, -1); m != nil {
+		rec.ISO, _ = strconv.Atoi(m[len(m)-1][1])
+	}
+
+	return rec
+}
+
+// filmProcess maps process names used by other apps to ours
+func filmProcess(v string) models.FilmProcess {
+	switch strings.ToLower(strings.ReplaceAll(strings.TrimSpace(v), " ", "")) {
+	case "c-41", "c41":
+		return models.ProcessC41
+	case "e-6", "e6":
+		return models.ProcessE6
+	case "bw", "b&w", "b/w", "blackandwhite":
+		return models.ProcessBW
+	default:
+		return models.FilmProcess(v)
+	}
+}
+
+// rollStatus is the furthest stage a roll has a date for. Without any it is
+// taken to be shot already, as other apps log rolls once they are exposed.
+func rollStatus(loaded, exposed, developed *time.Time) models.RollStatus {
+	switch {
+	case developed != nil:
+		return models.RollDeveloped
+	case exposed != nil:
+		return models.RollExposed
+	case loaded != nil:
+		return models.RollLoaded
+	default:
+		return models.RollExposed
+	}
+}
+
+// timeValue parses a date in one of timeLayouts, nil when it is empty or unknown
+func timeValue(v string) *time.Time {
+	v = strings.TrimSpace(v)
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, v); err == nil {
+			return &t
+		}
+	}
+	return nil
+}
+
+func optionalRef(ref string) *string {
+	if ref == "" {
+		return nil
+	}
+	return &ref
+}
+
+// apertureString normalises "1.8", "f1.8" or "f/1.8" to "f/1.8"
+func apertureString(v string) string {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return ""
+	}
+	if m := apertureRe.FindStringSubmatch(v); m != nil {
+		return "f/" + m[1]
+	}
+	return "f/" + v
+}
+
+// cameraFormat maps film format names used by other apps to ours
+func cameraFormat(v string) models.CameraFormat {
+	switch strings.ToLower(strings.TrimSpace(v)) {
+	case "135", "35mm", "35":
+		return models.Format35mm
+	case "120", "220", "120mm", "medium format":
+		return models.Format120mm
+	default:
+		return models.CameraFormat(v)
+	}
+}
+
+func optional(s string) *string {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil
+	}
+	return &s
+}
@@ -0,0 +1,77 @@
+package importers
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/georgiev098/film-manager/backend/internal/dtos"
+)
+
+var ErrUnknownSource = errors.New("unknown import source")
+
+// Parser turns another app's export file into our gear records
+type Parser interface {
+	// Name is the identifier used in POST /import/{source}
+	Name() string
+	Parse(data []byte) (*Result, error)
+}
+
+// LensCandidate is a lens found in the source file. SourceName is how the
+// source app refers to it and is the key users map to existing lenses with.
+type LensCandidate struct {
+	SourceName string
+	Record     dtos.LensRecord
+}
+
+// Result is what a parser could make of a file. Rolls refer to their film
+// stock and camera, frames to their roll, by Ref. Anything that has no home
+// in our models (exposure data, locations...) is counted in Unmapped.
+type Result struct {
+	Cameras    []dtos.CameraRecord
+	Lenses     []LensCandidate
+	FilmStocks []dtos.FilmStockRecord
+	Rolls      []dtos.RollRecord
+	Frames     []dtos.FrameRecord
+
+	// Unmapped counts occurrences of source fields we could not map, keyed by field name
+	Unmapped map[string]int
+}
+
+func newResult() *Result {
+	return &Result{Unmapped: map[string]int{}}
+}
+
+func (r *Result) unmapped(field string) {
+	r.Unmapped[field]++
+}
+
+var registry = map[string]Parser{}
+
+func register(p Parser) {
+	registry[p.Name()] = p
+}
+
+func init() {
+	register(ExifNotesParser{})
+	register(LightmeParser{})
+	register(FilmLogbookParser{})
+}
+
+// Get returns the parser registered under name
+func Get(name string) (Parser, error) {
+	p, ok := registry[name]
+	if !ok {
+		return nil, ErrUnknownSource
+	}
+	return p, nil
+}
+
+// Names lists the registered sources, sorted
+func Names() []string {
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,16 @@
+package importers
+
+// LightmeParser reads the CSV logbook export of the Lightme light meter app,
+// one row per metered exposure. The film names the roll as well as its stock.
+type LightmeParser struct{}
+
+func (LightmeParser) Name() string { return "lightme" }
+
+func (LightmeParser) Parse(data []byte) (*Result, error) {
+	return parseFrameCSV(data, frameCSVColumns{
+		Roll:   "Film",
+		Film:   "Film",
+		Camera: "Camera",
+		Lens:   "Lens",
+	})
+}
@@ -0,0 +1,103 @@
+package jwtkeys
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+)
+
+// JWK is a public key in JSON Web Key format (RFC 7517)
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+
+	// RSA
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+
+	// OKP (Ed25519)
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS returns all verification keys for GET /.well-known/jwks.json
+func (ks *KeySet) JWKS() JWKS {
+	set := JWKS{Keys: make([]JWK, 0, len(ks.order))}
+
+	for _, key := range ks.Keys() {
+		jwk := publicJWK(key.Public)
+		jwk.Kid = key.ID
+		jwk.Use = "sig"
+		jwk.Alg = key.Method.Alg()
+
+		set.Keys = append(set.Keys, jwk)
+	}
+
+	return set
+}
+
+func publicJWK(pub crypto.PublicKey) JWK {
+	switch k := pub.(type) {
+	case *rsa.PublicKey:
+		return JWK{
+			Kty: "RSA",
+			N:   b64(k.N.Bytes()),
+			E:   b64(big.NewInt(int64(k.E)).Bytes()),
+		}
+	case ed25519.PublicKey:
+		return JWK{
+			Kty: "OKP",
+			Crv: "Ed25519",
+			X:   b64(k),
+		}
+	}
+
+	return JWK{}
+}
+
+// thumbprint computes the RFC 7638 JWK thumbprint, used as key ID
+func thumbprint(pub crypto.PublicKey) (string, error) {
+	jwk := publicJWK(pub)
+
+	// Only the required members, in lexicographic order
+	var members any
+	switch jwk.Kty {
+	case "RSA":
+		members = struct {
+			E   string `json:"e"`
+			Kty string `json:"kty"`
+			N   string `json:"n"`
+		}{jwk.E, jwk.Kty, jwk.N}
+	case "OKP":
+		members = struct {
+			Crv string `json:"crv"`
+			Kty string `json:"kty"`
+			X   string `json:"x"`
+		}{jwk.Crv, jwk.Kty, jwk.X}
+	default:
+		return "", fmt.Errorf("unsupported key type %T", pub)
+	}
+
+	data, err := json.Marshal(members)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(data)
+	return b64(sum[:]), nil
+}
+
+func b64(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
@@ -0,0 +1,219 @@
+// Package jwtkeys holds the asymmetric keys used to sign and verify access tokens.
+//
+// One key signs new tokens. Older keys can be kept for verification only, so a
+// key can be rotated without logging anybody out: add the new key as signing key,
+// keep the old one as verification key until the last tokens it signed expire.
+package jwtkeys
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const minRSABits = 2048
+
+var ErrUnknownKey = errors.New("unknown signing key")
+
+// Key is a public key that tokens can be verified with
+type Key struct {
+	ID     string // "kid", the RFC 7638 thumbprint of the key
+	Method jwt.SigningMethod
+	Public crypto.PublicKey
+}
+
+type KeySet struct {
+	signer  crypto.Signer
+	signing Key
+	keys    map[string]Key
+	order   []string
+}
+
+// New builds a key set that signs with signer and also accepts tokens signed
+// by the private keys belonging to previous
+func New(signer crypto.Signer, previous ...crypto.PublicKey) (*KeySet, error) {
+	signing, err := newKey(signer.Public())
+	if err != nil {
+		return nil, err
+	}
+
+	ks := &KeySet{
+		signer:  signer,
+		signing: signing,
+		keys:    map[string]Key{},
+	}
+	ks.add(signing)
+
+	for _, pub := range previous {
+		key, err := newKey(pub)
+		if err != nil {
+			return nil, err
+		}
+		ks.add(key)
+	}
+
+	return ks, nil
+}
+
+// Load reads a PEM private key to sign with and PEM keys (public or private)
+// that are only used for verification
+func Load(signingKeyFile string, verificationKeyFiles []string) (*KeySet, error) {
+	priv, err := readKey(signingKeyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	signer, ok := priv.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("%s: not a private key", signingKeyFile)
+	}
+
+	previous := make([]crypto.PublicKey, 0, len(verificationKeyFiles))
+	for _, file := range verificationKeyFiles {
+		key, err := readKey(file)
+		if err != nil {
+			return nil, err
+		}
+
+		if s, ok := key.(crypto.Signer); ok {
+			key = s.Public()
+		}
+		previous = append(previous, key)
+	}
+
+	return New(signer, previous...)
+}
+
+// Generate creates a key set with a fresh Ed25519 key. Tokens signed with it
+// stop working when the process exits, so it is only meant for development.
+func Generate() (*KeySet, error) {
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	return New(priv)
+}
+
+// Sign signs claims with the current signing key and sets the kid header
+func (ks *KeySet) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(ks.signing.Method, claims)
+	token.Header["kid"] = ks.signing.ID
+
+	return token.SignedString(ks.signer)
+}
+
+// Keyfunc selects the verification key by kid for jwt.Parse. The token's
+// algorithm must be the one that belongs to that key.
+func (ks *KeySet) Keyfunc(token *jwt.Token) (any, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	key, ok := ks.keys[kid]
+	if !ok {
+		return nil, ErrUnknownKey
+	}
+
+	if token.Method.Alg() != key.Method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method %s for key %s", token.Method.Alg(), kid)
+	}
+
+	return key.Public, nil
+}
+
+// Algorithms lists the algorithms of all verification keys, for jwt.WithValidMethods
+func (ks *KeySet) Algorithms() []string {
+	algs := make([]string, 0, len(ks.order))
+	for _, kid := range ks.order {
+		alg := ks.keys[kid].Method.Alg()
+		if !slices.Contains(algs, alg) {
+			algs = append(algs, alg)
+		}
+	}
+
+	return algs
+}
+
+// Keys returns all verification keys, the signing key first
+func (ks *KeySet) Keys() []Key {
+	keys := make([]Key, 0, len(ks.order))
+	for _, kid := range ks.order {
+		keys = append(keys, ks.keys[kid])
+	}
+
+	return keys
+}
+
+func (ks *KeySet) SigningKeyID() string {
+	return ks.signing.ID
+}
+
+func (ks *KeySet) add(key Key) {
+	if _, ok := ks.keys[key.ID]; ok {
+		return
+	}
+
+	ks.keys[key.ID] = key
+	ks.order = append(ks.order, key.ID)
+}
+
+func newKey(pub crypto.PublicKey) (Key, error) {
+	var method jwt.SigningMethod
+
+	switch k := pub.(type) {
+	case *rsa.PublicKey:
+		if k.N.BitLen() < minRSABits {
+			return Key{}, fmt.Errorf("RSA keys must have at least %d bits", minRSABits)
+		}
+		method = jwt.SigningMethodRS256
+	case ed25519.PublicKey:
+		method = jwt.SigningMethodEdDSA
+	default:
+		return Key{}, fmt.Errorf("unsupported key type %T, use RSA or Ed25519", pub)
+	}
+
+	kid, err := thumbprint(pub)
+	if err != nil {
+		return Key{}, err
+	}
+
+	return Key{ID: kid, Method: method, Public: pub}, nil
+}
+
+// readKey parses the first PEM block of a file as a PKCS#8/PKCS#1 private key or a PKIX public key
+func readKey(file string) (any, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%s: no PEM data", file)
+	}
+
+	var key any
+	switch block.Type {
+	case "PRIVATE KEY":
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		key, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("%s: unsupported PEM block %q", file, block.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+
+	return key, nil
+}
@@ -0,0 +1,44 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"log"
+)
+
+type Message struct {
+	To      string
+	Subject string
+	Body    string // plain text
+}
+
+// Mailer sends transactional email (password resets, verification links...)
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+type Config struct {
+	Driver string // smtp | file | log
+	From   string
+
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUsername string
+	SMTPPassword string
+
+	FileDir string
+}
+
+// New builds the mailer selected by cfg.Driver
+func New(cfg Config, logger *log.Logger) (Mailer, error) {
+	switch cfg.Driver {
+	case "smtp":
+		return NewSMTPMailer(cfg), nil
+	case "file":
+		return NewFileMailer(cfg.FileDir, cfg.From)
+	case "log", "":
+		return NewLogMailer(logger), nil
+	default:
+		return nil, fmt.Errorf("unknown mail driver %q", cfg.Driver)
+	}
+}
@@ -0,0 +1,60 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"net/mail"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+type SMTPMailer struct {
+	addr     string
+	from     string
+	envelope string // bare address for MAIL FROM
+	auth     smtp.Auth
+}
+
+func NewSMTPMailer(cfg Config) *SMTPMailer {
+	var auth smtp.Auth
+	if cfg.SMTPUsername != "" {
+		auth = smtp.PlainAuth("", cfg.SMTPUsername, cfg.SMTPPassword, cfg.SMTPHost)
+	}
+
+	envelope := cfg.From
+	if addr, err := mail.ParseAddress(cfg.From); err == nil {
+		envelope = addr.Address
+	}
+
+	return &SMTPMailer{
+		addr:     fmt.Sprintf("%s:%d", cfg.SMTPHost, cfg.SMTPPort),
+		from:     cfg.From,
+		envelope: envelope,
+		auth:     auth,
+	}
+}
+
+func (m *SMTPMailer) Send(ctx context.Context, msg Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return smtp.SendMail(m.addr, m.auth, m.envelope, []string{msg.To}, buildMessage(m.from, msg))
+}
+
+// buildMessage renders a minimal RFC 5322 plain text message
+func buildMessage(from string, msg Message) []byte {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "From: %s\r\n", from)
+	fmt.Fprintf(&b, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&b, "Subject: %s\r\n", msg.Subject)
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(msg.Body, "\n", "\r\n"))
+
+	return []byte(b.String())
+}
@@ -2,59 +2,130 @@ package middlewares
 
 import (
 	"context"
+	"errors"
 	"net/http"
+	"slices"
 	"strings"
+	"time"
 
+	"github.com/georgiev098/film-manager/backend/internal/audit"
 	"github.com/georgiev098/film-manager/backend/internal/core"
+	"github.com/georgiev098/film-manager/backend/internal/helpers"
+	"github.com/georgiev098/film-manager/backend/internal/models"
+	"github.com/georgiev098/film-manager/backend/internal/repositories"
+	"github.com/georgiev098/film-manager/backend/internal/services"
 	"github.com/golang-jwt/jwt/v5"
+	"gorm.io/gorm"
 )
 
 type contextKey string
 
-const userIDKey contextKey = "userID"
+const (
+	userIDKey        contextKey = "userID"
+	roleKey          contextKey = "role"
+	emailVerifiedKey contextKey = "emailVerified"
+	scopesKey        contextKey = "scopes"
+	authTimeKey      contextKey = "authTime"
+)
 
+// Auth accepts either a JWT access token or a personal access token as bearer token.
+// The user is loaded on every request, so disabling an account, changing its
+// role or verifying its email applies at once, not when the access token expires.
 func Auth(deps *core.AppDeps) func(http.Handler) http.Handler {
+	userRepo := repositories.NewUserRepo(deps.DB)
+	accessTokens := services.NewAccessTokenService(
+		repositories.NewPersonalAccessTokenRepo(deps.DB),
+		userRepo,
+	)
+
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 
 			authHeader := r.Header.Get("Authorization")
 			if authHeader == "" {
-				http.Error(w, "missing authorization header", http.StatusUnauthorized)
+				helpers.WriteProblem(w, r, http.StatusUnauthorized, "missing authorization header")
 				return
 			}
 
 			parts := strings.Split(authHeader, " ")
 			if len(parts) != 2 || parts[0] != "Bearer" {
-				http.Error(w, "invalid authorization header", http.StatusUnauthorized)
+				helpers.WriteProblem(w, r, http.StatusUnauthorized, "invalid authorization header")
 				return
 			}
 
 			tokenString := parts[1]
 
-			token, err := jwt.Parse(tokenString, func(token *jwt.Token) (any, error) {
-				return []byte(deps.Config.Auth.AccessSecret), nil
-			})
+			if strings.HasPrefix(tokenString, services.AccessTokenPrefix) {
+				user, scopes, err := accessTokens.Authenticate(r.Context(), tokenString)
+				if err != nil {
+					if !errors.Is(err, services.ErrInvalidAccessToken) && !errors.Is(err, services.ErrUserDisabled) {
+						deps.Logger.Println("access token error:", err)
+					}
+					helpers.WriteProblem(w, r, http.StatusUnauthorized, "invalid token")
+					return
+				}
+
+				role := user.Role
+				if role == "" {
+					role = models.RoleUser
+				}
+
+				ctx := context.WithValue(r.Context(), userIDKey, user.ID)
+				ctx = context.WithValue(ctx, roleKey, role)
+				ctx = context.WithValue(ctx, emailVerifiedKey, user.EmailVerifiedAt != nil)
+				ctx = context.WithValue(ctx, scopesKey, scopes)
+				ctx = audit.WithActor(ctx, user.ID)
+
+				next.ServeHTTP(w, r.WithContext(ctx))
+				return
+			}
 
-			if err != nil || !token.Valid {
-				http.Error(w, "invalid token", http.StatusUnauthorized)
+			var claims services.AccessClaims
+
+			// Only the algorithms of our own keys, and iss, aud and exp must be present
+			_, err := jwt.ParseWithClaims(tokenString, &claims, deps.Keys.Keyfunc,
+				jwt.WithValidMethods(deps.Keys.Algorithms()),
+				jwt.WithIssuer(deps.Config.Auth.Issuer),
+				jwt.WithAudience(deps.Config.Auth.Audience),
+				jwt.WithExpirationRequired(),
+			)
+			if err != nil {
+				helpers.WriteProblem(w, r, http.StatusUnauthorized, "invalid token")
 				return
 			}
 
-			claims, ok := token.Claims.(jwt.MapClaims)
-			if !ok {
-				http.Error(w, "invalid token claims", http.StatusUnauthorized)
+			if claims.UserID == 0 {
+				helpers.WriteProblem(w, r, http.StatusUnauthorized, "invalid user id")
 				return
 			}
 
-			userIDFloat, ok := claims["user_id"].(float64)
-			if !ok {
-				http.Error(w, "invalid user id", http.StatusUnauthorized)
+			user, err := userRepo.GetUserByID(r.Context(), claims.UserID)
+			if err != nil {
+				if !errors.Is(err, gorm.ErrRecordNotFound) {
+					helpers.WriteError(w, r, deps.Logger, err)
+					return
+				}
+				helpers.WriteProblem(w, r, http.StatusUnauthorized, "invalid token")
 				return
 			}
 
-			userID := uint(userIDFloat)
+			if user.Disabled {
+				helpers.WriteProblem(w, r, http.StatusUnauthorized, "account disabled")
+				return
+			}
+
+			role := user.Role
+			if role == "" {
+				role = models.RoleUser
+			}
 
-			ctx := context.WithValue(r.Context(), userIDKey, userID)
+			ctx := context.WithValue(r.Context(), userIDKey, user.ID)
+			ctx = context.WithValue(ctx, roleKey, role)
+			ctx = context.WithValue(ctx, emailVerifiedKey, user.EmailVerifiedAt != nil)
+			if claims.AuthTime != nil {
+				ctx = context.WithValue(ctx, authTimeKey, claims.AuthTime.Time)
+			}
+			ctx = audit.WithActor(ctx, user.ID)
 
 			next.ServeHTTP(w, r.WithContext(ctx))
 		})
@@ -65,3 +136,88 @@ func GetUserIDFromContext(ctx context.Context) (uint, bool) {
 	userID, ok := ctx.Value(userIDKey).(uint)
 	return userID, ok
 }
+
+func GetRoleFromContext(ctx context.Context) (models.Role, bool) {
+	role, ok := ctx.Value(roleKey).(models.Role)
+	return role, ok
+}
+
+func GetEmailVerifiedFromContext(ctx context.Context) bool {
+	verified, _ := ctx.Value(emailVerifiedKey).(bool)
+	return verified
+}
+
+// GetAuthTimeFromContext returns when the JWT session was started by logging
+// in. It is zero for personal access tokens.
+func GetAuthTimeFromContext(ctx context.Context) time.Time {
+	authTime, _ := ctx.Value(authTimeKey).(time.Time)
+	return authTime
+}
+
+// GetScopesFromContext returns the scopes of a personal access token.
+// ok is false for JWT sessions, which are not limited by scopes.
+func GetScopesFromContext(ctx context.Context) ([]string, bool) {
+	scopes, ok := ctx.Value(scopesKey).([]string)
+	return scopes, ok
+}
+
+// RequireScope limits personal access tokens to "<resource>:read" for GET and
+// HEAD requests and "<resource>:write" for everything else. JWT sessions pass.
+// It must run after Auth.
+func RequireScope(resource string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			scopes, ok := GetScopesFromContext(r.Context())
+			if !ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			needed := resource + ":write"
+			if r.Method == http.MethodGet || r.Method == http.MethodHead {
+				needed = resource + ":read"
+			}
+
+			if !slices.Contains(scopes, needed) {
+				helpers.WriteProblem(w, r, http.StatusForbidden, "token lacks scope "+needed)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// SessionOnly rejects personal access tokens, for account management and
+// admin routes that scripts must not reach. It must run after Auth.
+func SessionOnly(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, isAccessToken := GetScopesFromContext(r.Context()); isAccessToken {
+			helpers.WriteProblem(w, r, http.StatusForbidden, "not available with personal access tokens")
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// RequireRole only lets through users with one of the given roles.
+// It must run after Auth.
+func RequireRole(roles ...models.Role) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			role, ok := GetRoleFromContext(r.Context())
+			if !ok {
+				helpers.WriteProblem(w, r, http.StatusUnauthorized, "unauthorized")
+				return
+			}
+
+			if !slices.Contains(roles, role) {
+				helpers.WriteProblem(w, r, http.StatusForbidden, "forbidden")
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
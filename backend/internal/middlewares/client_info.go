@@ -0,0 +1,21 @@
+package middlewares
+
+import (
+	"net/http"
+
+	"github.com/georgiev098/film-manager/backend/internal/audit"
+	"github.com/georgiev098/film-manager/backend/internal/helpers"
+)
+
+// ClientInfo puts the caller's IP and user agent on the request context,
+// so services can attach them to audit events
+func ClientInfo(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := audit.WithClient(r.Context(), audit.Client{
+			UserAgent: helpers.Truncate(r.UserAgent(), 255),
+			IPAddress: helpers.Truncate(helpers.ClientIP(r), 45),
+		})
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
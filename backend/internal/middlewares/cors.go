@@ -9,12 +9,14 @@ import (
 
 // CORS returns a Chi middleware configured using AppDeps.Config
 func CORS(deps *core.AppDeps) func(next http.Handler) http.Handler {
+	cfg := deps.Config.CORS
+
 	return cors.Handler(cors.Options{
-		AllowedOrigins:   deps.Config.AllowedOrigins, // e.g. []string{"http://localhost:5173"}
-		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"},
-		ExposedHeaders:   []string{"Link"},
+		AllowedOrigins:   cfg.AllowedOrigins,
+		AllowedMethods:   cfg.AllowedMethods,
+		AllowedHeaders:   cfg.AllowedHeaders,
+		ExposedHeaders:   cfg.ExposedHeaders,
 		AllowCredentials: true,
-		MaxAge:           300, // 5 minutes
+		MaxAge:           cfg.MaxAge,
 	})
 }
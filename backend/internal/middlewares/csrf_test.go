@@ -0,0 +1,53 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/georgiev098/film-manager/backend/internal/core"
+)
+
+func TestCSRF(t *testing.T) {
+	tests := []struct {
+		name       string
+		hostPrefix bool
+		cookie     *http.Cookie
+		header     string
+		wantStatus int
+	}{
+		{"matching token", false, &http.Cookie{Name: CSRFCookie, Value: "abc"}, "abc", http.StatusOK},
+		{"no cookie", false, nil, "abc", http.StatusForbidden},
+		{"no header", false, &http.Cookie{Name: CSRFCookie, Value: "abc"}, "", http.StatusForbidden},
+		{"different token", false, &http.Cookie{Name: CSRFCookie, Value: "abc"}, "abd", http.StatusForbidden},
+		{"empty cookie and header", false, &http.Cookie{Name: CSRFCookie, Value: ""}, "", http.StatusForbidden},
+		{"prefixed cookie", true, &http.Cookie{Name: "__Host-" + CSRFCookie, Value: "abc"}, "abc", http.StatusOK},
+		{"unprefixed cookie with prefix on", true, &http.Cookie{Name: CSRFCookie, Value: "abc"}, "abc", http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deps := &core.AppDeps{}
+			deps.Config.Cookies.HostPrefix = tt.hostPrefix
+
+			handler := CSRF(deps)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			}))
+
+			req := httptest.NewRequest(http.MethodPost, "/auth/refresh", nil)
+			if tt.cookie != nil {
+				req.AddCookie(tt.cookie)
+			}
+			if tt.header != "" {
+				req.Header.Set(CSRFHeader, tt.header)
+			}
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+		})
+	}
+}
@@ -0,0 +1,21 @@
+package middlewares
+
+import (
+	"net/http"
+
+	"github.com/georgiev098/film-manager/backend/internal/i18n"
+)
+
+// Locale picks the response language from Accept-Language, see
+// i18n.FromContext. Every response names the language and varies on the
+// header, so caches don't serve one language to a client asking for another.
+func Locale(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t := i18n.Negotiate(r.Header.Get("Accept-Language"))
+
+		w.Header().Set("Content-Language", t.Locale())
+		w.Header().Add("Vary", "Accept-Language")
+
+		next.ServeHTTP(w, r.WithContext(i18n.WithTranslator(r.Context(), t)))
+	})
+}
@@ -4,6 +4,8 @@ import (
 	"log"
 	"net/http"
 	"time"
+
+	"github.com/georgiev098/film-manager/backend/internal/helpers"
 )
 
 func Logging(next http.Handler) http.Handler {
@@ -12,6 +14,6 @@ func Logging(next http.Handler) http.Handler {
 
 		next.ServeHTTP(w, r)
 
-		log.Printf("%s %s %s", r.Method, r.URL.Path, time.Since(start))
+		log.Printf("%s %s %s [%s]", r.Method, r.URL.Path, time.Since(start), helpers.RequestIDFromContext(r.Context()))
 	})
 }
@@ -3,18 +3,62 @@ package middlewares
 import (
 	"log"
 	"net/http"
+	"runtime/debug"
+
+	"github.com/georgiev098/film-manager/backend/internal/helpers"
 )
 
+// Recovery turns a panic into a 500 problem response. If the handler already
+// started its response, the status can't change, so the panic is only logged
+// and the connection is left to be closed. http.ErrAbortHandler is re-panicked
+// for net/http, which uses it to abort a response on purpose.
 func Recovery(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rw := &headerTracker{ResponseWriter: w}
+
 		defer func() {
 			err := recover()
-			if err != nil {
-				log.Println("panic:", err)
-				http.Error(w, "internal server error", http.StatusInternalServerError)
+			if err == nil {
+				return
+			}
+			if err == http.ErrAbortHandler {
+				panic(err)
+			}
+
+			log.Printf("panic [%s]: %v\n%s", helpers.RequestIDFromContext(r.Context()), err, debug.Stack())
+
+			if rw.wroteHeader {
+				// Abort the response, so the client doesn't take a truncated body as complete
+				panic(http.ErrAbortHandler)
 			}
+
+			helpers.WriteProblem(w, r, http.StatusInternalServerError, "internal server error")
 		}()
 
-		next.ServeHTTP(w, r)
+		next.ServeHTTP(rw, r)
 	})
 }
+
+// headerTracker records whether the response status was sent
+type headerTracker struct {
+	http.ResponseWriter
+	wroteHeader bool
+}
+
+func (t *headerTracker) WriteHeader(status int) {
+	// 1xx responses are sent ahead of the real one
+	if status >= 200 {
+		t.wroteHeader = true
+	}
+	t.ResponseWriter.WriteHeader(status)
+}
+
+func (t *headerTracker) Write(b []byte) (int, error) {
+	t.wroteHeader = true
+	return t.ResponseWriter.Write(b)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer's Flush and deadlines
+func (t *headerTracker) Unwrap() http.ResponseWriter {
+	return t.ResponseWriter
+}
@@ -0,0 +1,75 @@
+package middlewares
+
+import (
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRecovery(t *testing.T) {
+	out := log.Writer()
+	log.SetOutput(io.Discard)
+	t.Cleanup(func() { log.SetOutput(out) })
+
+	tests := []struct {
+		name       string
+		handler    http.HandlerFunc
+		wantStatus int
+		wantPanic  any
+	}{
+		{
+			name:       "panic before responding",
+			handler:    func(w http.ResponseWriter, r *http.Request) { panic("boom") },
+			wantStatus: http.StatusInternalServerError,
+		},
+		{
+			name: "panic after the header",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusAccepted)
+				panic("boom")
+			},
+			wantStatus: http.StatusAccepted,
+			wantPanic:  http.ErrAbortHandler,
+		},
+		{
+			name: "panic mid body",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte("partial"))
+				panic("boom")
+			},
+			wantStatus: http.StatusOK,
+			wantPanic:  http.ErrAbortHandler,
+		},
+		{
+			name:       "deliberate abort",
+			handler:    func(w http.ResponseWriter, r *http.Request) { panic(http.ErrAbortHandler) },
+			wantStatus: http.StatusOK,
+			wantPanic:  http.ErrAbortHandler,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+
+			func() {
+				defer func() {
+					if got := recover(); got != tt.wantPanic {
+						t.Errorf("panic = %v, want %v", got, tt.wantPanic)
+					}
+				}()
+				Recovery(tt.handler).ServeHTTP(rec, req)
+			}()
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if tt.wantStatus == http.StatusInternalServerError && rec.Header().Get("Content-Type") != "application/problem+json" {
+				t.Errorf("content type %q", rec.Header().Get("Content-Type"))
+			}
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package middlewares
+
+import (
+	"crypto/rand"
+	"net/http"
+
+	"github.com/georgiev098/film-manager/backend/internal/helpers"
+)
+
+const RequestIDHeader = "X-Request-ID"
+
+// RequestID tags every request with an ID, echoed in the X-Request-ID header
+// and in error responses. An ID set by a proxy in front of the API is kept.
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(RequestIDHeader)
+		if !validRequestID(id) {
+			id = rand.Text()
+		}
+
+		w.Header().Set(RequestIDHeader, id)
+
+		next.ServeHTTP(w, r.WithContext(helpers.WithRequestID(r.Context(), id)))
+	})
+}
+
+// validRequestID only accepts short IDs that are safe to put in logs
+func validRequestID(id string) bool {
+	if id == "" || len(id) > 128 {
+		return false
+	}
+
+	for _, c := range id {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '-', c == '_', c == '.', c == ':':
+		default:
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,69 @@
+package middlewares
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/georgiev098/film-manager/backend/internal/core"
+)
+
+// SecurityHeaders sets the configured security headers on every response.
+// Routes can replace them afterwards, see ContentSecurityPolicy.
+func SecurityHeaders(deps *core.AppDeps) func(next http.Handler) http.Handler {
+	cfg := deps.Config.SecurityHeaders
+
+	headers := http.Header{}
+	headers.Set("X-Content-Type-Options", "nosniff")
+
+	if cfg.HSTSMaxAge > 0 {
+		hsts := "max-age=" + strconv.Itoa(int(cfg.HSTSMaxAge.Seconds()))
+		if cfg.HSTSIncludeSubdomains {
+			hsts += "; includeSubDomains"
+		}
+		if cfg.HSTSPreload {
+			hsts += "; preload"
+		}
+		headers.Set("Strict-Transport-Security", hsts)
+	}
+	if cfg.ContentSecurityPolicy != "" {
+		headers.Set("Content-Security-Policy", cfg.ContentSecurityPolicy)
+	}
+	if cfg.ReferrerPolicy != "" {
+		headers.Set("Referrer-Policy", cfg.ReferrerPolicy)
+	}
+	if cfg.PermissionsPolicy != "" {
+		headers.Set("Permissions-Policy", cfg.PermissionsPolicy)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			for key, values := range headers {
+				w.Header()[key] = values
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// ContentSecurityPolicy replaces the global Content-Security-Policy for a route.
+// An empty policy removes the header.
+func ContentSecurityPolicy(policy string) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if policy == "" {
+				w.Header().Del("Content-Security-Policy")
+			} else {
+				w.Header().Set("Content-Security-Policy", policy)
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// PrintHeaders is the override for printable HTML pages, which need their
+// inline styles
+func PrintHeaders(deps *core.AppDeps) func(next http.Handler) http.Handler {
+	return ContentSecurityPolicy(deps.Config.SecurityHeaders.PrintCSP)
+}
@@ -0,0 +1,29 @@
+package middlewares
+
+import (
+	"net/http"
+
+	"github.com/georgiev098/film-manager/backend/internal/core"
+	"github.com/georgiev098/film-manager/backend/internal/helpers"
+)
+
+// UnverifiedReadOnly rejects writes from users who haven't confirmed their
+// email when the "read_only" policy is configured. It must run after Auth.
+// The "limited" policy is enforced by services.ItemQuota where items are created.
+func UnverifiedReadOnly(deps *core.AppDeps) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if deps.Config.Auth.UnverifiedPolicy != core.UnverifiedPolicyReadOnly || GetEmailVerifiedFromContext(r.Context()) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			switch r.Method {
+			case http.MethodGet, http.MethodHead, http.MethodOptions:
+				next.ServeHTTP(w, r)
+			default:
+				helpers.WriteProblem(w, r, http.StatusForbidden, "verify your email address to make changes")
+			}
+		})
+	}
+}
@@ -0,0 +1,28 @@
+package models
+
+import "gorm.io/gorm"
+
+type ArchiveKind string
+
+// From the outside in; each kind is filed in the one before it
+const (
+	ArchiveBox    ArchiveKind = "box"
+	ArchiveBinder ArchiveKind = "binder"
+	ArchivePage   ArchiveKind = "page"
+	ArchiveStrip  ArchiveKind = "strip"
+)
+
+// ArchiveLocation is a place negatives are filed in. Boxes hold binders,
+// binders hold pages (sleeves) and pages hold strips. Developed rolls are
+// filed on a page, their frames on its strips.
+type ArchiveLocation struct {
+	gorm.Model
+	Kind     ArchiveKind      `gorm:"size:10;not null;index" json:"kind" validate:"required,oneof=box binder page strip"`
+	Label    string           `gorm:"not null" json:"label" validate:"required,max=100"` // shown after the kind, e.g. "2" for box 2
+	Position int              `gorm:"not null" json:"position" validate:"gte=0"`         // order among its siblings, e.g. the page number
+	ParentID *uint            `gorm:"index" json:"parent_id"`                            // nil for boxes and loose binders
+	Parent   *ArchiveLocation `gorm:"foreignKey:ParentID" json:"-" validate:"-"`
+	Notes    *string          `json:"notes" validate:"omitempty,max=500"` // optional
+	UserID   uint             `gorm:"not null" json:"user_id" validate:"required"`
+	User     User             `gorm:"foreignKey:UserID" json:"-" validate:"-"`
+}
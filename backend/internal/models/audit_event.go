@@ -0,0 +1,49 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Audit actions, "<area>.<what happened>"
+const (
+	AuditLogin        = "auth.login"
+	AuditLoginFailed  = "auth.login_failed"
+	AuditRefresh      = "auth.refresh"
+	AuditRefreshReuse = "auth.refresh_reused"
+	AuditLogout       = "auth.logout"
+	AuditLogoutAll    = "auth.logout_all"
+
+	AuditCameraCreate    = "camera.create"
+	AuditCameraUpdate    = "camera.update"
+	AuditCameraDelete    = "camera.delete"
+	AuditCameraDeleteAll = "camera.delete_all"
+	AuditLensCreate      = "lens.create"
+	AuditLensUpdate      = "lens.update"
+	AuditLensDelete      = "lens.delete"
+	AuditLensDeleteAll   = "lens.delete_all"
+
+	AuditCollectionImport = "collection.import"
+)
+
+// AuditEvent records who did what. Events are only ever inserted; the one
+// exception is account erasure, which removes the user's events with the rest
+// of their data.
+type AuditEvent struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `gorm:"index" json:"created_at"`
+
+	ActorID *uint  `gorm:"index" json:"actor_id"`                // who did it, nil for anonymous requests
+	UserID  *uint  `gorm:"index" json:"user_id"`                 // whose account or data it concerns
+	Action  string `gorm:"size:50;not null;index" json:"action"` // one of the Audit* constants
+
+	// Record the event is about, e.g. "camera" and its ID
+	Target   string `gorm:"size:20;index:idx_audit_target" json:"target,omitempty"`
+	TargetID *uint  `gorm:"index:idx_audit_target" json:"target_id,omitempty"`
+
+	IPAddress string `gorm:"size:45" json:"ip_address"`
+	UserAgent string `gorm:"size:255" json:"user_agent"`
+
+	// Event specific data, e.g. {"changes": {"brand": {"from": ..., "to": ...}}}
+	Details json.RawMessage `gorm:"type:json" json:"details,omitempty"`
+}
@@ -0,0 +1,44 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type DataRequestKind string
+
+const (
+	DataRequestExport  DataRequestKind = "export"  // "download my data" archive
+	DataRequestErasure DataRequestKind = "erasure" // hard delete of the account
+)
+
+type DataRequestStatus string
+
+const (
+	DataRequestPending   DataRequestStatus = "pending"
+	DataRequestRunning   DataRequestStatus = "running"
+	DataRequestCompleted DataRequestStatus = "completed"
+	DataRequestFailed    DataRequestStatus = "failed"
+	DataRequestCancelled DataRequestStatus = "cancelled"
+	DataRequestExpired   DataRequestStatus = "expired" // export archive was removed
+)
+
+// DataRequest is a background job acting on all of a user's data. Completed
+// erasures are kept as the record that the account was deleted, so there is
+// deliberately no foreign key to users and no personal data beyond the ID.
+type DataRequest struct {
+	gorm.Model
+	UserID uint              `gorm:"not null;index" json:"user_id"`
+	Kind   DataRequestKind   `gorm:"type:varchar(20);not null;index" json:"kind"`
+	Status DataRequestStatus `gorm:"type:varchar(20);not null;index" json:"status"`
+
+	DueAt       time.Time  `gorm:"not null;index" json:"due_at"` // erasures wait out the grace period
+	StartedAt   *time.Time `json:"started_at"`
+	CompletedAt *time.Time `json:"completed_at"`
+	ExpiresAt   *time.Time `json:"expires_at"` // when an export archive is removed
+
+	FilePath string `gorm:"size:255" json:"-"`
+	Summary  string `gorm:"type:text" json:"summary,omitempty"` // erasure: rows deleted per table
+	Error    string `gorm:"size:255" json:"error,omitempty"`
+}
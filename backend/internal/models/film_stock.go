@@ -0,0 +1,24 @@
+package models
+
+import "gorm.io/gorm"
+
+type FilmProcess string
+
+const (
+	ProcessC41 FilmProcess = "C-41"
+	ProcessE6  FilmProcess = "E-6"
+	ProcessBW  FilmProcess = "BW"
+)
+
+// FilmStock is a film the user shoots, e.g. Kodak Portra 400 in 120
+type FilmStock struct {
+	gorm.Model
+	Manufacturer string       `gorm:"not null" json:"manufacturer" validate:"required"`            // Kodak, Ilford
+	Name         string       `gorm:"not null" json:"name" validate:"required"`                    // Portra 400, HP5 Plus
+	ISO          int          `gorm:"not null" json:"iso" validate:"required,gt=0,lte=25600"`      // box speed
+	Format       CameraFormat `gorm:"not null" json:"format" validate:"required,oneof=35mm 120mm"` // same formats as cameras
+	Process      FilmProcess  `gorm:"not null" json:"process" validate:"required,oneof=C-41 E-6 BW"`
+	Notes        *string      `json:"notes" validate:"omitempty,max=500"` // optional
+	UserID       uint         `gorm:"not null" json:"user_id" validate:"required"`
+	User         User         `gorm:"foreignKey:UserID" json:"-" validate:"-"`
+}
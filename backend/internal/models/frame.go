@@ -0,0 +1,17 @@
+package models
+
+import "gorm.io/gorm"
+
+// Frame is one exposure on a roll
+type Frame struct {
+	gorm.Model
+	RollID  uint             `gorm:"not null;index" json:"roll_id"`
+	Roll    *Roll            `gorm:"foreignKey:RollID" json:"-" validate:"-"`
+	Number  int              `gorm:"not null" json:"number" validate:"gte=0,lte=100"` // as printed on the film edge
+	StripID *uint            `gorm:"index" json:"strip_id"`                           // strip on the roll's page it is filed in
+	Strip   *ArchiveLocation `gorm:"foreignKey:StripID" json:"-" validate:"-"`
+	Title   *string          `json:"title" validate:"omitempty,max=100"` // optional
+	Notes   *string          `json:"notes" validate:"omitempty,max=500"` // optional
+	UserID  uint             `gorm:"not null" json:"user_id" validate:"required"`
+	User    User             `gorm:"foreignKey:UserID" json:"-" validate:"-"`
+}
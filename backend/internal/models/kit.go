@@ -0,0 +1,14 @@
+package models
+
+import "gorm.io/gorm"
+
+// Kit is the gear packed for a kind of shoot, e.g. "street" or "landscape"
+type Kit struct {
+	gorm.Model
+	Name    string   `gorm:"not null" json:"name" validate:"required,max=100"`
+	Notes   *string  `json:"notes" validate:"omitempty,max=500"` // optional
+	Cameras []Camera `gorm:"many2many:kit_cameras" json:"cameras"`
+	Lenses  []Lens   `gorm:"many2many:kit_lenses" json:"lenses"`
+	UserID  uint     `gorm:"not null" json:"user_id"`
+	User    User     `gorm:"foreignKey:UserID" json:"-"`
+}
@@ -0,0 +1,18 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type PasswordResetToken struct {
+	gorm.Model
+
+	UserID    uint       `gorm:"not null;index"`
+	TokenHash string     `gorm:"not null;uniqueIndex"`
+	ExpiresAt time.Time  `gorm:"not null"`
+	UsedAt    *time.Time // set once the token has been redeemed or superseded
+
+	User User `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+}
@@ -0,0 +1,38 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Scopes limit what a personal access token may do. Resource routes map
+// GET requests to "<resource>:read" and everything else to "<resource>:write".
+const (
+	ScopeCamerasRead     = "cameras:read"
+	ScopeCamerasWrite    = "cameras:write"
+	ScopeLensesRead      = "lenses:read"
+	ScopeLensesWrite     = "lenses:write"
+	ScopeCollectionRead  = "collection:read"
+	ScopeCollectionWrite = "collection:write"
+)
+
+// PersonalAccessToken is a long-lived credential for scripts, stored hashed
+type PersonalAccessToken struct {
+	gorm.Model
+
+	UserID     uint   `gorm:"not null;index"`
+	Name       string `gorm:"size:100;not null"`
+	TokenHash  string `gorm:"not null;uniqueIndex"`
+	Prefix     string `gorm:"size:16;not null"`  // first characters of the token, to tell tokens apart
+	Scopes     string `gorm:"size:255;not null"` // space separated
+	ExpiresAt  *time.Time
+	LastUsedAt *time.Time
+
+	User User `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+}
+
+func (t *PersonalAccessToken) ScopeList() []string {
+	return strings.Fields(t.Scopes)
+}
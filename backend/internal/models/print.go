@@ -0,0 +1,35 @@
+package models
+
+import (
+	"encoding/json"
+
+	"gorm.io/gorm"
+)
+
+// Print is one darkroom print of a frame. A reprint is a new version that
+// points back at the attempt it was based on and records what changed.
+type Print struct {
+	gorm.Model
+	FrameID    uint   `gorm:"not null;index" json:"frame_id" validate:"required"`
+	Frame      *Frame `gorm:"foreignKey:FrameID" json:"-" validate:"-"`
+	Version    int    `gorm:"not null" json:"version"`
+	PreviousID *uint  `gorm:"index" json:"previous_id"` // the attempt this one reprints
+	Previous   *Print `gorm:"foreignKey:PreviousID" json:"-" validate:"-"`
+
+	Paper            string   `gorm:"not null" json:"paper" validate:"required,max=100"`                  // "Ilford MGFB Warmtone"
+	PaperSize        string   `gorm:"size:20;not null" json:"paper_size" validate:"required,max=20"`      // "8x10in", "24x30cm"
+	Contrast         *string  `gorm:"size:20" json:"contrast" validate:"omitempty,max=20"`                // grade or filter, "2.5", "00"
+	EnlargerHeightCM *float64 `json:"enlarger_height_cm" validate:"omitempty,gt=0,lte=300"`               // optional
+	Aperture         *string  `gorm:"size:10" json:"aperture" validate:"omitempty,contains=f/"`           // "f/8"
+	ExposureSeconds  float64  `gorm:"not null" json:"exposure_seconds" validate:"required,gt=0,lte=3600"` // base exposure
+	TestStrip        *string  `json:"test_strip" validate:"omitempty,max=500"`                            // "5s steps from 5s, 15s best"
+	DodgeBurn        *string  `json:"dodge_burn" validate:"omitempty,max=1000"`                           // "dodge face 3s, burn sky +50%"
+	Toning           *string  `json:"toning" validate:"omitempty,max=200"`                                // "selenium 1+9, 3 min"
+	Notes            *string  `json:"notes" validate:"omitempty,max=500"`                                 // optional, e.g. how it came out
+
+	// What changed from the previous version, {"paper": {"from": ..., "to": ...}}
+	Changes json.RawMessage `gorm:"type:json" json:"changes,omitempty" validate:"-"`
+
+	UserID uint `gorm:"not null" json:"user_id" validate:"required"`
+	User   User `gorm:"foreignKey:UserID" json:"-" validate:"-"`
+}
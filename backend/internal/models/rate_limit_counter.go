@@ -0,0 +1,11 @@
+package models
+
+import "time"
+
+// RateLimitCounter backs the SQL rate limit store
+type RateLimitCounter struct {
+	Bucket       string    `gorm:"primaryKey;size:191"` // e.g. "login:ip:203.0.113.7"
+	Count        int       `gorm:"not null"`
+	ResetAt      time.Time `gorm:"not null;index"`
+	BlockedUntil *time.Time
+}
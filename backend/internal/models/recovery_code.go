@@ -0,0 +1,18 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// RecoveryCode is a one-time 2FA backup code, stored hashed
+type RecoveryCode struct {
+	gorm.Model
+
+	UserID   uint   `gorm:"not null;index"`
+	CodeHash string `gorm:"not null;uniqueIndex"`
+	UsedAt   *time.Time
+
+	User User `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+}
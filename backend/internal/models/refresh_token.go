@@ -11,8 +11,19 @@ type RefreshToken struct {
 
 	UserID    uint      `gorm:"not null;index"`
 	TokenHash string    `gorm:"not null;uniqueIndex"`
+	FamilyID  string    `gorm:"size:32;index"` // shared by every token rotated from the same login
 	ExpiresAt time.Time `gorm:"not null"`
 	Revoked   bool      `gorm:"default:false"`
 
+	// Set when the token was revoked by rotation. Only presenting a rotated
+	// token is reuse; logout, password resets and admins revoke without it.
+	RotatedAt *time.Time
+
+	// Client metadata, refreshed on every rotation
+	UserAgent        string `gorm:"size:255"`
+	IPAddress        string `gorm:"size:45"`
+	SessionStartedAt time.Time
+	LastUsedAt       time.Time
+
 	User User `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
 }
@@ -0,0 +1,37 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type RollStatus string
+
+// A roll only moves forward through these
+const (
+	RollLoaded    RollStatus = "loaded"
+	RollExposed   RollStatus = "exposed"
+	RollDeveloped RollStatus = "developed"
+)
+
+// Roll is one roll of a film stock. While loaded it sits in CameraID; the
+// camera is kept afterwards as the one it was shot with. Once developed it
+// can be filed in the negative archive.
+type Roll struct {
+	gorm.Model
+	FilmStockID uint       `gorm:"not null" json:"film_stock_id" validate:"required"`
+	FilmStock   *FilmStock `gorm:"foreignKey:FilmStockID" json:"film_stock,omitempty" validate:"-"`
+	CameraID    *uint      `gorm:"index" json:"camera_id"`
+	Camera      *Camera    `gorm:"foreignKey:CameraID" json:"camera,omitempty" validate:"-"`
+	Status      RollStatus `gorm:"size:20;not null;index" json:"status" validate:"required,oneof=loaded exposed developed"`
+	LoadedAt    *time.Time `json:"loaded_at"`
+	ExposedAt   *time.Time `json:"exposed_at"`
+	DevelopedAt *time.Time `json:"developed_at"`
+	// Page of the negative archive the developed roll is filed on
+	ArchiveLocationID *uint            `gorm:"index" json:"archive_location_id"`
+	ArchiveLocation   *ArchiveLocation `gorm:"foreignKey:ArchiveLocationID" json:"-" validate:"-"`
+	Notes             *string          `json:"notes" validate:"omitempty,max=500"` // optional
+	UserID            uint             `gorm:"not null" json:"user_id" validate:"required"`
+	User              User             `gorm:"foreignKey:UserID" json:"-" validate:"-"`
+}
@@ -0,0 +1,18 @@
+package models
+
+import "gorm.io/gorm"
+
+// Shoot is a planned outing: where, when and with which kit
+type Shoot struct {
+	gorm.Model
+	Title     string  `gorm:"not null" json:"title" validate:"required,max=100"`
+	Date      string  `gorm:"size:10;not null" json:"date" validate:"required,datetime=2006-01-02"` // local date at the location
+	Latitude  float64 `gorm:"not null" json:"latitude" validate:"gte=-90,lte=90"`
+	Longitude float64 `gorm:"not null" json:"longitude" validate:"gte=-180,lte=180"`
+	Timezone  string  `gorm:"size:64;not null" json:"timezone" validate:"omitempty,timezone"` // IANA name, UTC when empty
+	KitID     *uint   `gorm:"index" json:"kit_id"`                                            // optional
+	Kit       *Kit    `gorm:"foreignKey:KitID" json:"-" validate:"-"`
+	Notes     *string `json:"notes" validate:"omitempty,max=500"` // optional
+	UserID    uint    `gorm:"not null" json:"user_id" validate:"required"`
+	User      User    `gorm:"foreignKey:UserID" json:"-" validate:"-"`
+}
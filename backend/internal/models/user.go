@@ -1,13 +1,42 @@
 package models
 
-import "gorm.io/gorm"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type Role string
+
+const (
+	RoleUser      Role = "user"
+	RoleModerator Role = "moderator"
+	RoleAdmin     Role = "admin"
+)
 
 type User struct {
 	gorm.Model
 	Email        string `gorm:"uniqueIndex;not null" json:"email" validate:"required,email"`
-	PasswordHash string `gorm:"not null" json:"-" validate:"required"`
+	PasswordHash string `gorm:"not null" json:"-" validate:"required"` // empty for accounts that only sign in through an identity provider
 	FirstName    string `json:"first_name" validate:"required,max=50,min=2"`
 	LastName     string `json:"last_name" validate:"required,max=50,min=2"`
+	Role         Role   `gorm:"type:varchar(20);not null;default:user" json:"role"`
+	Disabled     bool   `gorm:"not null;default:false" json:"disabled"`
+
+	EmailVerifiedAt *time.Time `json:"email_verified_at"`
+
+	// Preferences
+	Units    string `gorm:"size:10;not null;default:metric" json:"units"` // metric | imperial
+	Currency string `gorm:"size:3;not null;default:EUR" json:"currency"`  // ISO 4217 code
+	Timezone string `gorm:"size:64;not null;default:UTC" json:"timezone"` // IANA name
+
+	// TOTP second factor. The secret is stored while enrolling and only
+	// enforced once TOTPEnabled is set by confirming a first code.
+	TOTPSecret   string `gorm:"size:64" json:"-"`
+	TOTPEnabled  bool   `gorm:"not null;default:false" json:"totp_enabled"`
+	TOTPLastStep int64  `json:"-"` // last accepted time step, blocks replaying a code
+
+	Identities []UserIdentity `gorm:"foreignKey:UserID" json:"-"`
 
 	Cameras []Camera `gorm:"foreignKey:UserID" json:"-"`
 	Lenses  []Lens   `gorm:"foreignKey:UserID" json:"-"`
@@ -0,0 +1,15 @@
+package models
+
+import "gorm.io/gorm"
+
+// UserIdentity links a user to an account at an external OpenID Connect provider
+type UserIdentity struct {
+	gorm.Model
+
+	UserID   uint   `gorm:"not null;index"`
+	Provider string `gorm:"size:50;not null;uniqueIndex:idx_identity_provider_subject"`
+	Subject  string `gorm:"size:255;not null;uniqueIndex:idx_identity_provider_subject"` // "sub" claim
+	Email    string `gorm:"size:255"`                                                    // email at the provider when linked
+
+	User User `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+}
@@ -0,0 +1,116 @@
+package password
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+)
+
+const (
+	saltLength = 16
+	keyLength  = 32
+)
+
+// Params are the argon2id cost settings for new hashes
+type Params struct {
+	Memory      uint32 // KiB
+	Iterations  uint32
+	Parallelism uint8
+}
+
+// DefaultParams follow the second recommended option of RFC 9106
+var DefaultParams = Params{
+	Memory:      64 * 1024,
+	Iterations:  3,
+	Parallelism: 4,
+}
+
+// Argon2id makes PHC strings with the given parameters. Verify uses the
+// parameters stored in each hash.
+type Argon2id struct {
+	Params Params
+}
+
+func (a Argon2id) Owns(hash string) bool {
+	return strings.HasPrefix(hash, "$argon2id$")
+}
+
+// Hash returns an argon2id PHC string for password
+func (a Argon2id) Hash(password string) (string, error) {
+	salt := make([]byte, saltLength)
+	_, err := rand.Read(salt)
+	if err != nil {
+		return "", err
+	}
+
+	key := argon2.IDKey([]byte(password), salt, a.Params.Iterations, a.Params.Memory, a.Params.Parallelism, keyLength)
+
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, a.Params.Memory, a.Params.Iterations, a.Params.Parallelism,
+		b64.EncodeToString(salt), b64.EncodeToString(key),
+	), nil
+}
+
+func (a Argon2id) Verify(hash, password string) (bool, error) {
+	phc, err := parseArgon2id(hash)
+	if err != nil {
+		return false, err
+	}
+
+	key := argon2.IDKey([]byte(password), phc.salt, phc.params.Iterations, phc.params.Memory, phc.params.Parallelism, uint32(len(phc.key)))
+	return subtle.ConstantTimeCompare(key, phc.key) == 1, nil
+}
+
+func (a Argon2id) Current(hash string) bool {
+	phc, err := parseArgon2id(hash)
+	if err != nil {
+		return false
+	}
+
+	return phc.version == argon2.Version && phc.params == a.Params && len(phc.key) == keyLength
+}
+
+type argon2idHash struct {
+	version int
+	params  Params
+	salt    []byte
+	key     []byte
+}
+
+var b64 = base64.RawStdEncoding
+
+func parseArgon2id(hash string) (*argon2idHash, error) {
+	// "", "argon2id", "v=19", "m=...,t=...,p=...", salt, key
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return nil, ErrUnknownFormat
+	}
+
+	var phc argon2idHash
+
+	_, err := fmt.Sscanf(parts[2], "v=%d", &phc.version)
+	if err != nil {
+		return nil, ErrUnknownFormat
+	}
+
+	_, err = fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &phc.params.Memory, &phc.params.Iterations, &phc.params.Parallelism)
+	if err != nil {
+		return nil, ErrUnknownFormat
+	}
+
+	phc.salt, err = b64.DecodeString(parts[4])
+	if err != nil {
+		return nil, ErrUnknownFormat
+	}
+
+	phc.key, err = b64.DecodeString(parts[5])
+	if err != nil || len(phc.key) == 0 {
+		return nil, ErrUnknownFormat
+	}
+
+	return &phc, nil
+}
@@ -0,0 +1,104 @@
+package password
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// BreachedList checks passwords against a local copy of a breached-password
+// list such as Have I Been Pwned's, made of uppercase SHA-1 hashes.
+//
+// path can be a directory in the k-anonymity range layout, with one file per
+// 5-character hash prefix (e.g. "21BD1" or "21BD1.txt") holding the remaining
+// 35 characters per line. Only the range file of a password's prefix is read.
+// path can also be a single file with one full hash per line, which is kept in
+// memory and suits smaller lists. Lines may end in ":<count>".
+type BreachedList struct {
+	dir    string
+	hashes map[string]struct{}
+}
+
+func LoadBreachedList(path string) (*BreachedList, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	// Range files are only read when needed, so make sure now that there
+	// are some to read
+	if info.IsDir() {
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return nil, err
+		}
+		if len(entries) == 0 {
+			return nil, fmt.Errorf("%s: no range files", path)
+		}
+
+		return &BreachedList{dir: path}, nil
+	}
+
+	list := &BreachedList{hashes: map[string]struct{}{}}
+	err = eachHash(path, func(hash string) {
+		list.hashes[hash] = struct{}{}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
+
+// Contains reports whether password is on the list
+func (l *BreachedList) Contains(password string) (bool, error) {
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+
+	if l.hashes != nil {
+		_, ok := l.hashes[hash]
+		return ok, nil
+	}
+
+	prefix, suffix := hash[:5], hash[5:]
+
+	for _, name := range []string{prefix, prefix + ".txt"} {
+		found := false
+		err := eachHash(filepath.Join(l.dir, name), func(h string) {
+			if h == suffix {
+				found = true
+			}
+		})
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+
+		return found, err
+	}
+
+	return false, nil
+}
+
+func eachHash(path string, fn func(hash string)) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		hash, _, _ := strings.Cut(strings.TrimSpace(scanner.Text()), ":")
+		if hash != "" {
+			fn(strings.ToUpper(hash))
+		}
+	}
+
+	return scanner.Err()
+}
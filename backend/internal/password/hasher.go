@@ -0,0 +1,78 @@
+// Package password hashes passwords with argon2id in PHC string format
+// ($argon2id$v=19$m=65536,t=3,p=4$<salt>$<hash>) and still verifies the
+// bcrypt hashes stored before argon2id was introduced.
+//
+// The parameters are part of every hash, so they can be raised at any time:
+// NeedsRehash reports hashes made with older settings, and callers rehash
+// them when the user next logs in with the correct password.
+package password
+
+import "errors"
+
+var ErrUnknownFormat = errors.New("unknown password hash format")
+
+// Scheme is one way of hashing passwords. Every hash names the scheme and
+// settings that made it, so stored hashes keep verifying after new hashes
+// move to another scheme or stronger settings.
+type Scheme interface {
+	// Owns reports whether hash is in this scheme's format
+	Owns(hash string) bool
+	Hash(password string) (string, error)
+	Verify(hash, password string) (bool, error)
+	// Current reports whether hash, which the scheme owns, was made with the
+	// settings Hash uses now
+	Current(hash string) bool
+}
+
+// Hasher hashes new passwords with one scheme and verifies hashes of that
+// scheme and of older ones
+type Hasher struct {
+	current Scheme
+	legacy  []Scheme
+}
+
+// NewHasher hashes with argon2id at params and accepts old bcrypt hashes
+func NewHasher(params Params) *Hasher {
+	return NewSchemeHasher(Argon2id{Params: params}, Bcrypt{})
+}
+
+func NewSchemeHasher(current Scheme, legacy ...Scheme) *Hasher {
+	return &Hasher{
+		current: current,
+		legacy:  legacy,
+	}
+}
+
+// Hash hashes password with the current scheme
+func (h *Hasher) Hash(password string) (string, error) {
+	return h.current.Hash(password)
+}
+
+// Verify reports whether password matches the stored hash of any known scheme
+func (h *Hasher) Verify(hash, password string) (bool, error) {
+	scheme := h.schemeOf(hash)
+	if scheme == nil {
+		return false, ErrUnknownFormat
+	}
+
+	return scheme.Verify(hash, password)
+}
+
+// NeedsRehash reports whether hash was made with another scheme or other settings than new hashes
+func (h *Hasher) NeedsRehash(hash string) bool {
+	return !h.current.Owns(hash) || !h.current.Current(hash)
+}
+
+func (h *Hasher) schemeOf(hash string) Scheme {
+	if h.current.Owns(hash) {
+		return h.current
+	}
+
+	for _, scheme := range h.legacy {
+		if scheme.Owns(hash) {
+			return scheme
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,117 @@
+package password
+
+import (
+	"errors"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Cheap settings, the tests don't need real cost
+var testParams = Params{Memory: 1024, Iterations: 1, Parallelism: 1}
+
+func mustHash(t *testing.T, scheme Scheme, pw string) string {
+	t.Helper()
+
+	hash, err := scheme.Hash(pw)
+	if err != nil {
+		t.Fatalf("hash: %v", err)
+	}
+	return hash
+}
+
+func TestHasherVerify(t *testing.T) {
+	h := NewHasher(testParams)
+
+	argonHash := mustHash(t, Argon2id{Params: testParams}, "correct horse")
+	olderArgonHash := mustHash(t, Argon2id{Params: Params{Memory: 512, Iterations: 2, Parallelism: 1}}, "correct horse")
+
+	bcryptHash, err := bcrypt.GenerateFromPassword([]byte("correct horse"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		hash     string
+		password string
+		want     bool
+		wantErr  error
+	}{
+		{"argon2id match", argonHash, "correct horse", true, nil},
+		{"argon2id mismatch", argonHash, "wrong horse", false, nil},
+		{"argon2id with older params", olderArgonHash, "correct horse", true, nil},
+		{"bcrypt match", string(bcryptHash), "correct horse", true, nil},
+		{"bcrypt mismatch", string(bcryptHash), "wrong horse", false, nil},
+		{"empty hash", "", "correct horse", false, ErrUnknownFormat},
+		{"unknown scheme", "$scrypt$ln=15$abc$def", "correct horse", false, ErrUnknownFormat},
+		{"malformed argon2id", "$argon2id$v=19$m=1024$salt", "correct horse", false, ErrUnknownFormat},
+		{"argon2id bad base64", "$argon2id$v=19$m=1024,t=1,p=1$!!!$!!!", "correct horse", false, ErrUnknownFormat},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := h.Verify(tt.hash, tt.password)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Verify = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHasherNeedsRehash(t *testing.T) {
+	h := NewHasher(testParams)
+
+	bcryptHash, err := bcrypt.GenerateFromPassword([]byte("pw"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		hash string
+		want bool
+	}{
+		{"current params", mustHash(t, Argon2id{Params: testParams}, "pw"), false},
+		{"lower memory", mustHash(t, Argon2id{Params: Params{Memory: 512, Iterations: 1, Parallelism: 1}}, "pw"), true},
+		{"more iterations", mustHash(t, Argon2id{Params: Params{Memory: 1024, Iterations: 2, Parallelism: 1}}, "pw"), true},
+		{"other argon2 version", "$argon2id$v=16$m=1024,t=1,p=1$c2FsdHNhbHRzYWx0c2FsdA$a2V5a2V5a2V5a2V5a2V5a2V5a2V5a2V5a2V5a2V5a2U", true},
+		{"short key", "$argon2id$v=19$m=1024,t=1,p=1$c2FsdHNhbHRzYWx0c2FsdA$a2V5", true},
+		{"bcrypt", string(bcryptHash), true},
+		{"garbage", "not a hash", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := h.NeedsRehash(tt.hash); got != tt.want {
+				t.Errorf("NeedsRehash = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHasherHashUsesCurrentScheme(t *testing.T) {
+	h := NewSchemeHasher(Bcrypt{}, Argon2id{Params: testParams})
+
+	hash, err := h.Hash("pw")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !(Bcrypt{}).Owns(hash) {
+		t.Fatalf("hash %q is not bcrypt", hash)
+	}
+
+	// The former current scheme is still verified
+	old := mustHash(t, Argon2id{Params: testParams}, "pw")
+	ok, err := h.Verify(old, "pw")
+	if err != nil || !ok {
+		t.Fatalf("Verify(argon2id) = %v, %v", ok, err)
+	}
+	if !h.NeedsRehash(old) {
+		t.Error("argon2id hash should need a rehash to bcrypt")
+	}
+}
@@ -0,0 +1,134 @@
+package password
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/nbutton23/zxcvbn-go"
+)
+
+// Validation tags registered by RegisterValidations. DTOs use the "password"
+// alias, which expands to the configured rules; errors carry the single rule
+// that failed as FieldError.ActualTag().
+const (
+	TagPassword = "password"
+	TagWords    = "password_words"
+	TagStrength = "password_strength"
+	TagBreached = "password_breached"
+)
+
+// Words shorter than this are ignored when looking for banned words
+const minBannedWordLength = 3
+
+type Policy struct {
+	MinLength   int
+	MaxLength   int
+	MinScore    int      // zxcvbn score, 0 (too guessable) to 4 (very unguessable)
+	BannedWords []string // e.g. the product name, matched case-insensitively
+	Breached    *BreachedList
+	Logger      *log.Logger // reports breached list read errors; nil uses the standard logger
+}
+
+type userInputsKey struct{}
+
+// WithUserInputs adds the user's own details (name, email...) to ctx, so
+// validating with Validate.StructCtx rejects passwords built from them
+func WithUserInputs(ctx context.Context, inputs ...string) context.Context {
+	return context.WithValue(ctx, userInputsKey{}, inputs)
+}
+
+// RegisterValidations registers the password rules and the "password" alias on v
+func (p *Policy) RegisterValidations(v *validator.Validate) error {
+	err := v.RegisterValidationCtx(TagWords, p.validateWords)
+	if err != nil {
+		return err
+	}
+
+	err = v.RegisterValidationCtx(TagStrength, p.validateStrength)
+	if err != nil {
+		return err
+	}
+
+	rules := fmt.Sprintf("min=%d,max=%d,%s,%s=%d", p.MinLength, p.MaxLength, TagWords, TagStrength, p.MinScore)
+
+	if p.Breached != nil {
+		err = v.RegisterValidation(TagBreached, p.validateBreached)
+		if err != nil {
+			return err
+		}
+		rules += "," + TagBreached
+	}
+
+	v.RegisterAlias(TagPassword, rules)
+	return nil
+}
+
+func (p *Policy) validateWords(ctx context.Context, fl validator.FieldLevel) bool {
+	pw := strings.ToLower(fl.Field().String())
+
+	for _, word := range slices.Concat(p.BannedWords, userInputs(ctx)) {
+		word = strings.ToLower(strings.TrimSpace(word))
+		if len(word) >= minBannedWordLength && strings.Contains(pw, word) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// validateStrength checks the zxcvbn score against the tag's parameter, as in
+// "password_strength=3", or MinScore without one
+func (p *Policy) validateStrength(ctx context.Context, fl validator.FieldLevel) bool {
+	minScore := p.MinScore
+	if param := fl.Param(); param != "" {
+		var err error
+		minScore, err = strconv.Atoi(param)
+		if err != nil {
+			panic(fmt.Sprintf("%s: bad parameter %q", TagStrength, param))
+		}
+	}
+
+	inputs := slices.Concat(userInputs(ctx), p.BannedWords)
+	return zxcvbn.PasswordStrength(fl.Field().String(), inputs).Score >= minScore
+}
+
+func (p *Policy) validateBreached(fl validator.FieldLevel) bool {
+	found, err := p.Breached.Contains(fl.Field().String())
+	if err != nil {
+		// An unreadable range file shouldn't block signups, but must not go unnoticed
+		p.logf("breached password list: %v", err)
+		return true
+	}
+
+	return !found
+}
+
+func (p *Policy) logf(format string, args ...any) {
+	if p.Logger == nil {
+		log.Printf(format, args...)
+		return
+	}
+
+	p.Logger.Printf(format, args...)
+}
+
+// userInputs returns the user's details from ctx, with email addresses also
+// split into their local part
+func userInputs(ctx context.Context) []string {
+	inputs, _ := ctx.Value(userInputsKey{}).([]string)
+
+	result := make([]string, 0, len(inputs)+1)
+	for _, in := range inputs {
+		result = append(result, in)
+		if local, _, ok := strings.Cut(in, "@"); ok {
+			result = append(result, local)
+		}
+	}
+
+	return result
+}
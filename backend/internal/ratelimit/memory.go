@@ -0,0 +1,144 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryStore keeps counters in process memory. They are lost on restart and
+// not shared between instances.
+type MemoryStore struct {
+	mu          sync.Mutex
+	entries     map[string]*Entry
+	lastCleanup time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		entries:     map[string]*Entry{},
+		lastCleanup: time.Now(),
+	}
+}
+
+func (s *MemoryStore) Hit(ctx context.Context, key string, window time.Duration) (Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	s.cleanup(now)
+
+	e, ok := s.entries[key]
+	if !ok {
+		e = &Entry{}
+		s.entries[key] = e
+	}
+
+	if !now.Before(e.ResetAt) {
+		e.Count = 0
+		e.ResetAt = now.Add(window)
+	}
+	e.Count++
+
+	return *e, nil
+}
+
+func (s *MemoryStore) Attempt(ctx context.Context, key string, window time.Duration, wait func(count int) time.Duration) (Entry, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	s.cleanup(now)
+
+	e, ok := s.entries[key]
+	if !ok {
+		e = &Entry{}
+		s.entries[key] = e
+	}
+
+	if now.Before(e.BlockedUntil) {
+		return *e, false, nil
+	}
+
+	if !now.Before(e.ResetAt) {
+		e.Count = 0
+		e.ResetAt = now.Add(window)
+	}
+	e.Count++
+
+	if d := wait(e.Count); d > 0 {
+		e.BlockedUntil = now.Add(d)
+	}
+
+	return *e, true, nil
+}
+
+func (s *MemoryStore) Undo(ctx context.Context, key string, wait func(count int) time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.entries[key]
+	if !ok || e.Count == 0 {
+		return nil
+	}
+
+	e.Count--
+	if wait(e.Count) == 0 {
+		e.BlockedUntil = time.Time{}
+	}
+
+	return nil
+}
+
+func (s *MemoryStore) Get(ctx context.Context, key string) (Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.entries[key]
+	if !ok {
+		return Entry{}, nil
+	}
+
+	entry := *e
+	if !time.Now().Before(entry.ResetAt) {
+		entry.Count = 0
+	}
+
+	return entry, nil
+}
+
+func (s *MemoryStore) Block(ctx context.Context, key string, until time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.entries[key]
+	if !ok {
+		e = &Entry{}
+		s.entries[key] = e
+	}
+	e.BlockedUntil = until
+
+	return nil
+}
+
+func (s *MemoryStore) Reset(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.entries, key)
+	return nil
+}
+
+// cleanup drops entries whose window and block have both ended. Callers hold mu.
+func (s *MemoryStore) cleanup(now time.Time) {
+	if now.Sub(s.lastCleanup) < cleanupInterval {
+		return
+	}
+	s.lastCleanup = now
+
+	for key, e := range s.entries {
+		if now.After(e.ResetAt) && now.After(e.BlockedUntil) {
+			delete(s.entries, key)
+		}
+	}
+}
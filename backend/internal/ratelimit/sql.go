@@ -0,0 +1,189 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/georgiev098/film-manager/backend/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SQLStore keeps counters in the rate_limit_counters table so all instances share them
+type SQLStore struct {
+	db *gorm.DB
+
+	mu          sync.Mutex
+	lastCleanup time.Time
+}
+
+func NewSQLStore(db *gorm.DB) *SQLStore {
+	return &SQLStore{
+		db:          db,
+		lastCleanup: time.Now(),
+	}
+}
+
+func (s *SQLStore) Hit(ctx context.Context, key string, window time.Duration) (Entry, error) {
+	now := time.Now()
+	s.cleanup(ctx, now)
+
+	// One atomic upsert: start a new window if the old one ended, otherwise count up.
+	// count is assigned first, so both IFs still see the old reset_at.
+	err := s.db.WithContext(ctx).Exec(`
+		INSERT INTO rate_limit_counters (bucket, count, reset_at) VALUES (?, 1, ?)
+		ON DUPLICATE KEY UPDATE
+			count = IF(reset_at <= ?, 1, count + 1),
+			reset_at = IF(reset_at <= ?, VALUES(reset_at), reset_at)`,
+		key, now.Add(window), now, now,
+	).Error
+	if err != nil {
+		return Entry{}, err
+	}
+
+	return s.Get(ctx, key)
+}
+
+// Attempt locks the key's row for the read-decide-write, so concurrent
+// attempts are counted one after another
+func (s *SQLStore) Attempt(ctx context.Context, key string, window time.Duration, wait func(count int) time.Duration) (Entry, bool, error) {
+	now := time.Now()
+	s.cleanup(ctx, now)
+
+	var entry Entry
+	allowed := false
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Make sure the row exists, so there is something to lock
+		err := tx.Exec(`
+			INSERT INTO rate_limit_counters (bucket, count, reset_at) VALUES (?, 0, ?)
+			ON DUPLICATE KEY UPDATE bucket = bucket`,
+			key, now.Add(window),
+		).Error
+		if err != nil {
+			return err
+		}
+
+		var row models.RateLimitCounter
+		err = tx.Clauses(clause.Locking{Strength: clause.LockingStrengthUpdate}).
+			Where("bucket = ?", key).
+			First(&row).Error
+		if err != nil {
+			return err
+		}
+
+		if row.BlockedUntil != nil && now.Before(*row.BlockedUntil) {
+			entry = Entry{Count: row.Count, ResetAt: row.ResetAt, BlockedUntil: *row.BlockedUntil}
+			return nil
+		}
+
+		if !now.Before(row.ResetAt) {
+			row.Count = 0
+			row.ResetAt = now.Add(window)
+		}
+		row.Count++
+
+		if d := wait(row.Count); d > 0 {
+			blockedUntil := now.Add(d)
+			row.BlockedUntil = &blockedUntil
+		}
+
+		err = tx.Model(&models.RateLimitCounter{}).Where("bucket = ?", key).Updates(map[string]any{
+			"count":         row.Count,
+			"reset_at":      row.ResetAt,
+			"blocked_until": row.BlockedUntil,
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		entry = Entry{Count: row.Count, ResetAt: row.ResetAt}
+		if row.BlockedUntil != nil {
+			entry.BlockedUntil = *row.BlockedUntil
+		}
+		allowed = true
+		return nil
+	})
+	if err != nil {
+		return Entry{}, false, err
+	}
+
+	return entry, allowed, nil
+}
+
+func (s *SQLStore) Undo(ctx context.Context, key string, wait func(count int) time.Duration) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var row models.RateLimitCounter
+		err := tx.Clauses(clause.Locking{Strength: clause.LockingStrengthUpdate}).
+			Where("bucket = ?", key).
+			First(&row).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if row.Count == 0 {
+			return nil
+		}
+
+		updates := map[string]any{"count": row.Count - 1}
+		if wait(row.Count-1) == 0 {
+			updates["blocked_until"] = nil
+		}
+
+		return tx.Model(&models.RateLimitCounter{}).Where("bucket = ?", key).Updates(updates).Error
+	})
+}
+
+func (s *SQLStore) Get(ctx context.Context, key string) (Entry, error) {
+	var row models.RateLimitCounter
+
+	err := s.db.WithContext(ctx).Where("bucket = ?", key).First(&row).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return Entry{}, nil
+	}
+	if err != nil {
+		return Entry{}, err
+	}
+
+	entry := Entry{Count: row.Count, ResetAt: row.ResetAt}
+	if !time.Now().Before(row.ResetAt) {
+		entry.Count = 0
+	}
+	if row.BlockedUntil != nil {
+		entry.BlockedUntil = *row.BlockedUntil
+	}
+
+	return entry, nil
+}
+
+func (s *SQLStore) Block(ctx context.Context, key string, until time.Time) error {
+	return s.db.WithContext(ctx).Exec(`
+		INSERT INTO rate_limit_counters (bucket, count, reset_at, blocked_until) VALUES (?, 0, ?, ?)
+		ON DUPLICATE KEY UPDATE blocked_until = VALUES(blocked_until)`,
+		key, time.Now(), until,
+	).Error
+}
+
+func (s *SQLStore) Reset(ctx context.Context, key string) error {
+	return s.db.WithContext(ctx).Where("bucket = ?", key).Delete(&models.RateLimitCounter{}).Error
+}
+
+// cleanup deletes rows whose window and block have both ended
+func (s *SQLStore) cleanup(ctx context.Context, now time.Time) {
+	s.mu.Lock()
+	if now.Sub(s.lastCleanup) < cleanupInterval {
+		s.mu.Unlock()
+		return
+	}
+	s.lastCleanup = now
+	s.mu.Unlock()
+
+	s.db.WithContext(ctx).
+		Where("reset_at < ? AND (blocked_until IS NULL OR blocked_until < ?)", now, now).
+		Delete(&models.RateLimitCounter{})
+}
@@ -0,0 +1,50 @@
+// Package ratelimit counts attempts per key in fixed time windows. Keys can
+// additionally be blocked until a point in time, which is used for lockouts.
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Entry is the state of one key. The zero Entry means the key has no attempts.
+type Entry struct {
+	Count        int
+	ResetAt      time.Time // end of the current window
+	BlockedUntil time.Time
+}
+
+// Store keeps the counters. Use the SQL store when running several API instances.
+type Store interface {
+	// Hit records an attempt. A new window of the given length starts when the previous one has ended.
+	Hit(ctx context.Context, key string, window time.Duration) (Entry, error)
+	Get(ctx context.Context, key string) (Entry, error)
+	Block(ctx context.Context, key string, until time.Time) error
+	Reset(ctx context.Context, key string) error
+
+	// Attempt atomically records an attempt unless the key is blocked. wait maps
+	// the new count to how long later attempts are blocked, 0 for not at all.
+	// ok is false when the key was already blocked; nothing is counted then.
+	Attempt(ctx context.Context, key string, window time.Duration, wait func(count int) time.Duration) (entry Entry, ok bool, err error)
+	// Undo takes back one attempt and lifts the block if wait, given the
+	// remaining count, says there should be none
+	Undo(ctx context.Context, key string, wait func(count int) time.Duration) error
+}
+
+// Expired entries are swept at most this often
+const cleanupInterval = 10 * time.Minute
+
+// New builds the store selected by driver
+func New(driver string, db *gorm.DB) (Store, error) {
+	switch driver {
+	case "memory", "":
+		return NewMemoryStore(), nil
+	case "sql":
+		return NewSQLStore(db), nil
+	default:
+		return nil, fmt.Errorf("unknown rate limit store %q", driver)
+	}
+}
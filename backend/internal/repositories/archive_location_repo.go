@@ -0,0 +1,104 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/georgiev098/film-manager/backend/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ArchiveLocationRepo struct {
+	db *gorm.DB
+}
+
+func NewArchiveLocationRepo(db *gorm.DB) *ArchiveLocationRepo {
+	return &ArchiveLocationRepo{db: db}
+}
+
+func (r *ArchiveLocationRepo) GetAllByUserID(ctx context.Context, userID uint) ([]models.ArchiveLocation, error) {
+	var locations []models.ArchiveLocation
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("parent_id, position, id").Find(&locations).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return locations, nil
+}
+
+// GetChildren returns the locations filed directly in any of parentIDs, in order
+func (r *ArchiveLocationRepo) GetChildren(ctx context.Context, parentIDs ...uint) ([]models.ArchiveLocation, error) {
+	var locations []models.ArchiveLocation
+	err := r.db.WithContext(ctx).Where("parent_id IN ?", parentIDs).Order("position, id").Find(&locations).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return locations, nil
+}
+
+// InUse reports whether anything is filed in the location: other
+// locations, rolls or frames
+func (r *ArchiveLocationRepo) InUse(ctx context.Context, locationID uint) (bool, error) {
+	checks := []struct {
+		model  any
+		column string
+	}{
+		{&models.ArchiveLocation{}, "parent_id"},
+		{&models.Roll{}, "archive_location_id"},
+		{&models.Frame{}, "strip_id"},
+	}
+
+	for _, c := range checks {
+		var count int64
+		err := r.db.WithContext(ctx).Model(c.model).Where(c.column+" = ?", locationID).Count(&count).Error
+		if err != nil {
+			return false, err
+		}
+		if count > 0 {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func (r *ArchiveLocationRepo) CreateLocation(ctx context.Context, location *models.ArchiveLocation) error {
+	return r.db.WithContext(ctx).Omit(clause.Associations).Create(location).Error
+}
+
+func (r *ArchiveLocationRepo) GetLocationByID(ctx context.Context, locationID uint) (*models.ArchiveLocation, error) {
+	var location models.ArchiveLocation
+
+	err := r.db.WithContext(ctx).First(&location, locationID).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &location, nil
+}
+
+func (r *ArchiveLocationRepo) UpdateLocation(ctx context.Context, location *models.ArchiveLocation, updates map[string]any) error {
+	return r.db.WithContext(ctx).Model(location).Omit(clause.Associations).Updates(updates).Error
+}
+
+func (r *ArchiveLocationRepo) DeleteLocation(ctx context.Context, location *models.ArchiveLocation) error {
+	return r.db.WithContext(ctx).Delete(location).Error
+}
+
+// PurgeAllByUserID removes the user's locations for good, soft-deleted ones
+// included, and returns how many there were. Locations reference their
+// parent, so the innermost go first.
+func (r *ArchiveLocationRepo) PurgeAllByUserID(ctx context.Context, userID uint) (int64, error) {
+	var deleted int64
+
+	for _, kind := range []models.ArchiveKind{models.ArchiveStrip, models.ArchivePage, models.ArchiveBinder, models.ArchiveBox} {
+		res := r.db.WithContext(ctx).Unscoped().Where("user_id = ? AND kind = ?", userID, kind).Delete(&models.ArchiveLocation{})
+		if res.Error != nil {
+			return deleted, res.Error
+		}
+		deleted += res.RowsAffected
+	}
+
+	return deleted, nil
+}
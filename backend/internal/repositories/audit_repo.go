@@ -0,0 +1,63 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/georgiev098/film-manager/backend/internal/dtos"
+	"github.com/georgiev098/film-manager/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// AuditRepository only inserts and reads, audit events are never changed
+type AuditRepository struct {
+	db *gorm.DB
+}
+
+func NewAuditRepo(db *gorm.DB) *AuditRepository {
+	return &AuditRepository{
+		db: db,
+	}
+}
+
+func (r *AuditRepository) Create(ctx context.Context, event *models.AuditEvent) error {
+	return r.db.WithContext(ctx).Create(event).Error
+}
+
+func (r *AuditRepository) Search(ctx context.Context, filter dtos.AuditFilter) ([]models.AuditEvent, error) {
+	query := r.db.WithContext(ctx).Model(&models.AuditEvent{})
+
+	if filter.UserID != nil {
+		query = query.Where("user_id = ?", *filter.UserID)
+	}
+	if filter.ActorID != nil {
+		query = query.Where("actor_id = ?", *filter.ActorID)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.Target != "" {
+		query = query.Where("target = ?", filter.Target)
+	}
+	if filter.TargetID != nil {
+		query = query.Where("target_id = ?", *filter.TargetID)
+	}
+	if filter.IPAddress != "" {
+		query = query.Where("ip_address = ?", filter.IPAddress)
+	}
+	if filter.From != nil {
+		query = query.Where("created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("created_at < ?", *filter.To)
+	}
+	if filter.BeforeID != 0 {
+		query = query.Where("id < ?", filter.BeforeID)
+	}
+
+	var events []models.AuditEvent
+	err := query.Order("id DESC").Limit(filter.Limit).Find(&events).Error
+	if err != nil {
+		return nil, err
+	}
+	return events, nil
+}
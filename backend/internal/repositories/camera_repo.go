@@ -5,6 +5,7 @@ import (
 
 	"github.com/georgiev098/film-manager/backend/internal/models"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type CameraRepo struct {
@@ -62,3 +63,28 @@ func (r *CameraRepo) DeleteCamera(ctx context.Context, camera *models.Camera) er
 func (r *CameraRepo) DeleteAllByUserID(ctx context.Context, userID uint) error {
 	return r.db.WithContext(ctx).Where("user_id = ?", userID).Delete(&models.Camera{}).Error
 }
+
+// PurgeAllByUserID removes the user's cameras for good, soft-deleted ones included.
+// The rows reference the user, so they have to go before the user does.
+func (r *CameraRepo) PurgeAllByUserID(ctx context.Context, userID uint) error {
+	return r.db.WithContext(ctx).Unscoped().Where("user_id = ?", userID).Delete(&models.Camera{}).Error
+}
+
+// CountByUserIDLocked counts with a locking read. Inside a transaction it sees
+// rows committed after the transaction started, unlike a plain count.
+func (r *CameraRepo) CountByUserIDLocked(ctx context.Context, userID uint) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.Camera{}).Clauses(clause.Locking{Strength: clause.LockingStrengthUpdate}).Where("user_id = ?", userID).Count(&count).Error
+	return count, err
+}
+
+// GetByIDsForUser returns those of the cameras that belong to the user
+func (r *CameraRepo) GetByIDsForUser(ctx context.Context, userID uint, cameraIDs []uint) ([]models.Camera, error) {
+	var cameras []models.Camera
+	err := r.db.WithContext(ctx).Where("user_id = ? AND id IN ?", userID, cameraIDs).Find(&cameras).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return cameras, nil
+}
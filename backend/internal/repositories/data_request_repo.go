@@ -0,0 +1,128 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/georgiev098/film-manager/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+type DataRequestRepository struct {
+	db *gorm.DB
+}
+
+func NewDataRequestRepo(db *gorm.DB) *DataRequestRepository {
+	return &DataRequestRepository{
+		db: db,
+	}
+}
+
+func (r *DataRequestRepository) Create(ctx context.Context, req *models.DataRequest) error {
+	return r.db.WithContext(ctx).Create(req).Error
+}
+
+func (r *DataRequestRepository) GetForUser(ctx context.Context, userID, id uint, kind models.DataRequestKind) (*models.DataRequest, error) {
+	var req models.DataRequest
+	err := r.db.WithContext(ctx).Where("user_id = ? AND kind = ?", userID, kind).First(&req, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &req, nil
+}
+
+func (r *DataRequestRepository) GetAllByUser(ctx context.Context, userID uint, kind models.DataRequestKind) ([]models.DataRequest, error) {
+	var reqs []models.DataRequest
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND kind = ?", userID, kind).
+		Order("created_at DESC").
+		Find(&reqs).Error
+	if err != nil {
+		return nil, err
+	}
+	return reqs, nil
+}
+
+func (r *DataRequestRepository) GetAllByKind(ctx context.Context, kind models.DataRequestKind) ([]models.DataRequest, error) {
+	var reqs []models.DataRequest
+	err := r.db.WithContext(ctx).Where("kind = ?", kind).Order("created_at DESC").Find(&reqs).Error
+	if err != nil {
+		return nil, err
+	}
+	return reqs, nil
+}
+
+// FindOpen returns the user's pending or running request of the given kind
+func (r *DataRequestRepository) FindOpen(ctx context.Context, userID uint, kind models.DataRequestKind) (*models.DataRequest, error) {
+	var req models.DataRequest
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND kind = ? AND status IN ?", userID, kind,
+			[]models.DataRequestStatus{models.DataRequestPending, models.DataRequestRunning}).
+		First(&req).Error
+	if err != nil {
+		return nil, err
+	}
+	return &req, nil
+}
+
+// GetDue returns pending requests whose due time has passed, and running ones
+// that were started before staleBefore (their worker most likely died)
+func (r *DataRequestRepository) GetDue(ctx context.Context, now, staleBefore time.Time, limit int) ([]models.DataRequest, error) {
+	var reqs []models.DataRequest
+	err := r.db.WithContext(ctx).
+		Where("(status = ? AND due_at <= ?) OR (status = ? AND started_at < ?)",
+			models.DataRequestPending, now, models.DataRequestRunning, staleBefore).
+		Order("due_at").
+		Limit(limit).
+		Find(&reqs).Error
+	if err != nil {
+		return nil, err
+	}
+	return reqs, nil
+}
+
+// Claim marks a request as running unless it changed since it was read, so
+// only one worker picks it up. Returns false if another worker got it first.
+func (r *DataRequestRepository) Claim(ctx context.Context, req *models.DataRequest) (bool, error) {
+	now := time.Now()
+
+	res := r.db.WithContext(ctx).
+		Model(&models.DataRequest{}).
+		Where("id = ? AND status = ? AND updated_at = ?", req.ID, req.Status, req.UpdatedAt).
+		Updates(map[string]any{"status": models.DataRequestRunning, "started_at": now})
+	if res.Error != nil {
+		return false, res.Error
+	}
+
+	return res.RowsAffected == 1, nil
+}
+
+func (r *DataRequestRepository) Update(ctx context.Context, req *models.DataRequest, updates map[string]any) error {
+	return r.db.WithContext(ctx).Model(req).Updates(updates).Error
+}
+
+// CancelPending cancels the user's pending request of the given kind.
+// Returns false if there was none (a running request can't be cancelled).
+func (r *DataRequestRepository) CancelPending(ctx context.Context, userID uint, kind models.DataRequestKind) (bool, error) {
+	res := r.db.WithContext(ctx).
+		Model(&models.DataRequest{}).
+		Where("user_id = ? AND kind = ? AND status = ?", userID, kind, models.DataRequestPending).
+		Update("status", models.DataRequestCancelled)
+	if res.Error != nil {
+		return false, res.Error
+	}
+
+	return res.RowsAffected > 0, nil
+}
+
+// GetExpiredExports returns completed exports whose archive should be removed
+func (r *DataRequestRepository) GetExpiredExports(ctx context.Context, now time.Time) ([]models.DataRequest, error) {
+	var reqs []models.DataRequest
+	err := r.db.WithContext(ctx).
+		Where("kind = ? AND status = ? AND expires_at <= ?", models.DataRequestExport, models.DataRequestCompleted, now).
+		Find(&reqs).Error
+	if err != nil {
+		return nil, err
+	}
+	return reqs, nil
+}
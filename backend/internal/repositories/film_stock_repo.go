@@ -0,0 +1,55 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/georgiev098/film-manager/backend/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type FilmStockRepo struct {
+	db *gorm.DB
+}
+
+func NewFilmStockRepo(db *gorm.DB) *FilmStockRepo {
+	return &FilmStockRepo{db: db}
+}
+
+func (r *FilmStockRepo) GetAllByUserID(ctx context.Context, userID uint) ([]models.FilmStock, error) {
+	var stocks []models.FilmStock
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).Find(&stocks).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return stocks, nil
+}
+
+func (r *FilmStockRepo) CreateFilmStock(ctx context.Context, stock *models.FilmStock) error {
+	return r.db.WithContext(ctx).Omit(clause.Associations).Create(stock).Error
+}
+
+func (r *FilmStockRepo) GetFilmStockByID(ctx context.Context, stockID uint) (*models.FilmStock, error) {
+	var stock models.FilmStock
+
+	err := r.db.WithContext(ctx).First(&stock, stockID).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &stock, nil
+}
+
+func (r *FilmStockRepo) UpdateFilmStock(ctx context.Context, stock *models.FilmStock, updates map[string]any) error {
+	return r.db.WithContext(ctx).Model(stock).Updates(updates).Error
+}
+
+func (r *FilmStockRepo) DeleteFilmStock(ctx context.Context, stock *models.FilmStock) error {
+	return r.db.WithContext(ctx).Delete(stock).Error
+}
+
+// PurgeAllByUserID removes the user's film stocks for good, soft-deleted ones included
+func (r *FilmStockRepo) PurgeAllByUserID(ctx context.Context, userID uint) error {
+	return r.db.WithContext(ctx).Unscoped().Where("user_id = ?", userID).Delete(&models.FilmStock{}).Error
+}
@@ -0,0 +1,91 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/georgiev098/film-manager/backend/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type FrameRepo struct {
+	db *gorm.DB
+}
+
+func NewFrameRepo(db *gorm.DB) *FrameRepo {
+	return &FrameRepo{db: db}
+}
+
+func (r *FrameRepo) GetAllByUserID(ctx context.Context, userID uint) ([]models.Frame, error) {
+	var frames []models.Frame
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("roll_id, number, id").Find(&frames).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return frames, nil
+}
+
+func (r *FrameRepo) GetAllByRollID(ctx context.Context, rollID uint) ([]models.Frame, error) {
+	var frames []models.Frame
+	err := r.db.WithContext(ctx).Where("roll_id = ?", rollID).Order("number, id").Find(&frames).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return frames, nil
+}
+
+// GetAllByStripIDs returns the frames filed in these strips, in order
+func (r *FrameRepo) GetAllByStripIDs(ctx context.Context, stripIDs []uint) ([]models.Frame, error) {
+	var frames []models.Frame
+	err := r.db.WithContext(ctx).Where("strip_id IN ?", stripIDs).Order("number, id").Find(&frames).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return frames, nil
+}
+
+// NumberTaken reports whether the roll has another frame than exceptID with this number
+func (r *FrameRepo) NumberTaken(ctx context.Context, rollID uint, number int, exceptID uint) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.Frame{}).
+		Where("roll_id = ? AND number = ? AND id <> ?", rollID, number, exceptID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+func (r *FrameRepo) CreateFrame(ctx context.Context, frame *models.Frame) error {
+	return r.db.WithContext(ctx).Omit(clause.Associations).Create(frame).Error
+}
+
+func (r *FrameRepo) GetFrameByID(ctx context.Context, frameID uint) (*models.Frame, error) {
+	var frame models.Frame
+
+	err := r.db.WithContext(ctx).First(&frame, frameID).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &frame, nil
+}
+
+func (r *FrameRepo) UpdateFrame(ctx context.Context, frame *models.Frame, updates map[string]any) error {
+	return r.db.WithContext(ctx).Model(frame).Omit(clause.Associations).Updates(updates).Error
+}
+
+// UnfileByRollID takes the roll's frames off their strips, for when the roll
+// moves to another page
+func (r *FrameRepo) UnfileByRollID(ctx context.Context, rollID uint) error {
+	return r.db.WithContext(ctx).Model(&models.Frame{}).Where("roll_id = ?", rollID).Update("strip_id", nil).Error
+}
+
+func (r *FrameRepo) DeleteFrame(ctx context.Context, frame *models.Frame) error {
+	return r.db.WithContext(ctx).Delete(frame).Error
+}
+
+// PurgeAllByUserID removes the user's frames for good, soft-deleted ones included
+func (r *FrameRepo) PurgeAllByUserID(ctx context.Context, userID uint) error {
+	return r.db.WithContext(ctx).Unscoped().Where("user_id = ?", userID).Delete(&models.Frame{}).Error
+}
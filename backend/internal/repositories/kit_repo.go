@@ -0,0 +1,85 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/georgiev098/film-manager/backend/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type KitRepo struct {
+	db *gorm.DB
+}
+
+func NewKitRepo(db *gorm.DB) *KitRepo {
+	return &KitRepo{db: db}
+}
+
+func (r *KitRepo) GetAllByUserID(ctx context.Context, userID uint) ([]models.Kit, error) {
+	var kits []models.Kit
+	err := r.db.WithContext(ctx).Preload("Cameras").Preload("Lenses").Where("user_id = ?", userID).Find(&kits).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return kits, nil
+}
+
+// CreateKit inserts the kit and links the cameras and lenses it already holds
+func (r *KitRepo) CreateKit(ctx context.Context, kit *models.Kit) error {
+	return r.db.WithContext(ctx).Omit("Cameras.*", "Lenses.*", "User").Create(kit).Error
+}
+
+func (r *KitRepo) GetKitByID(ctx context.Context, kitID uint) (*models.Kit, error) {
+	var kit models.Kit
+
+	err := r.db.WithContext(ctx).Preload("Cameras").Preload("Lenses").First(&kit, kitID).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &kit, nil
+}
+
+func (r *KitRepo) UpdateKit(ctx context.Context, kit *models.Kit, updates map[string]any) error {
+	return r.db.WithContext(ctx).Model(kit).Omit(clause.Associations).Updates(updates).Error
+}
+
+// ReplaceCameras links exactly these cameras to the kit
+func (r *KitRepo) ReplaceCameras(ctx context.Context, kit *models.Kit, cameras []models.Camera) error {
+	return r.db.WithContext(ctx).Model(kit).Omit("Cameras.*").Association("Cameras").Replace(cameras)
+}
+
+// ReplaceLenses links exactly these lenses to the kit
+func (r *KitRepo) ReplaceLenses(ctx context.Context, kit *models.Kit, lenses []models.Lens) error {
+	return r.db.WithContext(ctx).Model(kit).Omit("Lenses.*").Association("Lenses").Replace(lenses)
+}
+
+func (r *KitRepo) DeleteKit(ctx context.Context, kit *models.Kit) error {
+	return r.db.WithContext(ctx).Delete(kit).Error
+}
+
+// UnlinkAllByUserID removes the links between the user's kits, soft-deleted
+// ones included, and their gear
+func (r *KitRepo) UnlinkAllByUserID(ctx context.Context, userID uint) error {
+	kitIDs := r.db.Unscoped().Model(&models.Kit{}).Select("id").Where("user_id = ?", userID)
+
+	err := r.db.WithContext(ctx).Exec("DELETE FROM kit_cameras WHERE kit_id IN (?)", kitIDs).Error
+	if err != nil {
+		return err
+	}
+
+	return r.db.WithContext(ctx).Exec("DELETE FROM kit_lenses WHERE kit_id IN (?)", kitIDs).Error
+}
+
+// PurgeAllByUserID removes the user's kits for good, soft-deleted ones
+// included, with their links to gear
+func (r *KitRepo) PurgeAllByUserID(ctx context.Context, userID uint) error {
+	err := r.UnlinkAllByUserID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	return r.db.WithContext(ctx).Unscoped().Where("user_id = ?", userID).Delete(&models.Kit{}).Error
+}
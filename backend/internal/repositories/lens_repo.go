@@ -5,6 +5,7 @@ import (
 
 	"github.com/georgiev098/film-manager/backend/internal/models"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type LensRepo struct {
@@ -62,3 +63,28 @@ func (r *LensRepo) DeleteLens(ctx context.Context, lens *models.Lens) error {
 func (r *LensRepo) DeleteAllByUserID(ctx context.Context, userID uint) error {
 	return r.db.WithContext(ctx).Where("user_id = ?", userID).Delete(&models.Lens{}).Error
 }
+
+// PurgeAllByUserID removes the user's lenses for good, soft-deleted ones included.
+// The rows reference the user, so they have to go before the user does.
+func (r *LensRepo) PurgeAllByUserID(ctx context.Context, userID uint) error {
+	return r.db.WithContext(ctx).Unscoped().Where("user_id = ?", userID).Delete(&models.Lens{}).Error
+}
+
+// CountByUserIDLocked counts with a locking read. Inside a transaction it sees
+// rows committed after the transaction started, unlike a plain count.
+func (r *LensRepo) CountByUserIDLocked(ctx context.Context, userID uint) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.Lens{}).Clauses(clause.Locking{Strength: clause.LockingStrengthUpdate}).Where("user_id = ?", userID).Count(&count).Error
+	return count, err
+}
+
+// GetByIDsForUser returns those of the lenses that belong to the user
+func (r *LensRepo) GetByIDsForUser(ctx context.Context, userID uint, lensIDs []uint) ([]models.Lens, error) {
+	var lenses []models.Lens
+	err := r.db.WithContext(ctx).Where("user_id = ? AND id IN ?", userID, lensIDs).Find(&lenses).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return lenses, nil
+}
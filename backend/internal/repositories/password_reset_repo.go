@@ -0,0 +1,77 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/georgiev098/film-manager/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+type PasswordResetRepository struct {
+	db *gorm.DB
+}
+
+func NewPasswordResetRepo(db *gorm.DB) *PasswordResetRepository {
+	return &PasswordResetRepository{
+		db: db,
+	}
+}
+
+func (r *PasswordResetRepository) Create(ctx context.Context, token *models.PasswordResetToken) error {
+	return r.db.WithContext(ctx).Create(token).Error
+}
+
+func (r *PasswordResetRepository) FindTokenByHash(ctx context.Context, tokenHash string) (*models.PasswordResetToken, error) {
+	var token models.PasswordResetToken
+
+	err := r.db.WithContext(ctx).Where("token_hash = ?", tokenHash).First(&token).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+// Redeem uses a token to set the user's new password hash and revoke their
+// refresh and personal access tokens, all or nothing. Returns false if the token was already used.
+func (r *PasswordResetRepository) Redeem(ctx context.Context, tokenID, userID uint, passwordHash string) (bool, error) {
+	redeemed := false
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&models.PasswordResetToken{}).
+			Where("id = ? AND used_at IS NULL", tokenID).
+			Update("used_at", time.Now())
+		if res.Error != nil || res.RowsAffected != 1 {
+			return res.Error
+		}
+
+		err := tx.Model(&models.User{}).Where("id = ?", userID).Update("password_hash", passwordHash).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Model(&models.RefreshToken{}).Where("user_id = ?", userID).Update("revoked", true).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Where("user_id = ?", userID).Delete(&models.PersonalAccessToken{}).Error
+		if err != nil {
+			return err
+		}
+
+		redeemed = true
+		return nil
+	})
+
+	return redeemed, err
+}
+
+// Invalidate all outstanding tokens of a user, so only the newest link works
+func (r *PasswordResetRepository) InvalidateAllByUser(ctx context.Context, userID uint) error {
+	return r.db.WithContext(ctx).
+		Model(&models.PasswordResetToken{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Update("used_at", time.Now()).Error
+}
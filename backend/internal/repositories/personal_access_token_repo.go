@@ -0,0 +1,66 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/georgiev098/film-manager/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+type PersonalAccessTokenRepository struct {
+	db *gorm.DB
+}
+
+func NewPersonalAccessTokenRepo(db *gorm.DB) *PersonalAccessTokenRepository {
+	return &PersonalAccessTokenRepository{
+		db: db,
+	}
+}
+
+func (r *PersonalAccessTokenRepository) Create(ctx context.Context, token *models.PersonalAccessToken) error {
+	return r.db.WithContext(ctx).Create(token).Error
+}
+
+func (r *PersonalAccessTokenRepository) FindTokenByHash(ctx context.Context, tokenHash string) (*models.PersonalAccessToken, error) {
+	var token models.PersonalAccessToken
+
+	err := r.db.WithContext(ctx).Where("token_hash = ?", tokenHash).First(&token).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+func (r *PersonalAccessTokenRepository) GetAllByUser(ctx context.Context, userID uint) ([]models.PersonalAccessToken, error) {
+	var tokens []models.PersonalAccessToken
+
+	err := r.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Find(&tokens).Error
+
+	return tokens, err
+}
+
+// DeleteForUser deletes a token only if it belongs to the user. Returns the number of rows deleted.
+func (r *PersonalAccessTokenRepository) DeleteForUser(ctx context.Context, userID, tokenID uint) (int64, error) {
+	res := r.db.WithContext(ctx).
+		Where("id = ? AND user_id = ?", tokenID, userID).
+		Delete(&models.PersonalAccessToken{})
+
+	return res.RowsAffected, res.Error
+}
+
+func (r *PersonalAccessTokenRepository) DeleteAllByUser(ctx context.Context, userID uint) error {
+	return r.db.WithContext(ctx).Where("user_id = ?", userID).Delete(&models.PersonalAccessToken{}).Error
+}
+
+// TouchLastUsed records a use of the token
+func (r *PersonalAccessTokenRepository) TouchLastUsed(ctx context.Context, tokenID uint, at time.Time) error {
+	return r.db.WithContext(ctx).
+		Model(&models.PersonalAccessToken{}).
+		Where("id = ?", tokenID).
+		UpdateColumn("last_used_at", at).Error
+}
@@ -0,0 +1,94 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/georgiev098/film-manager/backend/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type PrintRepo struct {
+	db *gorm.DB
+}
+
+func NewPrintRepo(db *gorm.DB) *PrintRepo {
+	return &PrintRepo{db: db}
+}
+
+func (r *PrintRepo) GetAllByUserID(ctx context.Context, userID uint) ([]models.Print, error) {
+	var prints []models.Print
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("frame_id, version, id").Find(&prints).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return prints, nil
+}
+
+// GetAllByFrameID returns every version of every print of the frame
+func (r *PrintRepo) GetAllByFrameID(ctx context.Context, frameID uint) ([]models.Print, error) {
+	var prints []models.Print
+	err := r.db.WithContext(ctx).Where("frame_id = ?", frameID).Order("version, id").Find(&prints).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return prints, nil
+}
+
+func (r *PrintRepo) CreatePrint(ctx context.Context, print *models.Print) error {
+	return r.db.WithContext(ctx).Omit(clause.Associations).Create(print).Error
+}
+
+func (r *PrintRepo) GetPrintByID(ctx context.Context, printID uint) (*models.Print, error) {
+	var print models.Print
+
+	err := r.db.WithContext(ctx).First(&print, printID).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &print, nil
+}
+
+func (r *PrintRepo) UpdatePrint(ctx context.Context, print *models.Print, updates map[string]any) error {
+	return r.db.WithContext(ctx).Model(print).Omit(clause.Associations).Updates(updates).Error
+}
+
+func (r *PrintRepo) DeletePrint(ctx context.Context, print *models.Print) error {
+	return r.db.WithContext(ctx).Delete(print).Error
+}
+
+// UnlinkAllByUserID clears the links between versions of the user's prints,
+// which would otherwise keep the rows from being deleted in one statement
+func (r *PrintRepo) UnlinkAllByUserID(ctx context.Context, userID uint) error {
+	return r.db.WithContext(ctx).Unscoped().Model(&models.Print{}).Where("user_id = ?", userID).Update("previous_id", nil).Error
+}
+
+// PurgeAllByUserID removes the user's prints for good, soft-deleted ones included
+func (r *PrintRepo) PurgeAllByUserID(ctx context.Context, userID uint) error {
+	err := r.UnlinkAllByUserID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	return r.db.WithContext(ctx).Unscoped().Where("user_id = ?", userID).Delete(&models.Print{}).Error
+}
+
+// MaxVersionLocked returns the highest version among the frame's prints,
+// deleted ones included so numbers aren't reused. The locking read keeps two
+// new versions from getting the same number.
+func (r *PrintRepo) MaxVersionLocked(ctx context.Context, frameID uint) (int, error) {
+	var version *int
+	err := r.db.WithContext(ctx).Unscoped().Model(&models.Print{}).
+		Clauses(clause.Locking{Strength: clause.LockingStrengthUpdate}).
+		Where("frame_id = ?", frameID).
+		Select("MAX(version)").
+		Scan(&version).Error
+	if err != nil || version == nil {
+		return 0, err
+	}
+
+	return *version, nil
+}
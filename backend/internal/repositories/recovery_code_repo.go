@@ -0,0 +1,58 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/georgiev098/film-manager/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+type RecoveryCodeRepository struct {
+	db *gorm.DB
+}
+
+func NewRecoveryCodeRepo(db *gorm.DB) *RecoveryCodeRepository {
+	return &RecoveryCodeRepository{
+		db: db,
+	}
+}
+
+// Replace deletes the user's existing codes and stores a new set
+func (r *RecoveryCodeRepository) Replace(ctx context.Context, userID uint, codes []models.RecoveryCode) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Unscoped().Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Create(&codes).Error
+	})
+}
+
+// Redeem marks the unused code with codeHash as used. Returns false if no
+// such unused code exists.
+func (r *RecoveryCodeRepository) Redeem(ctx context.Context, userID uint, codeHash string) (bool, error) {
+	res := r.db.WithContext(ctx).
+		Model(&models.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Update("used_at", time.Now())
+	if res.Error != nil {
+		return false, res.Error
+	}
+
+	return res.RowsAffected == 1, nil
+}
+
+func (r *RecoveryCodeRepository) DeleteAllByUser(ctx context.Context, userID uint) error {
+	return r.db.WithContext(ctx).Unscoped().Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error
+}
+
+func (r *RecoveryCodeRepository) CountUnused(ctx context.Context, userID uint) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&models.RecoveryCode{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Count(&count).Error
+	return count, err
+}
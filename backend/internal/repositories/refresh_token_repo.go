@@ -44,6 +44,53 @@ func (r *RefreshTokenRepository) Revoke(ctx context.Context, tokenId uint) error
 		Update("revoked", true).Error
 }
 
+// Revoke a token for rotation, only if it is still active, and mark it as
+// rotated. Returns false when it was already revoked.
+func (r *RefreshTokenRepository) RevokeIfActive(ctx context.Context, tokenId uint) (bool, error) {
+	res := r.db.WithContext(ctx).
+		Model(&models.RefreshToken{}).
+		Where("id = ? AND revoked = ?", tokenId, false).
+		Updates(map[string]any{"revoked": true, "rotated_at": time.Now()})
+	if res.Error != nil {
+		return false, res.Error
+	}
+
+	return res.RowsAffected == 1, nil
+}
+
+// Revoke every token in a family (reuse detected)
+func (r *RefreshTokenRepository) RevokeFamily(ctx context.Context, familyID string) error {
+	return r.db.WithContext(ctx).
+		Model(&models.RefreshToken{}).
+		Where("family_id = ?", familyID).
+		Update("revoked", true).Error
+}
+
+// Active (not revoked, not expired) tokens of a user, newest first
+func (r *RefreshTokenRepository) GetActiveByUser(ctx context.Context, userID uint) ([]models.RefreshToken, error) {
+	var tokens []models.RefreshToken
+
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND revoked = ? AND expires_at > ?", userID, false, time.Now()).
+		Order("last_used_at DESC").
+		Find(&tokens).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return tokens, nil
+}
+
+// Revoke a family owned by userID. Returns the number of tokens revoked.
+func (r *RefreshTokenRepository) RevokeFamilyForUser(ctx context.Context, userID uint, familyID string) (int64, error) {
+	res := r.db.WithContext(ctx).
+		Model(&models.RefreshToken{}).
+		Where("user_id = ? AND family_id = ? AND revoked = ?", userID, familyID, false).
+		Update("revoked", true)
+
+	return res.RowsAffected, res.Error
+}
+
 // Revoke all refresh tokens by userID (logout everywhere)
 func (r *RefreshTokenRepository) RevokeAllByUser(ctx context.Context, userID uint) error {
 	return r.db.WithContext(ctx).
@@ -0,0 +1,104 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/georgiev098/film-manager/backend/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type RollRepo struct {
+	db *gorm.DB
+}
+
+func NewRollRepo(db *gorm.DB) *RollRepo {
+	return &RollRepo{db: db}
+}
+
+func (r *RollRepo) GetAllByUserID(ctx context.Context, userID uint) ([]models.Roll, error) {
+	var rolls []models.Roll
+	err := r.db.WithContext(ctx).Preload("FilmStock").Where("user_id = ?", userID).Find(&rolls).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return rolls, nil
+}
+
+// GetLoaded returns the user's rolls that are in a camera right now, with
+// their stock and camera. cameraIDs narrows them down to those cameras; nil
+// means all of them.
+func (r *RollRepo) GetLoaded(ctx context.Context, userID uint, cameraIDs []uint) ([]models.Roll, error) {
+	query := r.db.WithContext(ctx).
+		Preload("FilmStock").
+		Preload("Camera").
+		Where("user_id = ? AND status = ? AND camera_id IS NOT NULL", userID, models.RollLoaded)
+
+	if cameraIDs != nil {
+		query = query.Where("camera_id IN ?", cameraIDs)
+	}
+
+	var rolls []models.Roll
+	err := query.Order("id").Find(&rolls).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return rolls, nil
+}
+
+// CameraLoadedLocked reports whether another roll than exceptID is loaded in
+// the camera, with a locking read so two rolls cannot be loaded at once
+func (r *RollRepo) CameraLoadedLocked(ctx context.Context, cameraID, exceptID uint) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.Roll{}).
+		Clauses(clause.Locking{Strength: clause.LockingStrengthUpdate}).
+		Where("camera_id = ? AND status = ? AND id <> ?", cameraID, models.RollLoaded, exceptID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+func (r *RollRepo) CreateRoll(ctx context.Context, roll *models.Roll) error {
+	return r.db.WithContext(ctx).Omit(clause.Associations).Create(roll).Error
+}
+
+func (r *RollRepo) GetRollByID(ctx context.Context, rollID uint) (*models.Roll, error) {
+	var roll models.Roll
+
+	err := r.db.WithContext(ctx).Preload("FilmStock").First(&roll, rollID).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &roll, nil
+}
+
+func (r *RollRepo) UpdateRoll(ctx context.Context, roll *models.Roll, updates map[string]any) error {
+	return r.db.WithContext(ctx).Model(roll).Omit(clause.Associations).Updates(updates).Error
+}
+
+func (r *RollRepo) DeleteRoll(ctx context.Context, roll *models.Roll) error {
+	return r.db.WithContext(ctx).Delete(roll).Error
+}
+
+// PurgeAllByUserID removes the user's rolls for good, soft-deleted ones included
+func (r *RollRepo) PurgeAllByUserID(ctx context.Context, userID uint) error {
+	return r.db.WithContext(ctx).Unscoped().Where("user_id = ?", userID).Delete(&models.Roll{}).Error
+}
+
+// GetFiledOn returns the rolls filed on these pages, with their stock and camera
+func (r *RollRepo) GetFiledOn(ctx context.Context, pageIDs []uint) ([]models.Roll, error) {
+	var rolls []models.Roll
+	err := r.db.WithContext(ctx).
+		Preload("FilmStock").
+		Preload("Camera").
+		Where("archive_location_id IN ?", pageIDs).
+		Order("developed_at, id").
+		Find(&rolls).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return rolls, nil
+}
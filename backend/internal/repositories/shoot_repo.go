@@ -0,0 +1,60 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/georgiev098/film-manager/backend/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ShootRepo struct {
+	db *gorm.DB
+}
+
+func NewShootRepo(db *gorm.DB) *ShootRepo {
+	return &ShootRepo{db: db}
+}
+
+func (r *ShootRepo) GetAllByUserID(ctx context.Context, userID uint) ([]models.Shoot, error) {
+	var shoots []models.Shoot
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("date").Find(&shoots).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return shoots, nil
+}
+
+func (r *ShootRepo) CreateShoot(ctx context.Context, shoot *models.Shoot) error {
+	return r.db.WithContext(ctx).Omit(clause.Associations).Create(shoot).Error
+}
+
+func (r *ShootRepo) GetShootByID(ctx context.Context, shootID uint) (*models.Shoot, error) {
+	var shoot models.Shoot
+
+	err := r.db.WithContext(ctx).First(&shoot, shootID).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &shoot, nil
+}
+
+func (r *ShootRepo) UpdateShoot(ctx context.Context, shoot *models.Shoot, updates map[string]any) error {
+	return r.db.WithContext(ctx).Model(shoot).Omit(clause.Associations).Updates(updates).Error
+}
+
+// DetachKit clears the kit from the user's shoots that use it
+func (r *ShootRepo) DetachKit(ctx context.Context, kitID uint) error {
+	return r.db.WithContext(ctx).Model(&models.Shoot{}).Where("kit_id = ?", kitID).Update("kit_id", nil).Error
+}
+
+func (r *ShootRepo) DeleteShoot(ctx context.Context, shoot *models.Shoot) error {
+	return r.db.WithContext(ctx).Delete(shoot).Error
+}
+
+// PurgeAllByUserID removes the user's shoots for good, soft-deleted ones included
+func (r *ShootRepo) PurgeAllByUserID(ctx context.Context, userID uint) error {
+	return r.db.WithContext(ctx).Unscoped().Where("user_id = ?", userID).Delete(&models.Shoot{}).Error
+}
@@ -0,0 +1,211 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/georgiev098/film-manager/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// UserData is every stored record tied to one user, soft-deleted rows included
+type UserData struct {
+	User         models.User
+	Cameras      []models.Camera
+	Lenses       []models.Lens
+	FilmStocks   []models.FilmStock
+	Rolls        []models.Roll
+	Kits         []models.Kit
+	Shoots       []models.Shoot
+	Frames       []models.Frame
+	Prints       []models.Print
+	Archive      []models.ArchiveLocation
+	Sessions     []models.RefreshToken
+	AccessTokens []models.PersonalAccessToken
+	Identities   []models.UserIdentity
+	DataRequests []models.DataRequest
+	AuditEvents  []models.AuditEvent
+}
+
+// UserDataRepository reads and permanently removes all of a user's data,
+// bypassing soft deletes. Used by data export and erasure requests.
+type UserDataRepository struct {
+	db *gorm.DB
+}
+
+func NewUserDataRepo(db *gorm.DB) *UserDataRepository {
+	return &UserDataRepository{
+		db: db,
+	}
+}
+
+func (r *UserDataRepository) Collect(ctx context.Context, userID uint) (*UserData, error) {
+	// A session, so each query below starts from the unscoped base instead of
+	// piling its conditions onto the previous one
+	db := r.db.WithContext(ctx).Unscoped().Session(&gorm.Session{})
+
+	var data UserData
+
+	err := db.First(&data.User, userID).Error
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.Where("user_id = ?", userID).Order("id").Find(&data.Cameras).Error
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.Where("user_id = ?", userID).Order("id").Find(&data.Lenses).Error
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.Where("user_id = ?", userID).Order("id").Find(&data.FilmStocks).Error
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.Where("user_id = ?", userID).Order("id").Find(&data.Rolls).Error
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.Preload("Cameras").Preload("Lenses").Where("user_id = ?", userID).Order("id").Find(&data.Kits).Error
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.Where("user_id = ?", userID).Order("id").Find(&data.Shoots).Error
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.Where("user_id = ?", userID).Order("id").Find(&data.Frames).Error
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.Where("user_id = ?", userID).Order("id").Find(&data.Prints).Error
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.Where("user_id = ?", userID).Order("id").Find(&data.Archive).Error
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.Where("user_id = ?", userID).Order("id").Find(&data.Sessions).Error
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.Where("user_id = ?", userID).Order("id").Find(&data.AccessTokens).Error
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.Where("user_id = ?", userID).Order("id").Find(&data.Identities).Error
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.Where("user_id = ?", userID).Order("id").Find(&data.DataRequests).Error
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.Where("user_id = ?", userID).Order("id").Find(&data.AuditEvents).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &data, nil
+}
+
+// Erase hard-deletes the user and everything they own in one transaction and
+// returns the number of rows removed per table. Erasure requests are kept.
+func (r *UserDataRepository) Erase(ctx context.Context, userID uint) (map[string]int64, error) {
+	deleted := map[string]int64{}
+
+	// In an order that deletes rows before the ones they reference
+	owned := []struct {
+		table string
+		model any
+	}{
+		{"prints", &models.Print{}},
+		{"frames", &models.Frame{}},
+		{"shoots", &models.Shoot{}},
+		{"kits", &models.Kit{}},
+		{"rolls", &models.Roll{}},
+		{"film_stocks", &models.FilmStock{}},
+		{"cameras", &models.Camera{}},
+		{"lenses", &models.Lens{}},
+		{"refresh_tokens", &models.RefreshToken{}},
+		{"personal_access_tokens", &models.PersonalAccessToken{}},
+		{"recovery_codes", &models.RecoveryCode{}},
+		{"user_identities", &models.UserIdentity{}},
+		{"password_reset_tokens", &models.PasswordResetToken{}},
+		{"audit_events", &models.AuditEvent{}},
+	}
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// The links between kits and gear reference both
+		err := NewKitRepo(tx).UnlinkAllByUserID(ctx, userID)
+		if err != nil {
+			return err
+		}
+
+		// Reprints reference the print before them
+		err = NewPrintRepo(tx).UnlinkAllByUserID(ctx, userID)
+		if err != nil {
+			return err
+		}
+
+		for _, o := range owned {
+			res := tx.Unscoped().Where("user_id = ?", userID).Delete(o.model)
+			if res.Error != nil {
+				return res.Error
+			}
+			deleted[o.table] = res.RowsAffected
+		}
+
+		// After the rolls and frames filed in them
+		locations, err := NewArchiveLocationRepo(tx).PurgeAllByUserID(ctx, userID)
+		if err != nil {
+			return err
+		}
+		deleted["archive_locations"] = locations
+
+		res := tx.Unscoped().
+			Where("user_id = ? AND kind <> ?", userID, models.DataRequestErasure).
+			Delete(&models.DataRequest{})
+		if res.Error != nil {
+			return res.Error
+		}
+		deleted["data_requests"] = res.RowsAffected
+
+		res = tx.Unscoped().Delete(&models.User{}, userID)
+		if res.Error != nil {
+			return res.Error
+		}
+		deleted["users"] = res.RowsAffected
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return deleted, nil
+}
+
+// GetUser finds the user even if the account was soft-deleted
+func (r *UserDataRepository) GetUser(ctx context.Context, userID uint) (*models.User, error) {
+	var user models.User
+	err := r.db.WithContext(ctx).Unscoped().First(&user, userID).Error
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
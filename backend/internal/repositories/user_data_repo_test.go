@@ -0,0 +1,67 @@
+package repositories
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/georgiev098/film-manager/backend/internal/models"
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestCollect(t *testing.T) {
+	ctx := context.Background()
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{
+		Logger: logger.Discard,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = db.AutoMigrate(&models.User{}, &models.Camera{}, &models.Lens{}, &models.FilmStock{}, &models.Roll{}, &models.Kit{}, &models.Shoot{}, &models.ArchiveLocation{}, &models.Frame{}, &models.Print{}, &models.RefreshToken{}, &models.PersonalAccessToken{}, &models.UserIdentity{}, &models.DataRequest{}, &models.AuditEvent{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user := &models.User{Email: "user@example.com", FirstName: "Ansel", LastName: "Adams", Role: models.RoleUser}
+	other := &models.User{Email: "other@example.com", FirstName: "Vivian", LastName: "Maier", Role: models.RoleUser}
+	if err := db.Create([]*models.User{user, other}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	cameras := []models.Camera{
+		{Brand: "Nikon", CameraModel: "FM2", CameraFormat: models.Format35mm, UserID: user.ID},
+		{Brand: "Mamiya", CameraModel: "RB67", CameraFormat: models.Format120mm, UserID: user.ID},
+		{Brand: "Rollei", CameraModel: "2.8F", CameraFormat: models.Format120mm, UserID: other.ID},
+	}
+	if err := db.Create(&cameras).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Delete(&cameras[1]).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	lens := &models.Lens{Manufacturer: "Nikon", LensType: models.LensAnalog, FocalLengthMin: 50, FocalLengthMax: 50, MinApertureStr: "f/16", MaxApertureStr: "f/1.4", Mount: "F-mount", UserID: user.ID}
+	if err := db.Create(lens).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := NewUserDataRepo(db).Collect(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("Collect: %v", err)
+	}
+
+	if data.User.ID != user.ID {
+		t.Errorf("user: got %d, want %d", data.User.ID, user.ID)
+	}
+	// Soft-deleted gear is part of the export, other users' gear is not
+	if len(data.Cameras) != 2 {
+		t.Errorf("got %d cameras, want 2", len(data.Cameras))
+	}
+	if len(data.Lenses) != 1 {
+		t.Errorf("got %d lenses, want 1", len(data.Lenses))
+	}
+}
@@ -0,0 +1,39 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/georgiev098/film-manager/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+type UserIdentityRepository struct {
+	db *gorm.DB
+}
+
+func NewUserIdentityRepo(db *gorm.DB) *UserIdentityRepository {
+	return &UserIdentityRepository{
+		db: db,
+	}
+}
+
+func (r *UserIdentityRepository) Create(ctx context.Context, identity *models.UserIdentity) error {
+	return r.db.WithContext(ctx).Create(identity).Error
+}
+
+func (r *UserIdentityRepository) FindByProviderSubject(ctx context.Context, provider, subject string) (*models.UserIdentity, error) {
+	var identity models.UserIdentity
+
+	err := r.db.WithContext(ctx).
+		Where("provider = ? AND subject = ?", provider, subject).
+		First(&identity).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &identity, nil
+}
+
+func (r *UserIdentityRepository) DeleteAllByUser(ctx context.Context, userID uint) error {
+	return r.db.WithContext(ctx).Where("user_id = ?", userID).Delete(&models.UserIdentity{}).Error
+}
@@ -2,9 +2,11 @@ package repositories
 
 import (
 	"context"
+	"time"
 
 	"github.com/georgiev098/film-manager/backend/internal/models"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type UserRepository struct {
@@ -39,3 +41,70 @@ func (r *UserRepository) GetUserByID(ctx context.Context, userID uint) (*models.
 	}
 	return &user, nil
 }
+
+// LockUserByID loads a user and locks the row until the surrounding
+// transaction ends, serializing work done on the user's behalf
+func (r *UserRepository) LockUserByID(ctx context.Context, userID uint) (*models.User, error) {
+	var user models.User
+	err := r.db.WithContext(ctx).Clauses(clause.Locking{Strength: clause.LockingStrengthUpdate}).First(&user, userID).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+func (r *UserRepository) GetAllUsers(ctx context.Context) ([]models.User, error) {
+	var users []models.User
+	err := r.db.WithContext(ctx).Order("id").Find(&users).Error
+	if err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+// DeleteUser removes the user's row for good. A soft-deleted row would keep
+// its unique email address taken.
+func (r *UserRepository) DeleteUser(ctx context.Context, user *models.User) error {
+	return r.db.WithContext(ctx).Unscoped().Delete(user).Error
+}
+
+// EmailTaken reports whether any account, soft-deleted ones included, uses email
+func (r *UserRepository) EmailTaken(ctx context.Context, email string) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Unscoped().Model(&models.User{}).Where("email = ?", email).Count(&count).Error
+	return count > 0, err
+}
+
+// SetPasswordHash stores a new password hash and invalidates the user's open
+// password reset links, which were issued for the old password
+func (r *UserRepository) SetPasswordHash(ctx context.Context, user *models.User, hash string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(user).Update("password_hash", hash).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Model(&models.PasswordResetToken{}).
+			Where("user_id = ? AND used_at IS NULL", user.ID).
+			Update("used_at", time.Now()).Error
+	})
+}
+
+func (r *UserRepository) UpdateUser(ctx context.Context, user *models.User, updates map[string]any) error {
+	return r.db.WithContext(ctx).Model(user).Updates(updates).Error
+}
+
+// AdvanceTOTPStep records step as the last used TOTP step. Returns false if
+// that step (or a later one) was already used, so a code can't be replayed.
+func (r *UserRepository) AdvanceTOTPStep(ctx context.Context, userID uint, step int64) (bool, error) {
+	res := r.db.WithContext(ctx).
+		Model(&models.User{}).
+		Where("id = ? AND totp_last_step < ?", userID, step).
+		Update("totp_last_step", step)
+	if res.Error != nil {
+		return false, res.Error
+	}
+
+	return res.RowsAffected == 1, nil
+}
@@ -5,44 +5,128 @@ import (
 
 	"github.com/georgiev098/film-manager/backend/internal/core"
 	"github.com/georgiev098/film-manager/backend/internal/handlers"
+	"github.com/georgiev098/film-manager/backend/internal/helpers"
 	"github.com/georgiev098/film-manager/backend/internal/middlewares"
+	"github.com/georgiev098/film-manager/backend/internal/models"
 	"github.com/go-chi/chi/v5"
 )
 
 func Register(deps *core.AppDeps) http.Handler {
 	r := chi.NewRouter()
 
+	// --- Request ID, first so every response and log line carries it ---
+	r.Use(middlewares.RequestID)
 	// --- CORS ---
 	r.Use(middlewares.CORS(deps))
+	// --- Security headers ---
+	r.Use(middlewares.SecurityHeaders(deps))
 	// --- Logging ---
 	r.Use(middlewares.Logging)
 	// --- Recovery ---
 	r.Use(middlewares.Recovery)
+	// --- Client IP and user agent for the audit log ---
+	r.Use(middlewares.ClientInfo)
+	// --- Language of validation messages ---
+	r.Use(middlewares.Locale)
 
 	// --- Handlers ---
 	healthHandler := handlers.NewHealthHandler(deps)
 	authHandler := handlers.NewAuthHandler(deps)
 	cameraHandler := handlers.NewCameraHandler(deps)
 	lensHandler := handlers.NewLensHandler(deps)
+	lightHandler := handlers.NewLightHandler(deps)
+	filmStockHandler := handlers.NewFilmStockHandler(deps)
+	rollHandler := handlers.NewRollHandler(deps)
+	kitHandler := handlers.NewKitHandler(deps)
+	shootHandler := handlers.NewShootHandler(deps)
+	frameHandler := handlers.NewFrameHandler(deps)
+	archiveHandler := handlers.NewArchiveHandler(deps)
+	printHandler := handlers.NewPrintHandler(deps)
+	collectionHandler := handlers.NewCollectionHandler(deps)
+	adminHandler := handlers.NewAdminHandler(deps)
+	mfaHandler := handlers.NewMFAHandler(deps)
+	accessTokenHandler := handlers.NewAccessTokenHandler(deps)
+	oidcHandler := handlers.NewOIDCHandler(deps)
+	jwksHandler := handlers.NewJWKSHandler(deps)
+	userHandler := handlers.NewUserHandler(deps)
+	dataRequestHandler := handlers.NewDataRequestHandler(deps)
+	auditHandler := handlers.NewAuditHandler(deps)
 
 	// --- Health check ---
 	r.Get("/health", healthHandler.Check)
 
+	// --- Public keys for access tokens ---
+	r.Get("/.well-known/jwks.json", jwksHandler.Keys)
+
 	// --- Auth ---
 	r.Route("/auth", func(r chi.Router) {
 		r.Post("/signup", authHandler.SignUp)
 		r.Post("/login", authHandler.Login)
-		r.Post("/refresh", authHandler.Refresh)
-		r.Post("/logout", authHandler.Logout)
+		r.Post("/login/mfa", authHandler.LoginMFA)
+		r.Get("/csrf", authHandler.CSRFToken)
+		r.With(middlewares.CSRF(deps)).Post("/refresh", authHandler.Refresh)
+		r.With(middlewares.CSRF(deps)).Post("/logout", authHandler.Logout)
+		r.Post("/password/forgot", authHandler.ForgotPassword)
+		r.Post("/password/reset", authHandler.ResetPassword)
+		r.Post("/verify", authHandler.VerifyEmail)
+		r.Post("/verify/resend-email", authHandler.ResendVerificationByEmail)
+
+		r.Get("/oidc/providers", oidcHandler.Providers)
+		r.Get("/oidc/{provider}/login", oidcHandler.Login)
+		r.Get("/oidc/{provider}/callback", oidcHandler.Callback)
+
+		r.Group(func(r chi.Router) {
+			r.Use(middlewares.Auth(deps))
+			r.Use(middlewares.SessionOnly)
+
+			r.Get("/sessions", authHandler.GetSessions)
+			r.Delete("/sessions/{id}", authHandler.RevokeSession)
+			r.Post("/logout-all", authHandler.LogoutAll)
+			r.Post("/verify/resend", authHandler.ResendVerification)
+
+			r.Post("/2fa/enroll", mfaHandler.Enroll)
+			r.Post("/2fa/confirm", mfaHandler.Confirm)
+			r.Post("/2fa/disable", mfaHandler.Disable)
+			r.Post("/2fa/recovery-codes", mfaHandler.RegenerateRecoveryCodes)
+
+			r.Get("/tokens", accessTokenHandler.GetTokens)
+			r.Post("/tokens", accessTokenHandler.CreateToken)
+			r.Delete("/tokens/{id}", accessTokenHandler.RevokeToken)
+		})
+	})
+
+	// --- Current user ---
+	// Outside the protected group so unverified users can still fix their address
+	r.Route("/me", func(r chi.Router) {
+		r.Use(middlewares.Auth(deps))
+		r.Use(middlewares.SessionOnly)
+
+		r.Get("/", userHandler.GetMe)
+		r.Patch("/", userHandler.UpdateMe)
+		r.Delete("/", userHandler.DeleteMe)
+		r.Post("/password", userHandler.ChangePassword)
+		r.Post("/email", userHandler.ChangeEmail)
+
+		r.Get("/exports", dataRequestHandler.GetExports)
+		r.Post("/exports", dataRequestHandler.RequestExport)
+		r.Get("/exports/{id}", dataRequestHandler.GetExport)
+		r.Get("/exports/{id}/download", dataRequestHandler.DownloadExport)
+
+		r.Get("/erasure", dataRequestHandler.GetErasure)
+		r.Post("/erasure", dataRequestHandler.RequestErasure)
+		r.Delete("/erasure", dataRequestHandler.CancelErasure)
 	})
 
 	// Protected routes
 	r.Group(func(r chi.Router) {
 		r.Use(middlewares.Auth(deps))
+		r.Use(middlewares.UnverifiedReadOnly(deps))
 
 		// --- Cameras---
 		r.Route("/cameras", func(r chi.Router) {
-			r.Get("/all", cameraHandler.GetAllCameras)
+			r.Use(middlewares.RequireScope("cameras"))
+
+			r.With(middlewares.SessionOnly, middlewares.RequireRole(models.RoleAdmin)).Get("/all", cameraHandler.GetAllCameras)
 			r.Get("/", cameraHandler.GetAllCamerasForUser)
 			r.Post("/", cameraHandler.CreateCamera)
 			r.Get("/{id}", cameraHandler.GetCameraByID)
@@ -52,7 +136,9 @@ func Register(deps *core.AppDeps) http.Handler {
 
 		// --- Lenses ---
 		r.Route("/lenses", func(r chi.Router) {
-			r.Get("/all", lensHandler.GetAllLenses)
+			r.Use(middlewares.RequireScope("lenses"))
+
+			r.With(middlewares.SessionOnly, middlewares.RequireRole(models.RoleAdmin)).Get("/all", lensHandler.GetAllLenses)
 			r.Get("/", lensHandler.GetAllLensesForUser)
 			r.Post("/", lensHandler.CreateLens)
 			r.Get("/{id}", lensHandler.GetLensByID)
@@ -60,17 +146,124 @@ func Register(deps *core.AppDeps) http.Handler {
 			r.Delete("/{id}", lensHandler.DeleteLens)
 
 		})
+
+		// --- Film ---
+		r.Route("/film-stocks", func(r chi.Router) {
+			r.Use(middlewares.RequireScope("film"))
+
+			r.Get("/", filmStockHandler.GetFilmStocks)
+			r.Post("/", filmStockHandler.CreateFilmStock)
+			r.Get("/{id}", filmStockHandler.GetFilmStockByID)
+			r.Patch("/{id}", filmStockHandler.UpdateFilmStock)
+			r.Delete("/{id}", filmStockHandler.DeleteFilmStock)
+		})
+
+		r.Route("/rolls", func(r chi.Router) {
+			r.Use(middlewares.RequireScope("film"))
+
+			r.Get("/", rollHandler.GetRolls)
+			r.Post("/", rollHandler.CreateRoll)
+			r.Get("/{id}", rollHandler.GetRollByID)
+			r.Patch("/{id}", rollHandler.UpdateRoll)
+			r.Delete("/{id}", rollHandler.DeleteRoll)
+			r.Get("/{id}/frames", frameHandler.GetFrames)
+			r.Post("/{id}/frames", frameHandler.CreateFrame)
+		})
+
+		r.Route("/frames", func(r chi.Router) {
+			r.Use(middlewares.RequireScope("film"))
+
+			r.Get("/{id}", frameHandler.GetFrameByID)
+			r.Patch("/{id}", frameHandler.UpdateFrame)
+			r.Delete("/{id}", frameHandler.DeleteFrame)
+		})
+
+		// --- Negative archive ---
+		r.Route("/archive", func(r chi.Router) {
+			r.Use(middlewares.RequireScope("archive"))
+
+			r.Get("/locations", archiveHandler.GetLocations)
+			r.Post("/locations", archiveHandler.CreateLocation)
+			r.Get("/locations/{id}", archiveHandler.GetLocationByID)
+			r.Patch("/locations/{id}", archiveHandler.UpdateLocation)
+			r.Delete("/locations/{id}", archiveHandler.DeleteLocation)
+			r.Get("/find", archiveHandler.FindFrame)
+			r.With(middlewares.PrintHeaders(deps)).Get("/binders/{id}/index", archiveHandler.BinderIndex)
+		})
+
+		// --- Darkroom print log ---
+		r.Route("/prints", func(r chi.Router) {
+			r.Use(middlewares.RequireScope("prints"))
+
+			r.Get("/", printHandler.GetPrints)
+			r.Post("/", printHandler.CreatePrint)
+			r.Get("/{id}", printHandler.GetPrintByID)
+			r.Patch("/{id}", printHandler.UpdatePrint)
+			r.Delete("/{id}", printHandler.DeletePrint)
+			r.Post("/{id}/reprint", printHandler.Reprint)
+		})
+
+		// --- Kits and planned shoots ---
+		r.Route("/kits", func(r chi.Router) {
+			r.Use(middlewares.RequireScope("shoots"))
+
+			r.Get("/", kitHandler.GetKits)
+			r.Post("/", kitHandler.CreateKit)
+			r.Get("/{id}", kitHandler.GetKitByID)
+			r.Patch("/{id}", kitHandler.UpdateKit)
+			r.Delete("/{id}", kitHandler.DeleteKit)
+		})
+
+		r.Route("/shoots", func(r chi.Router) {
+			r.Use(middlewares.RequireScope("shoots"))
+
+			r.Get("/", shootHandler.GetShoots)
+			r.Post("/", shootHandler.CreateShoot)
+			r.Get("/{id}", shootHandler.GetShootByID)
+			r.Patch("/{id}", shootHandler.UpdateShoot)
+			r.Delete("/{id}", shootHandler.DeleteShoot)
+			r.Get("/{id}/light", shootHandler.GetLight)
+		})
+
+		// --- Audit log of the user's own account ---
+		r.With(middlewares.SessionOnly).Get("/audit", auditHandler.GetMyEvents)
+
+		// --- Light planning ---
+		r.With(middlewares.RequireScope("light")).Get("/light", lightHandler.GetLight)
+
+		// --- Import / export ---
+		r.With(middlewares.RequireScope("collection")).Get("/export", collectionHandler.Export)
+		r.Route("/import", func(r chi.Router) {
+			r.Use(middlewares.RequireScope("collection"))
+
+			r.Post("/", collectionHandler.Import)
+			r.Get("/sources", collectionHandler.ListSources)
+			r.Post("/{source}", collectionHandler.ImportExternal)
+		})
+
+		// --- Admin ---
+		r.Route("/admin", func(r chi.Router) {
+			r.Use(middlewares.SessionOnly)
+			r.Use(middlewares.RequireRole(models.RoleAdmin))
+
+			r.Get("/users", adminHandler.GetAllUsers)
+			r.Post("/users/{id}/disable", adminHandler.DisableUser)
+			r.Post("/users/{id}/enable", adminHandler.EnableUser)
+			r.Post("/users/{id}/unlock", adminHandler.UnlockUser)
+			r.Patch("/users/{id}/role", adminHandler.UpdateRole)
+			r.Post("/users/{id}/password", adminHandler.ResetPassword)
+			r.Get("/erasures", dataRequestHandler.GetErasureLog)
+			r.Get("/audit", auditHandler.Search)
+		})
 	})
 
 	// --- Not found / method not allowed ---
 	r.NotFound(func(w http.ResponseWriter, r *http.Request) {
-		w.WriteHeader(http.StatusNotFound)
-		w.Write([]byte("not found"))
+		helpers.WriteProblem(w, r, http.StatusNotFound, "not found")
 	})
 
 	r.MethodNotAllowed(func(w http.ResponseWriter, r *http.Request) {
-		w.WriteHeader(http.StatusMethodNotAllowed)
-		w.Write([]byte("method not allowed"))
+		helpers.WriteProblem(w, r, http.StatusMethodNotAllowed, "method not allowed")
 	})
 
 	return r
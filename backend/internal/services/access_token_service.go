@@ -0,0 +1,149 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/georgiev098/film-manager/backend/internal/apperrors"
+	"github.com/georgiev098/film-manager/backend/internal/dtos"
+	"github.com/georgiev098/film-manager/backend/internal/models"
+	"github.com/georgiev098/film-manager/backend/internal/repositories"
+)
+
+// AccessTokenPrefix marks personal access tokens so the auth middleware can
+// tell them apart from JWTs without trying to parse them
+const AccessTokenPrefix = "fmpat_"
+
+// Last-used timestamps are only written this often, not on every request
+const accessTokenTouchInterval = time.Minute
+
+var (
+	ErrInvalidAccessToken  = apperrors.Unauthorized("invalid or expired access token")
+	ErrAccessTokenNotFound = apperrors.NotFound("access token not found")
+)
+
+type AccessTokenService struct {
+	tokenRepo *repositories.PersonalAccessTokenRepository
+	userRepo  *repositories.UserRepository
+}
+
+func NewAccessTokenService(tokenRepo *repositories.PersonalAccessTokenRepository, userRepo *repositories.UserRepository) *AccessTokenService {
+	return &AccessTokenService{
+		tokenRepo: tokenRepo,
+		userRepo:  userRepo,
+	}
+}
+
+// Create issues a new token. The plain token is only ever returned here.
+func (s *AccessTokenService) Create(ctx context.Context, userID uint, input dtos.CreateAccessTokenRequest) (*dtos.CreatedAccessToken, error) {
+	raw := make([]byte, 32)
+	_, err := rand.Read(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	token := AccessTokenPrefix + hex.EncodeToString(raw)
+	expiresAt := time.Now().AddDate(0, 0, input.ExpiresInDays)
+
+	scopes := slices.Clone(input.Scopes)
+	slices.Sort(scopes)
+	scopes = slices.Compact(scopes)
+
+	pat := &models.PersonalAccessToken{
+		UserID:    userID,
+		Name:      strings.TrimSpace(input.Name),
+		TokenHash: hashToken(token),
+		Prefix:    token[:len(AccessTokenPrefix)+6],
+		Scopes:    strings.Join(scopes, " "),
+		ExpiresAt: &expiresAt,
+	}
+
+	err = s.tokenRepo.Create(ctx, pat)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dtos.CreatedAccessToken{
+		AccessToken: toAccessTokenDTO(pat),
+		Token:       token,
+	}, nil
+}
+
+func (s *AccessTokenService) List(ctx context.Context, userID uint) ([]dtos.AccessToken, error) {
+	tokens, err := s.tokenRepo.GetAllByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]dtos.AccessToken, 0, len(tokens))
+	for i := range tokens {
+		result = append(result, toAccessTokenDTO(&tokens[i]))
+	}
+
+	return result, nil
+}
+
+func (s *AccessTokenService) Revoke(ctx context.Context, userID, tokenID uint) error {
+	deleted, err := s.tokenRepo.DeleteForUser(ctx, userID, tokenID)
+	if err != nil {
+		return err
+	}
+
+	if deleted == 0 {
+		return ErrAccessTokenNotFound
+	}
+
+	return nil
+}
+
+// RevokeAll deletes every token of the user, e.g. after a password change
+func (s *AccessTokenService) RevokeAll(ctx context.Context, userID uint) error {
+	return s.tokenRepo.DeleteAllByUser(ctx, userID)
+}
+
+// Authenticate resolves a presented token to its user and scopes
+func (s *AccessTokenService) Authenticate(ctx context.Context, token string) (*models.User, []string, error) {
+	pat, err := s.tokenRepo.FindTokenByHash(ctx, hashToken(token))
+	if err != nil {
+		return nil, nil, ErrInvalidAccessToken
+	}
+
+	now := time.Now()
+	if pat.ExpiresAt != nil && pat.ExpiresAt.Before(now) {
+		return nil, nil, ErrInvalidAccessToken
+	}
+
+	user, err := s.userRepo.GetUserByID(ctx, pat.UserID)
+	if err != nil {
+		return nil, nil, ErrInvalidAccessToken
+	}
+
+	if user.Disabled {
+		return nil, nil, ErrUserDisabled
+	}
+
+	if pat.LastUsedAt == nil || now.Sub(*pat.LastUsedAt) > accessTokenTouchInterval {
+		err = s.tokenRepo.TouchLastUsed(ctx, pat.ID, now)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return user, pat.ScopeList(), nil
+}
+
+func toAccessTokenDTO(t *models.PersonalAccessToken) dtos.AccessToken {
+	return dtos.AccessToken{
+		ID:         t.ID,
+		Name:       t.Name,
+		Prefix:     t.Prefix,
+		Scopes:     t.ScopeList(),
+		CreatedAt:  t.CreatedAt,
+		ExpiresAt:  t.ExpiresAt,
+		LastUsedAt: t.LastUsedAt,
+	}
+}
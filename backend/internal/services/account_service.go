@@ -0,0 +1,132 @@
+package services
+
+import (
+	"context"
+
+	"github.com/georgiev098/film-manager/backend/internal/audit"
+	"github.com/georgiev098/film-manager/backend/internal/password"
+	"github.com/georgiev098/film-manager/backend/internal/repositories"
+	"gorm.io/gorm"
+)
+
+// AccountService deletes a user together with everything they own
+type AccountService struct {
+	db        *gorm.DB
+	audit     *audit.Service
+	passwords *password.Hasher
+}
+
+func NewAccountService(db *gorm.DB, audit *audit.Service, passwords *password.Hasher) *AccountService {
+	return &AccountService{
+		db:        db,
+		audit:     audit,
+		passwords: passwords,
+	}
+}
+
+// DeleteAccount re-authenticates the user and deletes their gear, film,
+// shoots, archive, credentials and the user in one transaction. The user row is
+// removed for good, so the address can be used for a new account.
+func (s *AccountService) DeleteAccount(ctx context.Context, userID uint, reauth Reauth) error {
+	user, err := repositories.NewUserRepo(s.db).GetUserByID(ctx, userID)
+	if err != nil {
+		return notFound(err, ErrUserNotFound)
+	}
+
+	err = reauth.verify(s.passwords, user)
+	if err != nil {
+		return err
+	}
+
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// The audit events of the deletes commit or roll back with them
+		txAudit := s.audit.WithDB(tx)
+
+		// Only deleting, so the services need no item quota
+		err := NewCameraService(repositories.NewCameraRepo(tx), nil, txAudit).DeleteAllByUser(ctx, userID, userID)
+		if err != nil {
+			return err
+		}
+
+		err = NewLensService(repositories.NewLensRepo(tx), nil, txAudit).DeleteAllByUser(ctx, userID, userID)
+		if err != nil {
+			return err
+		}
+
+		// Prints, frames, shoots and rolls reference each other, kits,
+		// cameras, film stocks and archive locations, so they go first
+		err = repositories.NewPrintRepo(tx).PurgeAllByUserID(ctx, userID)
+		if err != nil {
+			return err
+		}
+
+		err = repositories.NewFrameRepo(tx).PurgeAllByUserID(ctx, userID)
+		if err != nil {
+			return err
+		}
+
+		err = repositories.NewShootRepo(tx).PurgeAllByUserID(ctx, userID)
+		if err != nil {
+			return err
+		}
+
+		err = repositories.NewKitRepo(tx).PurgeAllByUserID(ctx, userID)
+		if err != nil {
+			return err
+		}
+
+		err = repositories.NewRollRepo(tx).PurgeAllByUserID(ctx, userID)
+		if err != nil {
+			return err
+		}
+
+		err = repositories.NewFilmStockRepo(tx).PurgeAllByUserID(ctx, userID)
+		if err != nil {
+			return err
+		}
+
+		_, err = repositories.NewArchiveLocationRepo(tx).PurgeAllByUserID(ctx, userID)
+		if err != nil {
+			return err
+		}
+
+		// The services above record the deletes in the audit log. Gear
+		// references the user, so the rows are then removed for good.
+		err = repositories.NewCameraRepo(tx).PurgeAllByUserID(ctx, userID)
+		if err != nil {
+			return err
+		}
+
+		err = repositories.NewLensRepo(tx).PurgeAllByUserID(ctx, userID)
+		if err != nil {
+			return err
+		}
+
+		err = repositories.CreateRefreshTokenRepository(tx).RevokeAllByUser(ctx, userID)
+		if err != nil {
+			return err
+		}
+
+		err = repositories.NewPersonalAccessTokenRepo(tx).DeleteAllByUser(ctx, userID)
+		if err != nil {
+			return err
+		}
+
+		err = repositories.NewRecoveryCodeRepo(tx).DeleteAllByUser(ctx, userID)
+		if err != nil {
+			return err
+		}
+
+		err = repositories.NewUserIdentityRepo(tx).DeleteAllByUser(ctx, userID)
+		if err != nil {
+			return err
+		}
+
+		err = repositories.NewPasswordResetRepo(tx).InvalidateAllByUser(ctx, userID)
+		if err != nil {
+			return err
+		}
+
+		return repositories.NewUserRepo(tx).DeleteUser(ctx, user)
+	})
+}
@@ -0,0 +1,144 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"path/filepath"
+	"testing"
+
+	"github.com/georgiev098/film-manager/backend/internal/audit"
+	"github.com/georgiev098/film-manager/backend/internal/dtos"
+	"github.com/georgiev098/film-manager/backend/internal/models"
+	"github.com/georgiev098/film-manager/backend/internal/password"
+	"github.com/georgiev098/film-manager/backend/internal/repositories"
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestDeleteAccountRemovesEverything(t *testing.T) {
+	ctx := context.Background()
+
+	// Foreign keys on, like MySQL, so rows left pointing at the user fail the delete
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")+"?_pragma=foreign_keys(1)"), &gorm.Config{
+		Logger: logger.Discard,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = db.AutoMigrate(&models.User{}, &models.Camera{}, &models.Lens{}, &models.FilmStock{}, &models.Roll{}, &models.Kit{}, &models.Shoot{}, &models.ArchiveLocation{}, &models.Frame{}, &models.Print{}, &models.RefreshToken{}, &models.PasswordResetToken{}, &models.RecoveryCode{}, &models.PersonalAccessToken{}, &models.UserIdentity{}, &models.AuditEvent{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	passwords := password.NewHasher(password.DefaultParams)
+	hash, err := passwords.Hash("correct horse battery staple")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user := &models.User{Email: "user@example.com", FirstName: "Ansel", LastName: "Adams", Role: models.RoleUser, PasswordHash: hash}
+	if err := db.Create(user).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	cameras := []models.Camera{
+		{Brand: "Nikon", CameraModel: "FM2", CameraFormat: models.Format35mm, UserID: user.ID},
+		{Brand: "Mamiya", CameraModel: "RB67", CameraFormat: models.Format120mm, UserID: user.ID},
+	}
+	if err := db.Create(&cameras).Error; err != nil {
+		t.Fatal(err)
+	}
+	// Deleted earlier, still in the table
+	if err := db.Delete(&cameras[1]).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	lens := &models.Lens{Manufacturer: "Nikon", LensType: models.LensAnalog, FocalLengthMin: 50, FocalLengthMax: 50, MinApertureStr: "f/16", MaxApertureStr: "f/1.4", Mount: "F-mount", UserID: user.ID}
+	if err := db.Create(lens).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	stock := &models.FilmStock{Manufacturer: "Kodak", Name: "Tri-X", ISO: 400, Format: models.Format35mm, Process: models.ProcessBW, UserID: user.ID}
+	if err := db.Create(stock).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	roll := &models.Roll{FilmStockID: stock.ID, CameraID: &cameras[0].ID, Status: models.RollLoaded, UserID: user.ID}
+	if err := NewRollService(db).CreateRoll(ctx, roll); err != nil {
+		t.Fatal(err)
+	}
+
+	box := &models.ArchiveLocation{Kind: models.ArchiveBox, Label: "1", UserID: user.ID}
+	if err := db.Create(box).Error; err != nil {
+		t.Fatal(err)
+	}
+	binder := &models.ArchiveLocation{Kind: models.ArchiveBinder, Label: "1", ParentID: &box.ID, UserID: user.ID}
+	if err := db.Create(binder).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	frame := &models.Frame{RollID: roll.ID, Number: 1, UserID: user.ID}
+	if err := db.Create(frame).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	print := &models.Print{FrameID: frame.ID, Paper: "Ilford MGFB", PaperSize: "8x10in", ExposureSeconds: 12, UserID: user.ID}
+	if err := NewPrintService(db).CreatePrint(ctx, print); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewPrintService(db).Reprint(ctx, print.ID, user.ID, dtos.Reprint{}); err != nil {
+		t.Fatal(err)
+	}
+
+	kit, err := NewKitService(db).CreateKit(ctx, user.ID, dtos.KitRequest{Name: "Street", CameraIDs: []uint{cameras[0].ID}, LensIDs: []uint{lens.ID}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	shoot := &models.Shoot{Title: "Old town", Date: "2026-06-21", Latitude: 42.7, Longitude: 23.3, KitID: &kit.ID, UserID: user.ID}
+	if err := NewShootService(db, NewLightService()).CreateShoot(ctx, shoot); err != nil {
+		t.Fatal(err)
+	}
+
+	s := NewAccountService(db, audit.NewService(repositories.NewAuditRepo(db), log.New(io.Discard, "", 0)), passwords)
+
+	err = s.DeleteAccount(ctx, user.ID, Reauth{Password: "wrong"})
+	if !errors.Is(err, ErrWrongPassword) {
+		t.Fatalf("wrong password: got %v, want %v", err, ErrWrongPassword)
+	}
+
+	err = s.DeleteAccount(ctx, user.ID, Reauth{Password: "correct horse battery staple"})
+	if err != nil {
+		t.Fatalf("DeleteAccount: %v", err)
+	}
+
+	for _, model := range []any{&models.User{}, &models.Camera{}, &models.Lens{}, &models.FilmStock{}, &models.Roll{}, &models.Kit{}, &models.Shoot{}, &models.ArchiveLocation{}, &models.Frame{}, &models.Print{}} {
+		var count int64
+		if err := db.Unscoped().Model(model).Count(&count).Error; err != nil {
+			t.Fatal(err)
+		}
+		if count != 0 {
+			t.Errorf("%T: %d rows left", model, count)
+		}
+	}
+
+	var links int64
+	if err := db.Table("kit_cameras").Count(&links).Error; err != nil {
+		t.Fatal(err)
+	}
+	if links != 0 {
+		t.Errorf("kit_cameras: %d rows left", links)
+	}
+
+	var events int64
+	if err := db.Model(&models.AuditEvent{}).Where("action IN ?", []string{models.AuditCameraDeleteAll, models.AuditLensDeleteAll}).Count(&events).Error; err != nil {
+		t.Fatal(err)
+	}
+	if events != 2 {
+		t.Errorf("got %d gear delete audit events, want 2", events)
+	}
+}
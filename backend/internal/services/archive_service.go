@@ -0,0 +1,351 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/georgiev098/film-manager/backend/internal/apperrors"
+	"github.com/georgiev098/film-manager/backend/internal/dtos"
+	"github.com/georgiev098/film-manager/backend/internal/models"
+	"github.com/georgiev098/film-manager/backend/internal/repositories"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrArchiveLocationNotFound is also returned for another user's location
+	ErrArchiveLocationNotFound = apperrors.NotFound("archive location not found")
+	ErrArchiveParent           = apperrors.Validation("a binder goes in a box, a page in a binder and a strip on a page; boxes stand on their own")
+	ErrArchiveNotEmpty         = apperrors.Conflict("archive location is not empty")
+	ErrNotABinder              = apperrors.Validation("archive location is not a binder")
+	ErrFrameNotFiled           = apperrors.NotFound("the roll of this frame is not filed yet")
+)
+
+// archiveParents is the kind of location each kind is filed in, and whether
+// it may also stand on its own
+var archiveParents = map[models.ArchiveKind]struct {
+	kind     models.ArchiveKind
+	optional bool
+}{
+	models.ArchiveBox:    {"", true},
+	models.ArchiveBinder: {models.ArchiveBox, true},
+	models.ArchivePage:   {models.ArchiveBinder, false},
+	models.ArchiveStrip:  {models.ArchivePage, false},
+}
+
+// ArchivePlace is one step of the way to a negative
+type ArchivePlace struct {
+	ID       uint               `json:"id"`
+	Kind     models.ArchiveKind `json:"kind"`
+	Label    string             `json:"label"`
+	Position int                `json:"position"`
+}
+
+// FrameLocation tells where the negative of a frame is filed
+type FrameLocation struct {
+	Frame *models.Frame  `json:"frame"`
+	Roll  *models.Roll   `json:"roll"`
+	Path  []ArchivePlace `json:"path"`  // outermost first, down to the page or strip
+	Where string         `json:"where"` // e.g. "box 2 / binder 1998 / page 12 / strip 3, frame 14"
+}
+
+// BinderIndex is what a binder holds, page by page, for printing and
+// keeping with the binder
+type BinderIndex struct {
+	Binder    *models.ArchiveLocation
+	Box       *models.ArchiveLocation // nil for a loose binder
+	Pages     []IndexPage
+	PrintedAt time.Time
+}
+
+type IndexPage struct {
+	Page   models.ArchiveLocation
+	Rolls  []models.Roll
+	Strips []IndexStrip
+}
+
+type IndexStrip struct {
+	Strip  models.ArchiveLocation
+	Frames []models.Frame
+}
+
+type ArchiveService struct {
+	db *gorm.DB
+}
+
+func NewArchiveService(db *gorm.DB) *ArchiveService {
+	return &ArchiveService{
+		db: db,
+	}
+}
+
+func (s *ArchiveService) CreateLocation(ctx context.Context, location *models.ArchiveLocation) error {
+	err := s.checkParent(ctx, location.Kind, location.ParentID, location.UserID)
+	if err != nil {
+		return err
+	}
+
+	return repositories.NewArchiveLocationRepo(s.db).CreateLocation(ctx, location)
+}
+
+func (s *ArchiveService) GetAllForUser(ctx context.Context, userID uint) ([]models.ArchiveLocation, error) {
+	return repositories.NewArchiveLocationRepo(s.db).GetAllByUserID(ctx, userID)
+}
+
+func (s *ArchiveService) GetLocationByID(ctx context.Context, locationID uint, userID uint) (*models.ArchiveLocation, error) {
+	location, err := repositories.NewArchiveLocationRepo(s.db).GetLocationByID(ctx, locationID)
+	if err != nil {
+		return nil, notFound(err, ErrArchiveLocationNotFound)
+	}
+
+	if location.UserID != userID {
+		return nil, ErrArchiveLocationNotFound
+	}
+
+	return location, nil
+}
+
+func (s *ArchiveService) UpdateLocation(ctx context.Context, locationID uint, userID uint, input dtos.ArchiveLocationUpdate) (*models.ArchiveLocation, error) {
+	location, err := s.GetLocationByID(ctx, locationID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	updates := map[string]any{}
+
+	if input.Label != nil {
+		updates["label"] = input.Label
+	}
+	if input.Position != nil {
+		updates["position"] = input.Position
+	}
+	if input.ParentID != nil {
+		parentID := input.ParentID
+		if *parentID == 0 {
+			parentID = nil
+		}
+
+		err = s.checkParent(ctx, location.Kind, parentID, userID)
+		if err != nil {
+			return nil, err
+		}
+		updates["parent_id"] = parentID
+	}
+	if input.Notes != nil {
+		updates["notes"] = input.Notes
+	}
+
+	if len(updates) == 0 {
+		return location, nil
+	}
+
+	err = repositories.NewArchiveLocationRepo(s.db).UpdateLocation(ctx, location, updates)
+	if err != nil {
+		return nil, err
+	}
+
+	return location, nil
+}
+
+// DeleteLocation deletes an empty location. Whatever is filed in it has to
+// be moved or deleted first, so no negative goes missing from the index.
+func (s *ArchiveService) DeleteLocation(ctx context.Context, locationID uint, userID uint) error {
+	location, err := s.GetLocationByID(ctx, locationID, userID)
+	if err != nil {
+		return err
+	}
+
+	repo := repositories.NewArchiveLocationRepo(s.db)
+
+	inUse, err := repo.InUse(ctx, location.ID)
+	if err != nil {
+		return err
+	}
+	if inUse {
+		return ErrArchiveNotEmpty
+	}
+
+	return repo.DeleteLocation(ctx, location)
+}
+
+// FindFrame walks from a frame up to the box its negative is filed in
+func (s *ArchiveService) FindFrame(ctx context.Context, frameID uint, userID uint) (*FrameLocation, error) {
+	frame, err := NewFrameService(s.db).GetFrameByID(ctx, frameID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	roll, err := getRoll(ctx, repositories.NewRollRepo(s.db), frame.RollID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if roll.ArchiveLocationID == nil {
+		return nil, ErrFrameNotFiled
+	}
+
+	// Start at the strip when the frame is on one, else at the roll's page
+	id := roll.ArchiveLocationID
+	if frame.StripID != nil {
+		id = frame.StripID
+	}
+
+	var path []ArchivePlace
+	for id != nil {
+		location, err := s.GetLocationByID(ctx, *id, userID)
+		if err != nil {
+			return nil, err
+		}
+
+		path = append([]ArchivePlace{{
+			ID:       location.ID,
+			Kind:     location.Kind,
+			Label:    location.Label,
+			Position: location.Position,
+		}}, path...)
+
+		id = location.ParentID
+	}
+
+	steps := make([]string, 0, len(path))
+	for _, p := range path {
+		steps = append(steps, string(p.Kind)+" "+p.Label)
+	}
+
+	return &FrameLocation{
+		Frame: frame,
+		Roll:  roll,
+		Path:  path,
+		Where: fmt.Sprintf("%s, frame %d", strings.Join(steps, " / "), frame.Number),
+	}, nil
+}
+
+// BinderIndex collects the pages of a binder with the rolls filed on them
+// and the frames on each strip
+func (s *ArchiveService) BinderIndex(ctx context.Context, binderID uint, userID uint) (*BinderIndex, error) {
+	binder, err := s.GetLocationByID(ctx, binderID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if binder.Kind != models.ArchiveBinder {
+		return nil, ErrNotABinder
+	}
+
+	index := &BinderIndex{
+		Binder:    binder,
+		Pages:     []IndexPage{},
+		PrintedAt: time.Now(),
+	}
+
+	if binder.ParentID != nil {
+		index.Box, err = s.GetLocationByID(ctx, *binder.ParentID, userID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	repo := repositories.NewArchiveLocationRepo(s.db)
+
+	pages, err := repo.GetChildren(ctx, binder.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(pages) == 0 {
+		return index, nil
+	}
+
+	pageIDs := make([]uint, 0, len(pages))
+	for _, p := range pages {
+		pageIDs = append(pageIDs, p.ID)
+	}
+
+	strips, err := repo.GetChildren(ctx, pageIDs...)
+	if err != nil {
+		return nil, err
+	}
+
+	rolls, err := repositories.NewRollRepo(s.db).GetFiledOn(ctx, pageIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	var frames []models.Frame
+	if len(strips) > 0 {
+		stripIDs := make([]uint, 0, len(strips))
+		for _, st := range strips {
+			stripIDs = append(stripIDs, st.ID)
+		}
+
+		frames, err = repositories.NewFrameRepo(s.db).GetAllByStripIDs(ctx, stripIDs)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, page := range pages {
+		ip := IndexPage{Page: page}
+
+		for _, roll := range rolls {
+			if *roll.ArchiveLocationID == page.ID {
+				ip.Rolls = append(ip.Rolls, roll)
+			}
+		}
+
+		for _, strip := range strips {
+			if *strip.ParentID != page.ID {
+				continue
+			}
+
+			is := IndexStrip{Strip: strip}
+			for _, f := range frames {
+				if *f.StripID == strip.ID {
+					is.Frames = append(is.Frames, f)
+				}
+			}
+			ip.Strips = append(ip.Strips, is)
+		}
+
+		index.Pages = append(index.Pages, ip)
+	}
+
+	return index, nil
+}
+
+// checkParent makes sure a location of this kind can be filed in parentID
+func (s *ArchiveService) checkParent(ctx context.Context, kind models.ArchiveKind, parentID *uint, userID uint) error {
+	rule := archiveParents[kind]
+
+	if parentID == nil {
+		if !rule.optional {
+			return ErrArchiveParent
+		}
+		return nil
+	}
+
+	parent, err := s.GetLocationByID(ctx, *parentID, userID)
+	if err != nil {
+		return err
+	}
+
+	if parent.Kind != rule.kind {
+		return ErrArchiveParent
+	}
+
+	return nil
+}
+
+// checkPage makes sure locationID is one of the user's pages, for filing a roll
+func (s *ArchiveService) checkPage(ctx context.Context, locationID uint, userID uint) error {
+	page, err := s.GetLocationByID(ctx, locationID, userID)
+	if err != nil {
+		return err
+	}
+
+	if page.Kind != models.ArchivePage {
+		return ErrRollNotOnPage
+	}
+
+	return nil
+}
@@ -0,0 +1,124 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/georgiev098/film-manager/backend/internal/dtos"
+	"github.com/georgiev098/film-manager/backend/internal/models"
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestFindFrame(t *testing.T) {
+	ctx := context.Background()
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{
+		Logger: logger.Discard,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = db.AutoMigrate(&models.User{}, &models.Camera{}, &models.FilmStock{}, &models.Roll{}, &models.ArchiveLocation{}, &models.Frame{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user := &models.User{Email: "user@example.com", FirstName: "Ansel", LastName: "Adams", Role: models.RoleUser}
+	if err := db.Create(user).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	stock := &models.FilmStock{Manufacturer: "Kodak", Name: "Tri-X", ISO: 400, Format: models.Format35mm, Process: models.ProcessBW, UserID: user.ID}
+	if err := db.Create(stock).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	rolls := NewRollService(db)
+	archive := NewArchiveService(db)
+	frames := NewFrameService(db)
+
+	roll := &models.Roll{FilmStockID: stock.ID, Status: models.RollExposed, UserID: user.ID}
+	if err := rolls.CreateRoll(ctx, roll); err != nil {
+		t.Fatal(err)
+	}
+
+	frame := &models.Frame{RollID: roll.ID, Number: 14, UserID: user.ID}
+	if err := frames.CreateFrame(ctx, frame); err != nil {
+		t.Fatal(err)
+	}
+
+	location := func(kind models.ArchiveKind, label string, parent *models.ArchiveLocation) *models.ArchiveLocation {
+		t.Helper()
+
+		l := &models.ArchiveLocation{Kind: kind, Label: label, UserID: user.ID}
+		if parent != nil {
+			l.ParentID = &parent.ID
+		}
+		if err := archive.CreateLocation(ctx, l); err != nil {
+			t.Fatalf("create %s %s: %v", kind, label, err)
+		}
+		return l
+	}
+
+	box := location(models.ArchiveBox, "2", nil)
+	binder := location(models.ArchiveBinder, "1998", box)
+	page := location(models.ArchivePage, "12", binder)
+	strip := location(models.ArchiveStrip, "3", page)
+
+	err = archive.CreateLocation(ctx, &models.ArchiveLocation{Kind: models.ArchiveStrip, Label: "1", ParentID: &binder.ID, UserID: user.ID})
+	if !errors.Is(err, ErrArchiveParent) {
+		t.Errorf("strip in a binder: got %v, want %v", err, ErrArchiveParent)
+	}
+
+	_, err = archive.FindFrame(ctx, frame.ID, user.ID)
+	if !errors.Is(err, ErrFrameNotFiled) {
+		t.Errorf("before filing: got %v, want %v", err, ErrFrameNotFiled)
+	}
+
+	_, err = rolls.UpdateRoll(ctx, roll.ID, user.ID, dtos.RollUpdate{ArchiveLocationID: &page.ID})
+	if !errors.Is(err, ErrRollNotDeveloped) {
+		t.Errorf("filing an undeveloped roll: got %v, want %v", err, ErrRollNotDeveloped)
+	}
+
+	developed := models.RollDeveloped
+	_, err = rolls.UpdateRoll(ctx, roll.ID, user.ID, dtos.RollUpdate{Status: &developed, ArchiveLocationID: &page.ID})
+	if err != nil {
+		t.Fatalf("filing: %v", err)
+	}
+
+	found, err := archive.FindFrame(ctx, frame.ID, user.ID)
+	if err != nil {
+		t.Fatalf("FindFrame: %v", err)
+	}
+	if want := "box 2 / binder 1998 / page 12, frame 14"; found.Where != want {
+		t.Errorf("on the page: got %q, want %q", found.Where, want)
+	}
+
+	_, err = frames.UpdateFrame(ctx, frame.ID, user.ID, dtos.FrameUpdate{StripID: &strip.ID})
+	if err != nil {
+		t.Fatalf("filing the frame: %v", err)
+	}
+
+	found, err = archive.FindFrame(ctx, frame.ID, user.ID)
+	if err != nil {
+		t.Fatalf("FindFrame: %v", err)
+	}
+	if want := "box 2 / binder 1998 / page 12 / strip 3, frame 14"; found.Where != want {
+		t.Errorf("on the strip: got %q, want %q", found.Where, want)
+	}
+
+	err = archive.DeleteLocation(ctx, page.ID, user.ID)
+	if !errors.Is(err, ErrArchiveNotEmpty) {
+		t.Errorf("deleting a page in use: got %v, want %v", err, ErrArchiveNotEmpty)
+	}
+
+	_, err = archive.FindFrame(ctx, frame.ID, user.ID+1)
+	if !errors.Is(err, ErrFrameNotFound) {
+		t.Errorf("another user: got %v, want %v", err, ErrFrameNotFound)
+	}
+}
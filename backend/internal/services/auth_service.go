@@ -5,26 +5,54 @@ import (
 	"crypto/rand"
 	"crypto/sha256"
 	"encoding/hex"
-	"errors"
+	"log"
+	"strconv"
 	"time"
 
+	"github.com/georgiev098/film-manager/backend/internal/apperrors"
+	"github.com/georgiev098/film-manager/backend/internal/audit"
+	"github.com/georgiev098/film-manager/backend/internal/dtos"
+	"github.com/georgiev098/film-manager/backend/internal/helpers"
+	"github.com/georgiev098/film-manager/backend/internal/jwtkeys"
 	"github.com/georgiev098/film-manager/backend/internal/models"
 	"github.com/georgiev098/film-manager/backend/internal/repositories"
 	"github.com/golang-jwt/jwt/v5"
 )
 
+var (
+	ErrInvalidRefreshToken = apperrors.Unauthorized("invalid or expired refresh token")
+	ErrRefreshTokenReused  = apperrors.Unauthorized("refresh token reused, please log in again")
+	ErrSessionNotFound     = apperrors.NotFound("session not found")
+)
+
+// ClientInfo describes the device a session was started or refreshed from
+type ClientInfo struct {
+	UserAgent string
+	IPAddress string
+}
+
 type AuthService struct {
 	refreshTokenRepo *repositories.RefreshTokenRepository
-	accessSecret     string
+	userRepo         *repositories.UserRepository
+	audit            *audit.Service
+	logger           *log.Logger
+	keys             *jwtkeys.KeySet
+	issuer           string
+	audience         string
 	accessTTL        time.Duration
 	refreshTTL       time.Duration
 }
 
 // Constructor
-func NewAuthService(repo *repositories.RefreshTokenRepository, accessSecret string, accessTTL, refreshTTL time.Duration) *AuthService {
+func NewAuthService(repo *repositories.RefreshTokenRepository, userRepo *repositories.UserRepository, audit *audit.Service, logger *log.Logger, keys *jwtkeys.KeySet, issuer, audience string, accessTTL, refreshTTL time.Duration) *AuthService {
 	return &AuthService{
 		refreshTokenRepo: repo,
-		accessSecret:     accessSecret,
+		userRepo:         userRepo,
+		audit:            audit,
+		logger:           logger,
+		keys:             keys,
+		issuer:           issuer,
+		audience:         audience,
 		accessTTL:        accessTTL,
 		refreshTTL:       refreshTTL,
 	}
@@ -32,23 +60,32 @@ func NewAuthService(repo *repositories.RefreshTokenRepository, accessSecret stri
 
 // Custom JWT claims
 type AccessClaims struct {
-	UserID uint `json:"user_id"`
+	UserID        uint        `json:"user_id"`
+	Role          models.Role `json:"role"`
+	EmailVerified bool        `json:"email_verified"`
+	// When the session was started by logging in; kept across refreshes
+	AuthTime *jwt.NumericDate `json:"auth_time,omitempty"`
 	jwt.RegisteredClaims
 }
 
-// GenerateAccessToken creates a JWT access token for a user
-func (s *AuthService) GenerateAccessToken(userID uint) (string, error) {
+// GenerateAccessToken creates a JWT access token for a user whose session
+// was started at authTime
+func (s *AuthService) GenerateAccessToken(user *models.User, authTime time.Time) (string, error) {
 	claims := AccessClaims{
-		UserID: userID,
+		UserID:        user.ID,
+		Role:          user.Role,
+		EmailVerified: user.EmailVerifiedAt != nil,
+		AuthTime:      jwt.NewNumericDate(authTime),
 		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    s.issuer,
+			Subject:   strconv.FormatUint(uint64(user.ID), 10),
+			Audience:  jwt.ClaimStrings{s.audience},
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(s.accessTTL)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-
-	signedToken, err := token.SignedString([]byte(s.accessSecret))
+	signedToken, err := s.keys.Sign(claims)
 	if err != nil {
 		return "", err
 	}
@@ -57,21 +94,16 @@ func (s *AuthService) GenerateAccessToken(userID uint) (string, error) {
 }
 
 // GenerateRefreshToken creates a random refresh token string and returns a DB-ready model
-func (s *AuthService) GenerateRefreshToken(userID uint) (string, *models.RefreshToken, error) {
-	// Generate random 64-byte token
-	raw := make([]byte, 64)
-	_, err := rand.Read(raw)
+func (s *AuthService) GenerateRefreshToken(userID uint, familyID string) (string, *models.RefreshToken, error) {
+	tokenString, tokenHash, err := newOpaqueToken()
 	if err != nil {
 		return "", nil, err
 	}
 
-	tokenString := hex.EncodeToString(raw)
-	hash := sha256.Sum256([]byte(tokenString))
-	tokenHash := hex.EncodeToString(hash[:])
-
 	refreshToken := &models.RefreshToken{
 		UserID:    userID,
 		TokenHash: tokenHash,
+		FamilyID:  familyID,
 		ExpiresAt: time.Now().Add(s.refreshTTL),
 		Revoked:   false,
 	}
@@ -79,18 +111,40 @@ func (s *AuthService) GenerateRefreshToken(userID uint) (string, *models.Refresh
 	return tokenString, refreshToken, nil
 }
 
-// Login handles issuing tokens for a verified user
-func (s *AuthService) Login(ctx context.Context, userID uint) (accessToken string, refreshToken string, err error) {
-	accessToken, err = s.GenerateAccessToken(userID)
+// Login handles issuing tokens for a verified user, starting a new token family
+func (s *AuthService) Login(ctx context.Context, user *models.User, client ClientInfo) (accessToken string, refreshToken string, err error) {
+	familyID, err := newRandomID()
+	if err != nil {
+		return "", "", err
+	}
+
+	accessToken, refreshToken, err = s.issueTokens(ctx, user, familyID, client, time.Now())
+	if err != nil {
+		return "", "", err
+	}
+
+	s.audit.Record(ctx, audit.Entry{ActorID: user.ID, UserID: user.ID, Action: models.AuditLogin})
+
+	return accessToken, refreshToken, nil
+}
+
+// issueTokens creates an access token and a refresh token in the given family
+func (s *AuthService) issueTokens(ctx context.Context, user *models.User, familyID string, client ClientInfo, sessionStartedAt time.Time) (accessToken string, refreshToken string, err error) {
+	accessToken, err = s.GenerateAccessToken(user, sessionStartedAt)
 	if err != nil {
 		return "", "", err
 	}
 
-	refreshToken, tokenModel, err := s.GenerateRefreshToken(userID)
+	refreshToken, tokenModel, err := s.GenerateRefreshToken(user.ID, familyID)
 	if err != nil {
 		return "", "", err
 	}
 
+	tokenModel.UserAgent = helpers.Truncate(client.UserAgent, 255)
+	tokenModel.IPAddress = helpers.Truncate(client.IPAddress, 45)
+	tokenModel.SessionStartedAt = sessionStartedAt
+	tokenModel.LastUsedAt = time.Now()
+
 	err = s.refreshTokenRepo.Create(ctx, tokenModel)
 	if err != nil {
 		return "", "", err
@@ -100,37 +154,203 @@ func (s *AuthService) Login(ctx context.Context, userID uint) (accessToken strin
 }
 
 // Refresh rotates a refresh token and returns new tokens
-func (s *AuthService) Refresh(ctx context.Context, oldToken string) (newAccessToken string, newRefreshToken string, err error) {
-	hash := sha256.Sum256([]byte(oldToken))
-	hashHex := hex.EncodeToString(hash[:])
+func (s *AuthService) Refresh(ctx context.Context, oldToken string, client ClientInfo) (newAccessToken string, newRefreshToken string, err error) {
+	hashHex := hashToken(oldToken)
 
 	rt, err := s.refreshTokenRepo.FindTokenByHash(ctx, hashHex)
 	if err != nil {
-		return "", "", errors.New("invalid refresh token")
+		return "", "", notFound(err, ErrInvalidRefreshToken)
 	}
 
-	if rt.Revoked || rt.ExpiresAt.Before(time.Now()) {
-		return "", "", errors.New("refresh token expired or revoked")
+	if rt.Revoked {
+		return "", "", s.rejectRevoked(ctx, rt)
 	}
 
-	// Rotation: revoke old token
-	if err := s.refreshTokenRepo.Revoke(ctx, rt.ID); err != nil {
+	if rt.ExpiresAt.Before(time.Now()) {
+		return "", "", ErrInvalidRefreshToken
+	}
+
+	// Rotation: revoke old token. Losing the race to a concurrent refresh
+	// with the same token counts as reuse too, losing it to a logout doesn't.
+	rotated, err := s.refreshTokenRepo.RevokeIfActive(ctx, rt.ID)
+	if err != nil {
 		return "", "", err
 	}
+	if !rotated {
+		rt, err = s.refreshTokenRepo.FindTokenByHash(ctx, hashHex)
+		if err != nil {
+			return "", "", notFound(err, ErrInvalidRefreshToken)
+		}
+		return "", "", s.rejectRevoked(ctx, rt)
+	}
+
+	// Reload the user so role changes and disabled accounts take effect
+	user, err := s.userRepo.GetUserByID(ctx, rt.UserID)
+	if err != nil {
+		return "", "", err
+	}
+
+	if user.Disabled {
+		return "", "", ErrUserDisabled
+	}
+
+	// Tokens issued before families existed start a new one
+	if rt.FamilyID == "" {
+		return s.Login(ctx, user, client)
+	}
 
-	// Issue new tokens using correct user ID
-	return s.Login(ctx, rt.UserID)
+	startedAt := rt.SessionStartedAt
+	if startedAt.IsZero() {
+		startedAt = rt.CreatedAt
+	}
+
+	// Issue new tokens in the same family
+	newAccessToken, newRefreshToken, err = s.issueTokens(ctx, user, rt.FamilyID, client, startedAt)
+	if err != nil {
+		return "", "", err
+	}
+
+	s.audit.Record(ctx, audit.Entry{ActorID: user.ID, UserID: user.ID, Action: models.AuditRefresh})
+
+	return newAccessToken, newRefreshToken, nil
+}
+
+// rejectRevoked answers a revoked token. Only a token revoked by rotation
+// means a copy is being replayed; one revoked by logout, a password reset or
+// an admin is just stale, e.g. in a browser tab left open.
+func (s *AuthService) rejectRevoked(ctx context.Context, rt *models.RefreshToken) error {
+	if rt.RotatedAt == nil {
+		return ErrInvalidRefreshToken
+	}
+
+	return s.handleReuse(ctx, rt)
+}
+
+// handleReuse is called when an already rotated token is presented. Either the
+// legitimate client or an attacker holds a stolen copy, so the whole family is
+// revoked and both have to log in again.
+func (s *AuthService) handleReuse(ctx context.Context, rt *models.RefreshToken) error {
+	s.logger.Printf("SECURITY: refresh token reuse detected for user %d, family %q, token %d; revoking family", rt.UserID, rt.FamilyID, rt.ID)
+	s.audit.Record(ctx, audit.Entry{UserID: rt.UserID, Action: models.AuditRefreshReuse})
+
+	if rt.FamilyID != "" {
+		if err := s.refreshTokenRepo.RevokeFamily(ctx, rt.FamilyID); err != nil {
+			return err
+		}
+	}
+
+	return ErrRefreshTokenReused
 }
 
 // Logout revokes a refresh token
 func (s *AuthService) Logout(ctx context.Context, refreshToken string) error {
-	hash := sha256.Sum256([]byte(refreshToken))
-	hashHex := hex.EncodeToString(hash[:])
+	hashHex := hashToken(refreshToken)
 
 	rt, err := s.refreshTokenRepo.FindTokenByHash(ctx, hashHex)
 	if err != nil {
 		return nil // Already gone, safe
 	}
 
-	return s.refreshTokenRepo.Revoke(ctx, rt.ID)
+	err = s.refreshTokenRepo.Revoke(ctx, rt.ID)
+	if err != nil {
+		return err
+	}
+
+	s.audit.Record(ctx, audit.Entry{ActorID: rt.UserID, UserID: rt.UserID, Action: models.AuditLogout})
+
+	return nil
+}
+
+// LogoutAll revokes every refresh token of a user (logout everywhere)
+func (s *AuthService) LogoutAll(ctx context.Context, userID uint) error {
+	err := s.refreshTokenRepo.RevokeAllByUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	s.audit.Record(ctx, audit.Entry{ActorID: userID, UserID: userID, Action: models.AuditLogoutAll})
+
+	return nil
+}
+
+// ListSessions returns the user's active sessions. currentToken is the raw
+// refresh token of the caller, if any, so their own session can be flagged.
+func (s *AuthService) ListSessions(ctx context.Context, userID uint, currentToken string) ([]dtos.Session, error) {
+	tokens, err := s.refreshTokenRepo.GetActiveByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	currentHash := ""
+	if currentToken != "" {
+		currentHash = hashToken(currentToken)
+	}
+
+	sessions := make([]dtos.Session, 0, len(tokens))
+	for _, t := range tokens {
+		// Tokens from before families existed cannot be revoked one by one
+		if t.FamilyID == "" {
+			continue
+		}
+
+		createdAt := t.SessionStartedAt
+		if createdAt.IsZero() {
+			createdAt = t.CreatedAt
+		}
+
+		sessions = append(sessions, dtos.Session{
+			ID:         t.FamilyID,
+			UserAgent:  t.UserAgent,
+			IPAddress:  t.IPAddress,
+			CreatedAt:  createdAt,
+			LastUsedAt: t.LastUsedAt,
+			ExpiresAt:  t.ExpiresAt,
+			Current:    t.TokenHash == currentHash,
+		})
+	}
+
+	return sessions, nil
+}
+
+// RevokeSession logs out a single session (token family) of the user
+func (s *AuthService) RevokeSession(ctx context.Context, userID uint, sessionID string) error {
+	revoked, err := s.refreshTokenRepo.RevokeFamilyForUser(ctx, userID, sessionID)
+	if err != nil {
+		return err
+	}
+
+	if revoked == 0 {
+		return ErrSessionNotFound
+	}
+
+	return nil
+}
+
+// newRandomID returns 128 random bits in hex, used for token families and MFA challenges
+func newRandomID() (string, error) {
+	raw := make([]byte, 16)
+	_, err := rand.Read(raw)
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(raw), nil
+}
+
+// newOpaqueToken generates a random 64-byte token for the client and the hash
+// we store. Used for refresh and password reset tokens.
+func newOpaqueToken() (token string, tokenHash string, err error) {
+	raw := make([]byte, 64)
+	_, err = rand.Read(raw)
+	if err != nil {
+		return "", "", err
+	}
+
+	token = hex.EncodeToString(raw)
+	return token, hashToken(token), nil
+}
+
+func hashToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
 }
@@ -0,0 +1,185 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/georgiev098/film-manager/backend/internal/audit"
+	"github.com/georgiev098/film-manager/backend/internal/jwtkeys"
+	"github.com/georgiev098/film-manager/backend/internal/models"
+	"github.com/georgiev098/film-manager/backend/internal/repositories"
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func newTestAuthService(t *testing.T) (*AuthService, *gorm.DB, *models.User) {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{
+		Logger: logger.Discard,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = db.AutoMigrate(&models.User{}, &models.RefreshToken{}, &models.AuditEvent{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user := &models.User{Email: "user@example.com", FirstName: "Ansel", LastName: "Adams", Role: models.RoleUser}
+	if err := db.Create(user).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	keys, err := jwtkeys.Generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	discard := log.New(io.Discard, "", 0)
+	userRepo := repositories.NewUserRepo(db)
+
+	s := NewAuthService(
+		repositories.CreateRefreshTokenRepository(db),
+		userRepo,
+		audit.NewService(repositories.NewAuditRepo(db), discard),
+		discard,
+		keys,
+		"film-manager", "film-manager",
+		15*time.Minute, 24*time.Hour,
+	)
+
+	return s, db, user
+}
+
+func TestAuthServiceRefresh(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		// prepare gets the refresh token of a fresh login and returns the one to present
+		prepare           func(t *testing.T, s *AuthService, db *gorm.DB, token string) string
+		wantErr           error
+		wantFamilyRevoked bool
+	}{
+		{
+			name:    "active token",
+			prepare: func(t *testing.T, s *AuthService, db *gorm.DB, token string) string { return token },
+		},
+		{
+			name:    "unknown token",
+			prepare: func(t *testing.T, s *AuthService, db *gorm.DB, token string) string { return "not-a-token" },
+			wantErr: ErrInvalidRefreshToken,
+		},
+		{
+			name: "expired token",
+			prepare: func(t *testing.T, s *AuthService, db *gorm.DB, token string) string {
+				err := db.Model(&models.RefreshToken{}).Where("token_hash = ?", hashToken(token)).
+					Update("expires_at", time.Now().Add(-time.Minute)).Error
+				if err != nil {
+					t.Fatal(err)
+				}
+				return token
+			},
+			wantErr: ErrInvalidRefreshToken,
+		},
+		{
+			name: "logged out token is stale, not reused",
+			prepare: func(t *testing.T, s *AuthService, db *gorm.DB, token string) string {
+				if err := s.Logout(ctx, token); err != nil {
+					t.Fatal(err)
+				}
+				return token
+			},
+			wantErr: ErrInvalidRefreshToken,
+		},
+		{
+			name: "rotated token is reuse",
+			prepare: func(t *testing.T, s *AuthService, db *gorm.DB, token string) string {
+				if _, _, err := s.Refresh(ctx, token, ClientInfo{}); err != nil {
+					t.Fatal(err)
+				}
+				return token
+			},
+			wantErr:           ErrRefreshTokenReused,
+			wantFamilyRevoked: true,
+		},
+		{
+			name: "disabled user",
+			prepare: func(t *testing.T, s *AuthService, db *gorm.DB, token string) string {
+				if err := db.Model(&models.User{}).Where("1 = 1").Update("disabled", true).Error; err != nil {
+					t.Fatal(err)
+				}
+				return token
+			},
+			wantErr: ErrUserDisabled,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, db, user := newTestAuthService(t)
+
+			_, token, err := s.Login(ctx, user, ClientInfo{UserAgent: "test"})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var first models.RefreshToken
+			if err := db.Where("token_hash = ?", hashToken(token)).First(&first).Error; err != nil {
+				t.Fatal(err)
+			}
+
+			presented := tt.prepare(t, s, db, token)
+
+			access, refresh, err := s.Refresh(ctx, presented, ClientInfo{UserAgent: "test"})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+
+			var active int64
+			err = db.Model(&models.RefreshToken{}).
+				Where("family_id = ? AND revoked = ?", first.FamilyID, false).
+				Count(&active).Error
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.wantFamilyRevoked && active != 0 {
+				t.Errorf("%d tokens of the family still active", active)
+			}
+
+			if tt.wantErr != nil {
+				return
+			}
+
+			if access == "" || refresh == "" || refresh == token {
+				t.Fatalf("got access %q, refresh %q", access, refresh)
+			}
+
+			var old, rotated models.RefreshToken
+			if err := db.First(&old, first.ID).Error; err != nil {
+				t.Fatal(err)
+			}
+			if !old.Revoked || old.RotatedAt == nil {
+				t.Errorf("old token revoked %v, rotated at %v", old.Revoked, old.RotatedAt)
+			}
+
+			if err := db.Where("token_hash = ?", hashToken(refresh)).First(&rotated).Error; err != nil {
+				t.Fatal(err)
+			}
+			if rotated.FamilyID != first.FamilyID {
+				t.Errorf("family %q, want %q", rotated.FamilyID, first.FamilyID)
+			}
+			if !rotated.SessionStartedAt.Equal(first.SessionStartedAt) {
+				t.Errorf("session started %v, want %v", rotated.SessionStartedAt, first.SessionStartedAt)
+			}
+		})
+	}
+}
@@ -2,27 +2,50 @@ package services
 
 import (
 	"context"
-	"errors"
 
+	"github.com/georgiev098/film-manager/backend/internal/apperrors"
+	"github.com/georgiev098/film-manager/backend/internal/audit"
 	"github.com/georgiev098/film-manager/backend/internal/dtos"
 	"github.com/georgiev098/film-manager/backend/internal/models"
 	"github.com/georgiev098/film-manager/backend/internal/repositories"
 	"gorm.io/gorm"
 )
 
+// ErrCameraNotFound is also returned for another user's camera, so IDs cannot be probed
+var ErrCameraNotFound = apperrors.NotFound("camera not found")
+
 type CameraService struct {
-	repo *repositories.CameraRepo
+	repo  *repositories.CameraRepo
+	quota *ItemQuota
+	audit *audit.Service
 }
 
-func NewCameraService(repo *repositories.CameraRepo) *CameraService {
+func NewCameraService(repo *repositories.CameraRepo, quota *ItemQuota, audit *audit.Service) *CameraService {
 	return &CameraService{
-		repo: repo,
+		repo:  repo,
+		quota: quota,
+		audit: audit,
 	}
 }
 
 func (s *CameraService) CreateCamera(ctx context.Context, camera *models.Camera) error {
-	// business logic if any
-	return s.repo.CreateCamera(ctx, camera)
+	err := s.quota.Create(ctx, camera.UserID, 1, func(tx *gorm.DB) error {
+		return repositories.NewCameraRepo(tx).CreateCamera(ctx, camera)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.audit.Record(ctx, audit.Entry{
+		ActorID:  camera.UserID,
+		UserID:   camera.UserID,
+		Action:   models.AuditCameraCreate,
+		Target:   "camera",
+		TargetID: camera.ID,
+		Details:  map[string]any{"after": camera},
+	})
+
+	return nil
 }
 
 func (s *CameraService) GetAllCameras(ctx context.Context) ([]models.Camera, error) {
@@ -39,12 +62,12 @@ func (s *CameraService) GetCameraByID(ctx context.Context, cameraID uint, userID
 
 	camera, err := s.repo.GetCameraByID(ctx, cameraID)
 	if err != nil {
-		return nil, err
+		return nil, notFound(err, ErrCameraNotFound)
 	}
 
 	// ownership check
 	if camera.UserID != userID {
-		return nil, gorm.ErrRecordNotFound
+		return nil, ErrCameraNotFound
 	}
 
 	return camera, nil
@@ -53,12 +76,12 @@ func (s *CameraService) GetCameraByID(ctx context.Context, cameraID uint, userID
 func (s *CameraService) UpdateCamera(ctx context.Context, cameraID uint, userID uint, input dtos.CameraUpdate) (*models.Camera, error) {
 	camera, err := s.repo.GetCameraByID(ctx, cameraID)
 	if err != nil {
-		return nil, err
+		return nil, notFound(err, ErrCameraNotFound)
 	}
 
 	// ownership check
 	if camera.UserID != userID {
-		return nil, errors.New("forbidden")
+		return nil, ErrCameraNotFound
 	}
 	// Build map for GORM Updates
 	updates := map[string]any{}
@@ -89,12 +112,23 @@ func (s *CameraService) UpdateCamera(ctx context.Context, cameraID uint, userID
 		return camera, nil // nothing to update
 	}
 
+	before := cameraColumns(camera)
+
 	err = s.repo.UpdateCamera(ctx, camera, updates)
 
 	if err != nil {
 		return nil, err
 	}
 
+	s.audit.Record(ctx, audit.Entry{
+		ActorID:  userID,
+		UserID:   camera.UserID,
+		Action:   models.AuditCameraUpdate,
+		Target:   "camera",
+		TargetID: camera.ID,
+		Details:  map[string]any{"changes": audit.Diff(before, updates)},
+	})
+
 	return camera, nil
 }
 
@@ -102,19 +136,64 @@ func (s *CameraService) DeleteCamera(ctx context.Context, cameraID uint, userID
 	camera, err := s.repo.GetCameraByID(ctx, cameraID)
 
 	if err != nil {
-		return err
+		return notFound(err, ErrCameraNotFound)
 	}
 
 	if camera.UserID != userID {
-		return errors.New("forbidden")
+		return ErrCameraNotFound
+	}
+
+	err = s.repo.DeleteCamera(ctx, camera)
+	if err != nil {
+		return err
 	}
 
-	return s.repo.DeleteCamera(ctx, camera)
+	s.audit.Record(ctx, audit.Entry{
+		ActorID:  userID,
+		UserID:   camera.UserID,
+		Action:   models.AuditCameraDelete,
+		Target:   "camera",
+		TargetID: camera.ID,
+		Details:  map[string]any{"before": camera},
+	})
+
+	return nil
 }
 
 func (s *CameraService) DeleteAllByUser(ctx context.Context, requestingUserID, targetUserID uint) error {
 	if requestingUserID != targetUserID {
-		return errors.New("forbidden")
+		return ErrCameraNotFound
+	}
+
+	cameras, err := s.repo.GetAllByUserID(ctx, targetUserID)
+	if err != nil {
+		return err
+	}
+
+	err = s.repo.DeleteAllByUserID(ctx, targetUserID)
+	if err != nil {
+		return err
+	}
+
+	s.audit.Record(ctx, audit.Entry{
+		ActorID: requestingUserID,
+		UserID:  targetUserID,
+		Action:  models.AuditCameraDeleteAll,
+		Details: map[string]any{"before": cameras},
+	})
+
+	return nil
+}
+
+// cameraColumns maps update columns to the camera's current values, for audit diffs
+func cameraColumns(c *models.Camera) map[string]any {
+	return map[string]any{
+		"brand":         c.Brand,
+		"camera_model":  c.CameraModel,
+		"camera_format": c.CameraFormat,
+		"year":          c.Year,
+		"serial_number": c.SerialNumber,
+		"notes":         c.Notes,
+		"image_url":     c.ImageURL,
 	}
-	return s.repo.DeleteAllByUserID(ctx, targetUserID)
 }
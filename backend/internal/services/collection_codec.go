@@ -0,0 +1,493 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/georgiev098/film-manager/backend/internal/apperrors"
+	"github.com/georgiev098/film-manager/backend/internal/dtos"
+	"github.com/georgiev098/film-manager/backend/internal/models"
+)
+
+const (
+	FormatJSON = "json"
+	FormatCSV  = "csv"
+	FormatZIP  = "zip"
+)
+
+// Uncompressed size limit of a file inside an imported ZIP archive, so a
+// small upload can't expand into more than we are willing to hold in memory
+const maxZipEntryBytes = 50 << 20
+
+var ErrUnsupportedFormat = apperrors.Validation("unsupported format")
+
+// One CSV holds every kind of record, the "kind" column tells them apart;
+// archive locations go by their own kind (box, binder, page, strip). Lenses
+// and film stocks store their manufacturer in "brand". Columns a kind doesn't
+// use stay empty.
+var collectionCSVHeader = []string{
+	"kind", "ref", "brand", "camera_model", "camera_format", "year", "serial_number",
+	"lens_type", "min_focal_length", "max_focal_length", "min_aperture", "max_aperture",
+	"mount", "image_stabilization", "image_url",
+	"name", "iso", "format", "process",
+	"label", "position", "parent",
+	"film_stock", "camera", "status", "loaded_at", "exposed_at", "developed_at", "archive_location",
+	"roll", "number", "strip", "title",
+	"frame", "version", "previous", "paper", "paper_size", "contrast", "enlarger_height_cm",
+	"aperture", "exposure_seconds", "test_strip", "dodge_burn", "toning", "changes",
+	"cameras", "lenses",
+	"date", "latitude", "longitude", "timezone", "kit",
+	"notes",
+}
+
+// EncodeCollection writes the collection in the requested format
+func EncodeCollection(w io.Writer, format string, c *dtos.Collection) error {
+	switch format {
+	case FormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(c)
+	case FormatCSV:
+		return writeCollectionCSV(w, c)
+	case FormatZIP:
+		zw := zip.NewWriter(w)
+
+		f, err := zw.Create("collection.json")
+		if err != nil {
+			return err
+		}
+		if err := EncodeCollection(f, FormatJSON, c); err != nil {
+			return err
+		}
+
+		f, err = zw.Create("collection.csv")
+		if err != nil {
+			return err
+		}
+		if err := writeCollectionCSV(f, c); err != nil {
+			return err
+		}
+
+		return zw.Close()
+	default:
+		return ErrUnsupportedFormat
+	}
+}
+
+// DecodeCollection parses data produced by EncodeCollection
+func DecodeCollection(format string, data []byte) (*dtos.Collection, error) {
+	switch format {
+	case FormatJSON:
+		var c dtos.Collection
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&c); err != nil {
+			return nil, err
+		}
+		return &c, nil
+	case FormatCSV:
+		return readCollectionCSV(bytes.NewReader(data))
+	case FormatZIP:
+		zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			return nil, err
+		}
+
+		// Prefer the JSON file, it round-trips exactly
+		for _, name := range []string{"collection.json", "collection.csv"} {
+			i := slices.IndexFunc(zr.File, func(f *zip.File) bool { return f.Name == name })
+			if i < 0 {
+				continue
+			}
+			content, err := readZipEntry(zr.File[i])
+			if err != nil {
+				return nil, err
+			}
+			return DecodeCollection(strings.TrimPrefix(name, "collection."), content)
+		}
+
+		return nil, errors.New("archive contains neither collection.json nor collection.csv")
+	default:
+		return nil, ErrUnsupportedFormat
+	}
+}
+
+// readZipEntry reads an archive entry of at most maxZipEntryBytes. The size
+// in the header can be forged, so the read itself is limited too.
+func readZipEntry(f *zip.File) ([]byte, error) {
+	if f.UncompressedSize64 > maxZipEntryBytes {
+		return nil, fmt.Errorf("%s is larger than %d MB", f.Name, maxZipEntryBytes>>20)
+	}
+
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	content, err := io.ReadAll(io.LimitReader(rc, maxZipEntryBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(content) > maxZipEntryBytes {
+		return nil, fmt.Errorf("%s is larger than %d MB", f.Name, maxZipEntryBytes>>20)
+	}
+
+	return content, nil
+}
+
+func writeCollectionCSV(w io.Writer, c *dtos.Collection) error {
+	cw := csv.NewWriter(w)
+
+	if err := cw.Write(collectionCSVHeader); err != nil {
+		return err
+	}
+
+	var rows []map[string]string
+
+	for _, cam := range c.Cameras {
+		rows = append(rows, map[string]string{
+			"kind": "camera", "ref": cam.Ref, "brand": cam.Brand, "camera_model": cam.CameraModel,
+			"camera_format": string(cam.CameraFormat), "year": intPtrString(cam.Year), "serial_number": strPtrString(cam.SerialNumber),
+			"image_url": strPtrString(cam.ImageURL), "notes": strPtrString(cam.Notes),
+		})
+	}
+
+	for _, l := range c.Lenses {
+		rows = append(rows, map[string]string{
+			"kind": "lens", "ref": l.Ref, "brand": l.Manufacturer, "lens_type": string(l.LensType),
+			"min_focal_length": strconv.Itoa(l.FocalLengthMin), "max_focal_length": strconv.Itoa(l.FocalLengthMax),
+			"min_aperture": l.MinApertureStr, "max_aperture": l.MaxApertureStr, "mount": l.Mount,
+			"image_stabilization": strconv.FormatBool(l.ImageStabilization), "image_url": strPtrString(l.ImageURL), "notes": strPtrString(l.Notes),
+		})
+	}
+
+	for _, f := range c.FilmStocks {
+		rows = append(rows, map[string]string{
+			"kind": "film_stock", "ref": f.Ref, "brand": f.Manufacturer, "name": f.Name, "iso": strconv.Itoa(f.ISO),
+			"format": string(f.Format), "process": string(f.Process), "notes": strPtrString(f.Notes),
+		})
+	}
+
+	for _, a := range c.Archive {
+		rows = append(rows, map[string]string{
+			"kind": string(a.Kind), "ref": a.Ref, "label": a.Label, "position": strconv.Itoa(a.Position),
+			"parent": strPtrString(a.Parent), "notes": strPtrString(a.Notes),
+		})
+	}
+
+	for _, r := range c.Rolls {
+		rows = append(rows, map[string]string{
+			"kind": "roll", "ref": r.Ref, "film_stock": r.FilmStock, "camera": strPtrString(r.Camera), "status": string(r.Status),
+			"loaded_at": timePtrString(r.LoadedAt), "exposed_at": timePtrString(r.ExposedAt), "developed_at": timePtrString(r.DevelopedAt),
+			"archive_location": strPtrString(r.ArchiveLocation), "notes": strPtrString(r.Notes),
+		})
+	}
+
+	for _, f := range c.Frames {
+		rows = append(rows, map[string]string{
+			"kind": "frame", "ref": f.Ref, "roll": f.Roll, "number": strconv.Itoa(f.Number), "strip": strPtrString(f.Strip),
+			"title": strPtrString(f.Title), "notes": strPtrString(f.Notes),
+		})
+	}
+
+	for _, p := range c.Prints {
+		rows = append(rows, map[string]string{
+			"kind": "print", "ref": p.Ref, "frame": p.Frame, "version": strconv.Itoa(p.Version), "previous": strPtrString(p.Previous),
+			"paper": p.Paper, "paper_size": p.PaperSize, "contrast": strPtrString(p.Contrast), "enlarger_height_cm": floatPtrString(p.EnlargerHeightCM),
+			"aperture": strPtrString(p.Aperture), "exposure_seconds": strconv.FormatFloat(p.ExposureSeconds, 'f', -1, 64),
+			"test_strip": strPtrString(p.TestStrip), "dodge_burn": strPtrString(p.DodgeBurn), "toning": strPtrString(p.Toning),
+			"changes": string(p.Changes), "notes": strPtrString(p.Notes),
+		})
+	}
+
+	for _, k := range c.Kits {
+		rows = append(rows, map[string]string{
+			"kind": "kit", "ref": k.Ref, "name": k.Name, "cameras": strings.Join(k.Cameras, " "), "lenses": strings.Join(k.Lenses, " "),
+			"notes": strPtrString(k.Notes),
+		})
+	}
+
+	for _, sh := range c.Shoots {
+		rows = append(rows, map[string]string{
+			"kind": "shoot", "ref": sh.Ref, "title": sh.Title, "date": sh.Date,
+			"latitude": strconv.FormatFloat(sh.Latitude, 'f', -1, 64), "longitude": strconv.FormatFloat(sh.Longitude, 'f', -1, 64),
+			"timezone": sh.Timezone, "kit": strPtrString(sh.Kit), "notes": strPtrString(sh.Notes),
+		})
+	}
+
+	record := make([]string, len(collectionCSVHeader))
+	for _, row := range rows {
+		for i, col := range collectionCSVHeader {
+			record[i] = row[col]
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+func readCollectionCSV(r io.Reader) (*dtos.Collection, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+
+	header, err := cr.Read()
+	if err != nil {
+		return nil, err
+	}
+
+	cols := map[string]int{}
+	for i, name := range header {
+		cols[strings.TrimSpace(strings.ToLower(name))] = i
+	}
+	if _, ok := cols["kind"]; !ok {
+		return nil, errors.New("csv header must contain a kind column")
+	}
+
+	c := &dtos.Collection{Version: collectionVersion}
+	line := 1
+
+	for {
+		row, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		line++
+
+		get := func(col string) string {
+			i, ok := cols[col]
+			if !ok || i >= len(row) {
+				return ""
+			}
+			return strings.TrimSpace(row[i])
+		}
+
+		// The first column that fails to parse, reported with the line
+		var invalid string
+		atoi := func(col string) int {
+			v, err := strconv.Atoi(get(col))
+			if err != nil && invalid == "" {
+				invalid = col
+			}
+			return v
+		}
+		float := func(col string) float64 {
+			v, err := strconv.ParseFloat(get(col), 64)
+			if err != nil && invalid == "" {
+				invalid = col
+			}
+			return v
+		}
+		optionalTime := func(col string) *time.Time {
+			v, err := parseOptionalTime(get(col))
+			if err != nil && invalid == "" {
+				invalid = col
+			}
+			return v
+		}
+
+		switch kind := get("kind"); kind {
+		case "camera":
+			year, err := parseOptionalInt(get("year"))
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid year", line)
+			}
+			c.Cameras = append(c.Cameras, dtos.CameraRecord{
+				Ref:          get("ref"),
+				Brand:        get("brand"),
+				CameraModel:  get("camera_model"),
+				CameraFormat: models.CameraFormat(get("camera_format")),
+				Year:         year,
+				SerialNumber: optionalString(get("serial_number")),
+				Notes:        optionalString(get("notes")),
+				ImageURL:     optionalString(get("image_url")),
+			})
+		case "lens":
+			stabilized, _ := strconv.ParseBool(get("image_stabilization"))
+
+			c.Lenses = append(c.Lenses, dtos.LensRecord{
+				Ref:                get("ref"),
+				Manufacturer:       get("brand"),
+				LensType:           models.LensType(get("lens_type")),
+				ImageStabilization: stabilized,
+				FocalLengthMin:     atoi("min_focal_length"),
+				FocalLengthMax:     atoi("max_focal_length"),
+				MinApertureStr:     get("min_aperture"),
+				MaxApertureStr:     get("max_aperture"),
+				Mount:              get("mount"),
+				ImageURL:           optionalString(get("image_url")),
+				Notes:              optionalString(get("notes")),
+			})
+		case "film_stock":
+			c.FilmStocks = append(c.FilmStocks, dtos.FilmStockRecord{
+				Ref:          get("ref"),
+				Manufacturer: get("brand"),
+				Name:         get("name"),
+				ISO:          atoi("iso"),
+				Format:       models.CameraFormat(get("format")),
+				Process:      models.FilmProcess(get("process")),
+				Notes:        optionalString(get("notes")),
+			})
+		case string(models.ArchiveBox), string(models.ArchiveBinder), string(models.ArchivePage), string(models.ArchiveStrip):
+			c.Archive = append(c.Archive, dtos.ArchiveRecord{
+				Ref:      get("ref"),
+				Kind:     models.ArchiveKind(kind),
+				Label:    get("label"),
+				Position: atoi("position"),
+				Parent:   optionalString(get("parent")),
+				Notes:    optionalString(get("notes")),
+			})
+		case "roll":
+			c.Rolls = append(c.Rolls, dtos.RollRecord{
+				Ref:             get("ref"),
+				FilmStock:       get("film_stock"),
+				Camera:          optionalString(get("camera")),
+				Status:          models.RollStatus(get("status")),
+				LoadedAt:        optionalTime("loaded_at"),
+				ExposedAt:       optionalTime("exposed_at"),
+				DevelopedAt:     optionalTime("developed_at"),
+				ArchiveLocation: optionalString(get("archive_location")),
+				Notes:           optionalString(get("notes")),
+			})
+		case "frame":
+			c.Frames = append(c.Frames, dtos.FrameRecord{
+				Ref:    get("ref"),
+				Roll:   get("roll"),
+				Number: atoi("number"),
+				Strip:  optionalString(get("strip")),
+				Title:  optionalString(get("title")),
+				Notes:  optionalString(get("notes")),
+			})
+		case "print":
+			var height *float64
+			if get("enlarger_height_cm") != "" {
+				v := float("enlarger_height_cm")
+				height = &v
+			}
+			var changes json.RawMessage
+			if v := get("changes"); v != "" {
+				if !json.Valid([]byte(v)) {
+					return nil, fmt.Errorf("line %d: invalid changes", line)
+				}
+				changes = json.RawMessage(v)
+			}
+
+			c.Prints = append(c.Prints, dtos.PrintRecord{
+				Ref:              get("ref"),
+				Frame:            get("frame"),
+				Version:          atoi("version"),
+				Previous:         optionalString(get("previous")),
+				Paper:            get("paper"),
+				PaperSize:        get("paper_size"),
+				Contrast:         optionalString(get("contrast")),
+				EnlargerHeightCM: height,
+				Aperture:         optionalString(get("aperture")),
+				ExposureSeconds:  float("exposure_seconds"),
+				TestStrip:        optionalString(get("test_strip")),
+				DodgeBurn:        optionalString(get("dodge_burn")),
+				Toning:           optionalString(get("toning")),
+				Changes:          changes,
+				Notes:            optionalString(get("notes")),
+			})
+		case "kit":
+			c.Kits = append(c.Kits, dtos.KitRecord{
+				Ref:     get("ref"),
+				Name:    get("name"),
+				Cameras: strings.Fields(get("cameras")),
+				Lenses:  strings.Fields(get("lenses")),
+				Notes:   optionalString(get("notes")),
+			})
+		case "shoot":
+			c.Shoots = append(c.Shoots, dtos.ShootRecord{
+				Ref:       get("ref"),
+				Title:     get("title"),
+				Date:      get("date"),
+				Latitude:  float("latitude"),
+				Longitude: float("longitude"),
+				Timezone:  get("timezone"),
+				Kit:       optionalString(get("kit")),
+				Notes:     optionalString(get("notes")),
+			})
+		default:
+			return nil, fmt.Errorf("line %d: unknown kind %q", line, kind)
+		}
+
+		if invalid != "" {
+			return nil, fmt.Errorf("line %d: invalid %s", line, invalid)
+		}
+	}
+
+	return c, nil
+}
+
+func intPtrString(v *int) string {
+	if v == nil {
+		return ""
+	}
+	return strconv.Itoa(*v)
+}
+
+func floatPtrString(v *float64) string {
+	if v == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*v, 'f', -1, 64)
+}
+
+func timePtrString(v *time.Time) string {
+	if v == nil {
+		return ""
+	}
+	return v.Format(time.RFC3339Nano)
+}
+
+func strPtrString(v *string) string {
+	if v == nil {
+		return ""
+	}
+	return *v
+}
+
+func optionalString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+func parseOptionalTime(s string) (*time.Time, error) {
+	if s == "" {
+		return nil, nil
+	}
+	v, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+func parseOptionalInt(s string) (*int, error) {
+	if s == "" {
+		return nil, nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
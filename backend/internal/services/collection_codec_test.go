@@ -0,0 +1,91 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/flate"
+	"testing"
+)
+
+func TestDecodeCollectionZipEntryLimit(t *testing.T) {
+	// Compresses to a few dozen KB
+	huge := bytes.Repeat([]byte(" "), maxZipEntryBytes+1)
+
+	deflate := func(t *testing.T, data []byte) []byte {
+		t.Helper()
+
+		var buf bytes.Buffer
+		fw, err := flate.NewWriter(&buf, flate.BestCompression)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := fw.Write(data); err != nil {
+			t.Fatal(err)
+		}
+		if err := fw.Close(); err != nil {
+			t.Fatal(err)
+		}
+		return buf.Bytes()
+	}
+
+	tests := []struct {
+		name    string
+		write   func(t *testing.T, zw *zip.Writer)
+		wantErr bool
+	}{
+		{
+			name: "small entry",
+			write: func(t *testing.T, zw *zip.Writer) {
+				f, err := zw.Create("collection.json")
+				if err != nil {
+					t.Fatal(err)
+				}
+				f.Write([]byte(`{"version": 1, "cameras": [], "lenses": []}`))
+			},
+		},
+		{
+			name: "entry over the limit",
+			write: func(t *testing.T, zw *zip.Writer) {
+				f, err := zw.Create("collection.json")
+				if err != nil {
+					t.Fatal(err)
+				}
+				f.Write(huge)
+			},
+			wantErr: true,
+		},
+		{
+			name: "forged uncompressed size",
+			write: func(t *testing.T, zw *zip.Writer) {
+				compressed := deflate(t, huge)
+				f, err := zw.CreateRaw(&zip.FileHeader{
+					Name:               "collection.json",
+					Method:             zip.Deflate,
+					CompressedSize64:   uint64(len(compressed)),
+					UncompressedSize64: 100,
+				})
+				if err != nil {
+					t.Fatal(err)
+				}
+				f.Write(compressed)
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			zw := zip.NewWriter(&buf)
+			tt.write(t, zw)
+			if err := zw.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			_, err := DecodeCollection(FormatZIP, buf.Bytes())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
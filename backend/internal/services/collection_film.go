@@ -0,0 +1,1109 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/georgiev098/film-manager/backend/internal/dtos"
+	"github.com/georgiev098/film-manager/backend/internal/helpers"
+	"github.com/georgiev098/film-manager/backend/internal/i18n"
+	"github.com/georgiev098/film-manager/backend/internal/models"
+	"github.com/georgiev098/film-manager/backend/internal/repositories"
+	"gorm.io/gorm"
+)
+
+// archiveDepth orders the archive kinds from the outside in, so a location
+// is exported after the one it is filed in
+var archiveDepth = map[models.ArchiveKind]int{
+	models.ArchiveBox:    0,
+	models.ArchiveBinder: 1,
+	models.ArchivePage:   2,
+	models.ArchiveStrip:  3,
+}
+
+// importRefs resolves the refs of a collection file to the user's records,
+// matched or just imported. In a dry run the new ones keep ID 0.
+type importRefs struct {
+	cameras   map[string]*models.Camera
+	lenses    map[string]*models.Lens
+	stocks    map[string]*models.FilmStock
+	locations map[string]*models.ArchiveLocation
+	rolls     map[string]*models.Roll
+	frames    map[string]*models.Frame
+	prints    map[string]*models.Print
+	kits      map[string]*models.Kit
+}
+
+func newImportRefs() *importRefs {
+	return &importRefs{
+		cameras:   map[string]*models.Camera{},
+		lenses:    map[string]*models.Lens{},
+		stocks:    map[string]*models.FilmStock{},
+		locations: map[string]*models.ArchiveLocation{},
+		rolls:     map[string]*models.Roll{},
+		frames:    map[string]*models.Frame{},
+		prints:    map[string]*models.Print{},
+		kits:      map[string]*models.Kit{},
+	}
+}
+
+func setRef[T any](refs map[string]*T, ref string, record *T) {
+	if ref != "" {
+		refs[ref] = record
+	}
+}
+
+// lookupRef resolves an optional ref. ok is false when ref is set but unknown.
+func lookupRef[T any](refs map[string]*T, ref *string) (record *T, ok bool) {
+	if ref == nil {
+		return nil, true
+	}
+	record, ok = refs[*ref]
+	return record, ok
+}
+
+func exportRef(id uint) string {
+	return strconv.FormatUint(uint64(id), 10)
+}
+
+// refOf is the ref of id when that record is exported too. Links to deleted
+// records are left out.
+func refOf(id *uint, exported map[uint]bool) *string {
+	if id == nil || !exported[*id] {
+		return nil
+	}
+	ref := exportRef(*id)
+	return &ref
+}
+
+func idSet[T any](records []T, id func(T) uint) map[uint]bool {
+	ids := make(map[uint]bool, len(records))
+	for _, record := range records {
+		ids[id(record)] = true
+	}
+	return ids
+}
+
+// exportFilm adds the film stocks, archive, rolls, frames, prints, kits and
+// shoots to the collection. A record is left out with the one it belongs to.
+func (s *CollectionService) exportFilm(ctx context.Context, userID uint, collection *dtos.Collection, cameras []models.Camera, lenses []models.Lens) error {
+	stocks, err := repositories.NewFilmStockRepo(s.db).GetAllByUserID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	locations, err := repositories.NewArchiveLocationRepo(s.db).GetAllByUserID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	rolls, err := repositories.NewRollRepo(s.db).GetAllByUserID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	frames, err := repositories.NewFrameRepo(s.db).GetAllByUserID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	prints, err := repositories.NewPrintRepo(s.db).GetAllByUserID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	kits, err := repositories.NewKitRepo(s.db).GetAllByUserID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	shoots, err := repositories.NewShootRepo(s.db).GetAllByUserID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	cameraIDs := idSet(cameras, func(c models.Camera) uint { return c.ID })
+	lensIDs := idSet(lenses, func(l models.Lens) uint { return l.ID })
+	stockIDs := idSet(stocks, func(f models.FilmStock) uint { return f.ID })
+	locationIDs := idSet(locations, func(l models.ArchiveLocation) uint { return l.ID })
+	kitIDs := idSet(kits, func(k models.Kit) uint { return k.ID })
+
+	collection.FilmStocks = make([]dtos.FilmStockRecord, 0, len(stocks))
+	for _, f := range stocks {
+		collection.FilmStocks = append(collection.FilmStocks, dtos.FilmStockRecord{
+			Ref:          exportRef(f.ID),
+			Manufacturer: f.Manufacturer,
+			Name:         f.Name,
+			ISO:          f.ISO,
+			Format:       f.Format,
+			Process:      f.Process,
+			Notes:        f.Notes,
+		})
+	}
+
+	slices.SortStableFunc(locations, func(a, b models.ArchiveLocation) int {
+		return archiveDepth[a.Kind] - archiveDepth[b.Kind]
+	})
+	collection.Archive = make([]dtos.ArchiveRecord, 0, len(locations))
+	for _, l := range locations {
+		collection.Archive = append(collection.Archive, dtos.ArchiveRecord{
+			Ref:      exportRef(l.ID),
+			Kind:     l.Kind,
+			Label:    l.Label,
+			Position: l.Position,
+			Parent:   refOf(l.ParentID, locationIDs),
+			Notes:    l.Notes,
+		})
+	}
+
+	rollIDs := map[uint]bool{}
+	collection.Rolls = make([]dtos.RollRecord, 0, len(rolls))
+	for _, r := range rolls {
+		if !stockIDs[r.FilmStockID] {
+			continue
+		}
+		rollIDs[r.ID] = true
+		collection.Rolls = append(collection.Rolls, dtos.RollRecord{
+			Ref:             exportRef(r.ID),
+			FilmStock:       exportRef(r.FilmStockID),
+			Camera:          refOf(r.CameraID, cameraIDs),
+			Status:          r.Status,
+			LoadedAt:        r.LoadedAt,
+			ExposedAt:       r.ExposedAt,
+			DevelopedAt:     r.DevelopedAt,
+			ArchiveLocation: refOf(r.ArchiveLocationID, locationIDs),
+			Notes:           r.Notes,
+		})
+	}
+
+	frameIDs := map[uint]bool{}
+	collection.Frames = make([]dtos.FrameRecord, 0, len(frames))
+	for _, f := range frames {
+		if !rollIDs[f.RollID] {
+			continue
+		}
+		frameIDs[f.ID] = true
+		collection.Frames = append(collection.Frames, dtos.FrameRecord{
+			Ref:    exportRef(f.ID),
+			Roll:   exportRef(f.RollID),
+			Number: f.Number,
+			Strip:  refOf(f.StripID, locationIDs),
+			Title:  f.Title,
+			Notes:  f.Notes,
+		})
+	}
+
+	printIDs := idSet(prints, func(p models.Print) uint { return p.ID })
+	collection.Prints = make([]dtos.PrintRecord, 0, len(prints))
+	for _, p := range prints {
+		if !frameIDs[p.FrameID] {
+			continue
+		}
+		collection.Prints = append(collection.Prints, dtos.PrintRecord{
+			Ref:              exportRef(p.ID),
+			Frame:            exportRef(p.FrameID),
+			Version:          p.Version,
+			Previous:         refOf(p.PreviousID, printIDs),
+			Paper:            p.Paper,
+			PaperSize:        p.PaperSize,
+			Contrast:         p.Contrast,
+			EnlargerHeightCM: p.EnlargerHeightCM,
+			Aperture:         p.Aperture,
+			ExposureSeconds:  p.ExposureSeconds,
+			TestStrip:        p.TestStrip,
+			DodgeBurn:        p.DodgeBurn,
+			Toning:           p.Toning,
+			Notes:            p.Notes,
+			Changes:          p.Changes,
+		})
+	}
+
+	collection.Kits = make([]dtos.KitRecord, 0, len(kits))
+	for _, k := range kits {
+		rec := dtos.KitRecord{Ref: exportRef(k.ID), Name: k.Name, Notes: k.Notes}
+		for _, c := range k.Cameras {
+			if cameraIDs[c.ID] {
+				rec.Cameras = append(rec.Cameras, exportRef(c.ID))
+			}
+		}
+		for _, l := range k.Lenses {
+			if lensIDs[l.ID] {
+				rec.Lenses = append(rec.Lenses, exportRef(l.ID))
+			}
+		}
+		collection.Kits = append(collection.Kits, rec)
+	}
+
+	collection.Shoots = make([]dtos.ShootRecord, 0, len(shoots))
+	for _, sh := range shoots {
+		collection.Shoots = append(collection.Shoots, dtos.ShootRecord{
+			Ref:       exportRef(sh.ID),
+			Title:     sh.Title,
+			Date:      sh.Date,
+			Latitude:  sh.Latitude,
+			Longitude: sh.Longitude,
+			Timezone:  sh.Timezone,
+			Kit:       refOf(sh.KitID, kitIDs),
+			Notes:     sh.Notes,
+		})
+	}
+
+	return nil
+}
+
+// importFilm applies the film side of the collection after its gear. Records
+// are matched to the user's own: film stocks by manufacturer, name and format,
+// archive locations by kind and label within their parent, rolls by stock and
+// load time, frames by number on their roll, prints by version of their
+// frame, kits by name and shoots by title and date.
+func (s *CollectionService) importFilm(ctx context.Context, db *gorm.DB, userID uint, collection *dtos.Collection, cameras map[uint]*models.Camera, refs *importRefs, dryRun bool, report *dtos.ImportReport) error {
+	stockRepo := repositories.NewFilmStockRepo(db)
+	stocks, err := stockRepo.GetAllByUserID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	stockIndex := map[string]*models.FilmStock{}
+	stocksByID := map[uint]*models.FilmStock{}
+	for i := range stocks {
+		stockIndex[filmStockKey(stocks[i].Manufacturer, stocks[i].Name, stocks[i].Format)] = &stocks[i]
+		stocksByID[stocks[i].ID] = &stocks[i]
+	}
+
+	for _, rec := range collection.FilmStocks {
+		item, err := s.importFilmStock(ctx, stockRepo, stockIndex, refs, userID, rec, dryRun)
+		if err != nil {
+			return err
+		}
+		addImportItem(report, item)
+	}
+
+	locationRepo := repositories.NewArchiveLocationRepo(db)
+	locations, err := locationRepo.GetAllByUserID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	locationsByID := map[uint]*models.ArchiveLocation{}
+	for i := range locations {
+		locationsByID[locations[i].ID] = &locations[i]
+	}
+	locationIndex := map[archiveKey]*models.ArchiveLocation{}
+	for i := range locations {
+		if locations[i].ParentID != nil {
+			locations[i].Parent = locationsByID[*locations[i].ParentID]
+		}
+		locationIndex[newArchiveKey(locations[i].Parent, locations[i].Kind, locations[i].Label)] = &locations[i]
+	}
+
+	for _, rec := range collection.Archive {
+		item, err := s.importArchiveLocation(ctx, locationRepo, locationIndex, refs, userID, rec, dryRun)
+		if err != nil {
+			return err
+		}
+		addImportItem(report, item)
+	}
+
+	rollRepo := repositories.NewRollRepo(db)
+	rolls, err := rollRepo.GetAllByUserID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	rollIndex := newRollIndex()
+	rollsByID := map[uint]*models.Roll{}
+	for i := range rolls {
+		roll := &rolls[i]
+		roll.FilmStock = stocksByID[roll.FilmStockID]
+		if roll.CameraID != nil {
+			roll.Camera = cameras[*roll.CameraID]
+		}
+		if roll.ArchiveLocationID != nil {
+			roll.ArchiveLocation = locationsByID[*roll.ArchiveLocationID]
+		}
+		rollIndex.add(roll)
+		rollsByID[roll.ID] = roll
+	}
+
+	for _, rec := range collection.Rolls {
+		item, err := s.importRoll(ctx, db, rollIndex, refs, userID, rec, dryRun)
+		if err != nil {
+			return err
+		}
+		addImportItem(report, item)
+	}
+
+	frameRepo := repositories.NewFrameRepo(db)
+	frames, err := frameRepo.GetAllByUserID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	frameIndex := map[frameKey]*models.Frame{}
+	framesByID := map[uint]*models.Frame{}
+	for i := range frames {
+		frame := &frames[i]
+		frame.Roll = rollsByID[frame.RollID]
+		if frame.StripID != nil {
+			frame.Strip = locationsByID[*frame.StripID]
+		}
+		frameIndex[frameKey{frame.Roll, frame.Number}] = frame
+		framesByID[frame.ID] = frame
+	}
+
+	for _, rec := range collection.Frames {
+		item, err := s.importFrame(ctx, frameRepo, frameIndex, refs, userID, rec, dryRun)
+		if err != nil {
+			return err
+		}
+		addImportItem(report, item)
+	}
+
+	printRepo := repositories.NewPrintRepo(db)
+	prints, err := printRepo.GetAllByUserID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	printIndex := map[printKey]*models.Print{}
+	for i := range prints {
+		prints[i].Frame = framesByID[prints[i].FrameID]
+		printIndex[printKey{prints[i].Frame, prints[i].Version}] = &prints[i]
+	}
+
+	for _, rec := range collection.Prints {
+		item, err := s.importPrint(ctx, printRepo, printIndex, refs, userID, rec, dryRun)
+		if err != nil {
+			return err
+		}
+		addImportItem(report, item)
+	}
+
+	kitRepo := repositories.NewKitRepo(db)
+	kits, err := kitRepo.GetAllByUserID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	kitIndex := map[string]*models.Kit{}
+	for i := range kits {
+		kitIndex[strings.ToLower(kits[i].Name)] = &kits[i]
+	}
+
+	for _, rec := range collection.Kits {
+		item, err := s.importKit(ctx, kitRepo, kitIndex, refs, userID, rec, dryRun)
+		if err != nil {
+			return err
+		}
+		addImportItem(report, item)
+	}
+
+	shootRepo := repositories.NewShootRepo(db)
+	shoots, err := shootRepo.GetAllByUserID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	shootIndex := map[string]*models.Shoot{}
+	for i := range shoots {
+		shootIndex[shootKey(shoots[i].Title, shoots[i].Date)] = &shoots[i]
+	}
+
+	for _, rec := range collection.Shoots {
+		item, err := s.importShoot(ctx, shootRepo, shootIndex, refs, userID, rec, dryRun)
+		if err != nil {
+			return err
+		}
+		addImportItem(report, item)
+	}
+
+	return nil
+}
+
+func (s *CollectionService) importFilmStock(ctx context.Context, repo *repositories.FilmStockRepo, index map[string]*models.FilmStock, refs *importRefs, userID uint, rec dtos.FilmStockRecord, dryRun bool) (dtos.ImportItemResult, error) {
+	item := dtos.ImportItemResult{Kind: "film_stock", Key: fmt.Sprintf("%s %s %s", rec.Manufacturer, rec.Name, rec.Format)}
+
+	stock := models.FilmStock{
+		Manufacturer: rec.Manufacturer,
+		Name:         rec.Name,
+		ISO:          rec.ISO,
+		Format:       rec.Format,
+		Process:      rec.Process,
+		Notes:        rec.Notes,
+		UserID:       userID,
+	}
+
+	if err := s.validate.Struct(stock); err != nil {
+		return invalidImportItem(ctx, item, err), nil
+	}
+
+	key := filmStockKey(rec.Manufacturer, rec.Name, rec.Format)
+	existing, ok := index[key]
+	if !ok {
+		item.Action = dtos.ImportCreate
+		if !dryRun {
+			if err := repo.CreateFilmStock(ctx, &stock); err != nil {
+				return item, err
+			}
+			item.ID = stock.ID
+		}
+		index[key] = &stock
+		setRef(refs.stocks, rec.Ref, &stock)
+		return item, nil
+	}
+
+	item.ID = existing.ID
+	setRef(refs.stocks, rec.Ref, existing)
+
+	updates := map[string]any{}
+	if existing.ISO != rec.ISO {
+		updates["iso"] = rec.ISO
+	}
+	if existing.Process != rec.Process {
+		updates["process"] = rec.Process
+	}
+	if rec.Notes != nil && (existing.Notes == nil || *existing.Notes != *rec.Notes) {
+		updates["notes"] = *rec.Notes
+	}
+
+	return updateImportItem(item, updates, dryRun, func() error {
+		return repo.UpdateFilmStock(ctx, existing, updates)
+	})
+}
+
+func (s *CollectionService) importArchiveLocation(ctx context.Context, repo *repositories.ArchiveLocationRepo, index map[archiveKey]*models.ArchiveLocation, refs *importRefs, userID uint, rec dtos.ArchiveRecord, dryRun bool) (dtos.ImportItemResult, error) {
+	item := dtos.ImportItemResult{Kind: "archive", Key: fmt.Sprintf("%s %s", rec.Kind, rec.Label)}
+
+	parent, ok := lookupRef(refs.locations, rec.Parent)
+	if !ok {
+		return skipImportItem(item, "unknown archive location"), nil
+	}
+
+	location := models.ArchiveLocation{
+		Kind:     rec.Kind,
+		Label:    rec.Label,
+		Position: rec.Position,
+		Parent:   parent,
+		Notes:    rec.Notes,
+		UserID:   userID,
+	}
+
+	if err := s.validate.Struct(location); err != nil {
+		return invalidImportItem(ctx, item, err), nil
+	}
+
+	rule := archiveParents[rec.Kind]
+	if (parent == nil && !rule.optional) || (parent != nil && parent.Kind != rule.kind) {
+		return skipImportItem(item, ErrArchiveParent.Message), nil
+	}
+
+	key := newArchiveKey(parent, rec.Kind, rec.Label)
+	existing, ok := index[key]
+	if !ok {
+		item.Action = dtos.ImportCreate
+		if !dryRun {
+			if parent != nil {
+				location.ParentID = &parent.ID
+			}
+			if err := repo.CreateLocation(ctx, &location); err != nil {
+				return item, err
+			}
+			item.ID = location.ID
+		}
+		index[key] = &location
+		setRef(refs.locations, rec.Ref, &location)
+		return item, nil
+	}
+
+	item.ID = existing.ID
+	setRef(refs.locations, rec.Ref, existing)
+
+	updates := map[string]any{}
+	if existing.Position != rec.Position {
+		updates["position"] = rec.Position
+	}
+	if rec.Notes != nil && (existing.Notes == nil || *existing.Notes != *rec.Notes) {
+		updates["notes"] = *rec.Notes
+	}
+
+	return updateImportItem(item, updates, dryRun, func() error {
+		return repo.UpdateLocation(ctx, existing, updates)
+	})
+}
+
+// importRoll keeps the roll's own timestamps, so unlike RollService it writes
+// through the repository, checking the same rules against the imported state
+func (s *CollectionService) importRoll(ctx context.Context, db *gorm.DB, index *rollIndex, refs *importRefs, userID uint, rec dtos.RollRecord, dryRun bool) (dtos.ImportItemResult, error) {
+	item := dtos.ImportItemResult{Kind: "roll", Key: rec.Ref}
+
+	stock, ok := refs.stocks[rec.FilmStock]
+	if !ok {
+		return skipImportItem(item, "unknown film stock"), nil
+	}
+	item.Key = stock.Manufacturer + " " + stock.Name
+	if rec.LoadedAt != nil {
+		item.Key += " loaded " + rec.LoadedAt.Format(time.DateOnly)
+	}
+
+	camera, ok := lookupRef(refs.cameras, rec.Camera)
+	if !ok {
+		return skipImportItem(item, "unknown camera"), nil
+	}
+
+	page, ok := lookupRef(refs.locations, rec.ArchiveLocation)
+	if !ok {
+		return skipImportItem(item, "unknown archive location"), nil
+	}
+
+	roll := models.Roll{
+		FilmStock:       stock,
+		Camera:          camera,
+		Status:          rec.Status,
+		LoadedAt:        rec.LoadedAt,
+		ExposedAt:       rec.ExposedAt,
+		DevelopedAt:     rec.DevelopedAt,
+		ArchiveLocation: page,
+		Notes:           rec.Notes,
+		UserID:          userID,
+	}
+
+	// In a dry run a new film stock has no ID yet
+	if err := s.validate.StructExcept(roll, "FilmStockID"); err != nil {
+		return invalidImportItem(ctx, item, err), nil
+	}
+
+	if rec.Status == models.RollLoaded {
+		if camera == nil {
+			return skipImportItem(item, ErrRollNeedsCamera.Message), nil
+		}
+		if camera.CameraFormat != stock.Format {
+			return skipImportItem(item, ErrRollWrongFormat.Message), nil
+		}
+	}
+	if page != nil {
+		if rec.Status != models.RollDeveloped {
+			return skipImportItem(item, ErrRollNotDeveloped.Message), nil
+		}
+		if page.Kind != models.ArchivePage {
+			return skipImportItem(item, ErrRollNotOnPage.Message), nil
+		}
+	}
+
+	existing := index.match(stock, rec.LoadedAt)
+	if rec.Status == models.RollLoaded {
+		if other := index.loaded[camera]; other != nil && other != existing {
+			return skipImportItem(item, ErrCameraLoaded.Message), nil
+		}
+	}
+
+	repo := repositories.NewRollRepo(db)
+
+	if existing == nil {
+		item.Action = dtos.ImportCreate
+		if !dryRun {
+			roll.FilmStockID = stock.ID
+			if camera != nil {
+				roll.CameraID = &camera.ID
+			}
+			if page != nil {
+				roll.ArchiveLocationID = &page.ID
+			}
+			if err := repo.CreateRoll(ctx, &roll); err != nil {
+				return item, err
+			}
+			item.ID = roll.ID
+		}
+		index.setLoaded(&roll)
+		setRef(refs.rolls, rec.Ref, &roll)
+		return item, nil
+	}
+
+	item.ID = existing.ID
+	setRef(refs.rolls, rec.Ref, existing)
+
+	updates := map[string]any{}
+	if existing.Status != rec.Status {
+		if rollStages[rec.Status] < rollStages[existing.Status] {
+			return skipImportItem(item, ErrRollStatusBackward.Message), nil
+		}
+		updates["status"] = rec.Status
+	}
+	if camera != nil && existing.Camera != camera {
+		updates["camera_id"] = camera.ID
+	}
+	if timeChanged(existing.LoadedAt, rec.LoadedAt) {
+		updates["loaded_at"] = *rec.LoadedAt
+	}
+	if timeChanged(existing.ExposedAt, rec.ExposedAt) {
+		updates["exposed_at"] = *rec.ExposedAt
+	}
+	if timeChanged(existing.DevelopedAt, rec.DevelopedAt) {
+		updates["developed_at"] = *rec.DevelopedAt
+	}
+	refiled := page != nil && existing.ArchiveLocation != page
+	if refiled {
+		updates["archive_location_id"] = page.ID
+	}
+	if rec.Notes != nil && (existing.Notes == nil || *existing.Notes != *rec.Notes) {
+		updates["notes"] = *rec.Notes
+	}
+
+	item, err := updateImportItem(item, updates, dryRun, func() error {
+		if refiled {
+			// The strips of the old page don't hold its frames any more
+			err := repositories.NewFrameRepo(db).UnfileByRollID(ctx, existing.ID)
+			if err != nil {
+				return err
+			}
+		}
+		return repo.UpdateRoll(ctx, existing, updates)
+	})
+	if err != nil {
+		return item, err
+	}
+
+	// Later records are checked against the roll as imported
+	index.unsetLoaded(existing)
+	existing.Status = rec.Status
+	if camera != nil {
+		existing.Camera = camera
+	}
+	if page != nil {
+		existing.ArchiveLocation = page
+	}
+	index.setLoaded(existing)
+
+	return item, nil
+}
+
+func (s *CollectionService) importFrame(ctx context.Context, repo *repositories.FrameRepo, index map[frameKey]*models.Frame, refs *importRefs, userID uint, rec dtos.FrameRecord, dryRun bool) (dtos.ImportItemResult, error) {
+	item := dtos.ImportItemResult{Kind: "frame", Key: fmt.Sprintf("%s #%d", rec.Roll, rec.Number)}
+
+	roll, ok := refs.rolls[rec.Roll]
+	if !ok {
+		return skipImportItem(item, "unknown roll"), nil
+	}
+
+	strip, ok := lookupRef(refs.locations, rec.Strip)
+	if !ok {
+		return skipImportItem(item, "unknown archive location"), nil
+	}
+	if strip != nil && (strip.Kind != models.ArchiveStrip || roll.ArchiveLocation == nil || strip.Parent != roll.ArchiveLocation) {
+		return skipImportItem(item, ErrStripNotOnPage.Message), nil
+	}
+
+	frame := models.Frame{
+		Roll:   roll,
+		Number: rec.Number,
+		Strip:  strip,
+		Title:  rec.Title,
+		Notes:  rec.Notes,
+		UserID: userID,
+	}
+
+	if err := s.validate.Struct(frame); err != nil {
+		return invalidImportItem(ctx, item, err), nil
+	}
+
+	key := frameKey{roll, rec.Number}
+	existing, ok := index[key]
+	if !ok {
+		item.Action = dtos.ImportCreate
+		if !dryRun {
+			frame.RollID = roll.ID
+			if strip != nil {
+				frame.StripID = &strip.ID
+			}
+			if err := repo.CreateFrame(ctx, &frame); err != nil {
+				return item, err
+			}
+			item.ID = frame.ID
+		}
+		index[key] = &frame
+		setRef(refs.frames, rec.Ref, &frame)
+		return item, nil
+	}
+
+	item.ID = existing.ID
+	setRef(refs.frames, rec.Ref, existing)
+
+	updates := map[string]any{}
+	if strip != nil && existing.Strip != strip {
+		updates["strip_id"] = strip.ID
+		existing.Strip = strip
+	}
+	if rec.Title != nil && (existing.Title == nil || *existing.Title != *rec.Title) {
+		updates["title"] = *rec.Title
+	}
+	if rec.Notes != nil && (existing.Notes == nil || *existing.Notes != *rec.Notes) {
+		updates["notes"] = *rec.Notes
+	}
+
+	return updateImportItem(item, updates, dryRun, func() error {
+		return repo.UpdateFrame(ctx, existing, updates)
+	})
+}
+
+// importPrint keeps the version and changes of the print. A print that is
+// already there only takes the notes, its settings are as it was printed.
+func (s *CollectionService) importPrint(ctx context.Context, repo *repositories.PrintRepo, index map[printKey]*models.Print, refs *importRefs, userID uint, rec dtos.PrintRecord, dryRun bool) (dtos.ImportItemResult, error) {
+	item := dtos.ImportItemResult{Kind: "print", Key: fmt.Sprintf("%s v%d", rec.Frame, rec.Version)}
+
+	frame, ok := refs.frames[rec.Frame]
+	if !ok {
+		return skipImportItem(item, "unknown frame"), nil
+	}
+
+	previous, ok := lookupRef(refs.prints, rec.Previous)
+	if !ok {
+		return skipImportItem(item, "unknown print"), nil
+	}
+	if previous != nil && (previous.Frame != frame || previous.Version >= rec.Version) {
+		return skipImportItem(item, "the previous print must be an earlier version of the same frame"), nil
+	}
+	if rec.Version < 1 {
+		return skipImportItem(item, "invalid version"), nil
+	}
+
+	print := models.Print{
+		Frame:            frame,
+		Version:          rec.Version,
+		Previous:         previous,
+		Paper:            rec.Paper,
+		PaperSize:        rec.PaperSize,
+		Contrast:         rec.Contrast,
+		EnlargerHeightCM: rec.EnlargerHeightCM,
+		Aperture:         rec.Aperture,
+		ExposureSeconds:  rec.ExposureSeconds,
+		TestStrip:        rec.TestStrip,
+		DodgeBurn:        rec.DodgeBurn,
+		Toning:           rec.Toning,
+		Notes:            rec.Notes,
+		Changes:          rec.Changes,
+		UserID:           userID,
+	}
+
+	// In a dry run a new frame has no ID yet
+	if err := s.validate.StructExcept(print, "FrameID"); err != nil {
+		return invalidImportItem(ctx, item, err), nil
+	}
+
+	key := printKey{frame, rec.Version}
+	existing, ok := index[key]
+	if !ok {
+		item.Action = dtos.ImportCreate
+		if !dryRun {
+			print.FrameID = frame.ID
+			if previous != nil {
+				print.PreviousID = &previous.ID
+			}
+			if err := repo.CreatePrint(ctx, &print); err != nil {
+				return item, err
+			}
+			item.ID = print.ID
+		}
+		index[key] = &print
+		setRef(refs.prints, rec.Ref, &print)
+		return item, nil
+	}
+
+	item.ID = existing.ID
+	setRef(refs.prints, rec.Ref, existing)
+
+	updates := map[string]any{}
+	if rec.Notes != nil && (existing.Notes == nil || *existing.Notes != *rec.Notes) {
+		updates["notes"] = *rec.Notes
+	}
+
+	return updateImportItem(item, updates, dryRun, func() error {
+		return repo.UpdatePrint(ctx, existing, updates)
+	})
+}
+
+func (s *CollectionService) importKit(ctx context.Context, repo *repositories.KitRepo, index map[string]*models.Kit, refs *importRefs, userID uint, rec dtos.KitRecord, dryRun bool) (dtos.ImportItemResult, error) {
+	item := dtos.ImportItemResult{Kind: "kit", Key: rec.Name}
+
+	var cameras []models.Camera
+	for _, ref := range rec.Cameras {
+		camera, ok := refs.cameras[ref]
+		if !ok {
+			return skipImportItem(item, "unknown camera"), nil
+		}
+		cameras = append(cameras, *camera)
+	}
+
+	var lenses []models.Lens
+	for _, ref := range rec.Lenses {
+		lens, ok := refs.lenses[ref]
+		if !ok {
+			return skipImportItem(item, "unknown lens"), nil
+		}
+		lenses = append(lenses, *lens)
+	}
+
+	if err := s.validate.Struct(dtos.KitRequest{Name: rec.Name, Notes: rec.Notes}); err != nil {
+		return invalidImportItem(ctx, item, err), nil
+	}
+
+	key := strings.ToLower(rec.Name)
+	existing, ok := index[key]
+	if !ok {
+		kit := models.Kit{Name: rec.Name, Notes: rec.Notes, Cameras: cameras, Lenses: lenses, UserID: userID}
+
+		item.Action = dtos.ImportCreate
+		if !dryRun {
+			if err := repo.CreateKit(ctx, &kit); err != nil {
+				return item, err
+			}
+			item.ID = kit.ID
+		}
+		index[key] = &kit
+		setRef(refs.kits, rec.Ref, &kit)
+		return item, nil
+	}
+
+	item.ID = existing.ID
+	setRef(refs.kits, rec.Ref, existing)
+
+	updates := map[string]any{}
+	if rec.Notes != nil && (existing.Notes == nil || *existing.Notes != *rec.Notes) {
+		updates["notes"] = *rec.Notes
+	}
+
+	camerasChanged := !sameMembers(existing.Cameras, cameras, func(c models.Camera) uint { return c.ID })
+	lensesChanged := !sameMembers(existing.Lenses, lenses, func(l models.Lens) uint { return l.ID })
+
+	if len(updates) == 0 && !camerasChanged && !lensesChanged {
+		return skipImportItem(item, "unchanged"), nil
+	}
+
+	item.Action = dtos.ImportUpdate
+	if dryRun {
+		return item, nil
+	}
+
+	if len(updates) > 0 {
+		if err := repo.UpdateKit(ctx, existing, updates); err != nil {
+			return item, err
+		}
+	}
+	if camerasChanged {
+		if err := repo.ReplaceCameras(ctx, existing, cameras); err != nil {
+			return item, err
+		}
+	}
+	if lensesChanged {
+		if err := repo.ReplaceLenses(ctx, existing, lenses); err != nil {
+			return item, err
+		}
+	}
+
+	return item, nil
+}
+
+func (s *CollectionService) importShoot(ctx context.Context, repo *repositories.ShootRepo, index map[string]*models.Shoot, refs *importRefs, userID uint, rec dtos.ShootRecord, dryRun bool) (dtos.ImportItemResult, error) {
+	item := dtos.ImportItemResult{Kind: "shoot", Key: strings.TrimSpace(rec.Title + " " + rec.Date)}
+
+	kit, ok := lookupRef(refs.kits, rec.Kit)
+	if !ok {
+		return skipImportItem(item, "unknown kit"), nil
+	}
+
+	shoot := models.Shoot{
+		Title:     rec.Title,
+		Date:      rec.Date,
+		Latitude:  rec.Latitude,
+		Longitude: rec.Longitude,
+		Timezone:  rec.Timezone,
+		Kit:       kit,
+		Notes:     rec.Notes,
+		UserID:    userID,
+	}
+
+	if err := s.validate.Struct(shoot); err != nil {
+		return invalidImportItem(ctx, item, err), nil
+	}
+
+	key := shootKey(rec.Title, rec.Date)
+	existing, ok := index[key]
+	if !ok {
+		item.Action = dtos.ImportCreate
+		if !dryRun {
+			if kit != nil {
+				shoot.KitID = &kit.ID
+			}
+			if err := repo.CreateShoot(ctx, &shoot); err != nil {
+				return item, err
+			}
+			item.ID = shoot.ID
+		}
+		index[key] = &shoot
+		return item, nil
+	}
+
+	item.ID = existing.ID
+
+	updates := map[string]any{}
+	if existing.Latitude != rec.Latitude {
+		updates["latitude"] = rec.Latitude
+	}
+	if existing.Longitude != rec.Longitude {
+		updates["longitude"] = rec.Longitude
+	}
+	if existing.Timezone != rec.Timezone {
+		updates["timezone"] = rec.Timezone
+	}
+	if kit != nil && (existing.KitID == nil || *existing.KitID != kit.ID) {
+		updates["kit_id"] = kit.ID
+	}
+	if rec.Notes != nil && (existing.Notes == nil || *existing.Notes != *rec.Notes) {
+		updates["notes"] = *rec.Notes
+	}
+
+	return updateImportItem(item, updates, dryRun, func() error {
+		return repo.UpdateShoot(ctx, existing, updates)
+	})
+}
+
+func skipImportItem(item dtos.ImportItemResult, reason string) dtos.ImportItemResult {
+	item.Action = dtos.ImportSkip
+	item.Reason = reason
+	return item
+}
+
+func invalidImportItem(ctx context.Context, item dtos.ImportItemResult, err error) dtos.ImportItemResult {
+	item = skipImportItem(item, "invalid record")
+	item.Errors = helpers.ParseValidationErrors(i18n.FromContext(ctx), err)
+	return item
+}
+
+// updateImportItem finishes the item of a matched record: skipped when
+// nothing changes, otherwise updated through update unless dryRun
+func updateImportItem(item dtos.ImportItemResult, updates map[string]any, dryRun bool, update func() error) (dtos.ImportItemResult, error) {
+	if len(updates) == 0 {
+		return skipImportItem(item, "unchanged"), nil
+	}
+
+	item.Action = dtos.ImportUpdate
+	if !dryRun {
+		if err := update(); err != nil {
+			return item, err
+		}
+	}
+
+	return item, nil
+}
+
+func filmStockKey(manufacturer, name string, format models.CameraFormat) string {
+	return strings.ToLower(fmt.Sprintf("%s|%s|%s", manufacturer, name, format))
+}
+
+func shootKey(title, date string) string {
+	return strings.ToLower(title + "|" + date)
+}
+
+// archiveKey tells locations apart by label among the same kind in one parent
+type archiveKey struct {
+	parent *models.ArchiveLocation
+	kind   models.ArchiveKind
+	label  string
+}
+
+func newArchiveKey(parent *models.ArchiveLocation, kind models.ArchiveKind, label string) archiveKey {
+	return archiveKey{parent, kind, strings.ToLower(label)}
+}
+
+type frameKey struct {
+	roll   *models.Roll
+	number int
+}
+
+type printKey struct {
+	frame   *models.Frame
+	version int
+}
+
+// rollIndex finds the roll an import record refers to by film stock and load
+// time. Rolls of a stock loaded at the same time are claimed one by one.
+// It also tracks which roll each camera holds.
+type rollIndex struct {
+	byStock map[rollKey][]*models.Roll
+	loaded  map[*models.Camera]*models.Roll
+}
+
+type rollKey struct {
+	stock    *models.FilmStock
+	loadedAt int64
+}
+
+func newRollIndex() *rollIndex {
+	return &rollIndex{
+		byStock: map[rollKey][]*models.Roll{},
+		loaded:  map[*models.Camera]*models.Roll{},
+	}
+}
+
+func newRollKey(stock *models.FilmStock, loadedAt *time.Time) rollKey {
+	if loadedAt == nil {
+		return rollKey{stock: stock}
+	}
+	return rollKey{stock, loadedAt.Unix()}
+}
+
+func (idx *rollIndex) add(roll *models.Roll) {
+	key := newRollKey(roll.FilmStock, roll.LoadedAt)
+	idx.byStock[key] = append(idx.byStock[key], roll)
+	idx.setLoaded(roll)
+}
+
+func (idx *rollIndex) match(stock *models.FilmStock, loadedAt *time.Time) *models.Roll {
+	key := newRollKey(stock, loadedAt)
+	rolls := idx.byStock[key]
+	if len(rolls) == 0 {
+		return nil
+	}
+
+	idx.byStock[key] = rolls[1:]
+	return rolls[0]
+}
+
+func (idx *rollIndex) setLoaded(roll *models.Roll) {
+	if roll.Status == models.RollLoaded && roll.Camera != nil {
+		idx.loaded[roll.Camera] = roll
+	}
+}
+
+func (idx *rollIndex) unsetLoaded(roll *models.Roll) {
+	if roll.Camera != nil && idx.loaded[roll.Camera] == roll {
+		delete(idx.loaded, roll.Camera)
+	}
+}
+
+// timeChanged reports whether the imported time differs from the stored one
+// at the database's precision. A missing imported time changes nothing.
+func timeChanged(stored, imported *time.Time) bool {
+	if imported == nil {
+		return false
+	}
+	return stored == nil || !stored.Truncate(time.Second).Equal(imported.Truncate(time.Second))
+}
+
+// sameMembers reports whether both lists hold the same records. In a dry run
+// new records have ID 0, which no stored member has.
+func sameMembers[T any](stored, imported []T, id func(T) uint) bool {
+	if len(stored) != len(imported) {
+		return false
+	}
+
+	ids := idSet(stored, id)
+	for _, record := range imported {
+		if !ids[id(record)] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,538 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/georgiev098/film-manager/backend/internal/audit"
+	"github.com/georgiev098/film-manager/backend/internal/dtos"
+	"github.com/georgiev098/film-manager/backend/internal/helpers"
+	"github.com/georgiev098/film-manager/backend/internal/i18n"
+	"github.com/georgiev098/film-manager/backend/internal/importers"
+	"github.com/georgiev098/film-manager/backend/internal/models"
+	"github.com/georgiev098/film-manager/backend/internal/repositories"
+	"github.com/go-playground/validator/v10"
+	"gorm.io/gorm"
+)
+
+// collectionVersion 2 added the film side; version 1 files still import
+const collectionVersion = 2
+
+type CollectionService struct {
+	db       *gorm.DB
+	validate *validator.Validate
+	quota    *ItemQuota
+	audit    *audit.Service
+}
+
+func NewCollectionService(db *gorm.DB, validate *validator.Validate, quota *ItemQuota, audit *audit.Service) *CollectionService {
+	return &CollectionService{
+		db:       db,
+		validate: validate,
+		quota:    quota,
+		audit:    audit,
+	}
+}
+
+// Export collects all cameras and lenses owned by the user, with their film
+// stocks, rolls, frames, prints, kits, shoots and negative archive
+func (s *CollectionService) Export(ctx context.Context, userID uint) (*dtos.Collection, error) {
+	cameras, err := repositories.NewCameraRepo(s.db).GetAllByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	lenses, err := repositories.NewLensRepo(s.db).GetAllByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	collection := &dtos.Collection{
+		Version: collectionVersion,
+		Cameras: make([]dtos.CameraRecord, 0, len(cameras)),
+		Lenses:  make([]dtos.LensRecord, 0, len(lenses)),
+	}
+
+	for _, c := range cameras {
+		collection.Cameras = append(collection.Cameras, dtos.CameraRecord{
+			Ref:          exportRef(c.ID),
+			Brand:        c.Brand,
+			CameraModel:  c.CameraModel,
+			CameraFormat: c.CameraFormat,
+			Year:         c.Year,
+			SerialNumber: c.SerialNumber,
+			Notes:        c.Notes,
+			ImageURL:     c.ImageURL,
+		})
+	}
+
+	for _, l := range lenses {
+		collection.Lenses = append(collection.Lenses, dtos.LensRecord{
+			Ref:                exportRef(l.ID),
+			Manufacturer:       l.Manufacturer,
+			LensType:           l.LensType,
+			ImageStabilization: l.ImageStabilization,
+			FocalLengthMin:     l.FocalLengthMin,
+			FocalLengthMax:     l.FocalLengthMax,
+			MinApertureStr:     l.MinApertureStr,
+			MaxApertureStr:     l.MaxApertureStr,
+			Mount:              l.Mount,
+			ImageURL:           l.ImageURL,
+			Notes:              l.Notes,
+		})
+	}
+
+	err = s.exportFilm(ctx, userID, collection, cameras, lenses)
+	if err != nil {
+		return nil, err
+	}
+
+	return collection, nil
+}
+
+// Import merges a collection into the user's records. Cameras are matched by serial
+// number, then brand+model when either side has no serial; lenses by manufacturer, focal range, aperture and mount.
+// With dryRun nothing is written and the report describes what would happen.
+func (s *CollectionService) Import(ctx context.Context, userID uint, collection *dtos.Collection, dryRun bool) (*dtos.ImportReport, error) {
+	if dryRun {
+		report, err := s.importInto(ctx, s.db, userID, collection, true, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		err = s.quota.Check(ctx, s.db, userID, createdGear(report))
+		if err != nil {
+			return nil, err
+		}
+
+		return report, nil
+	}
+
+	var report *dtos.ImportReport
+	var events []audit.Entry
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		report, err = s.importInto(ctx, tx, userID, collection, false, &events)
+		if err != nil {
+			return err
+		}
+
+		// Counts what was just created, so one import cannot pass the limit
+		return s.quota.Check(ctx, tx, userID, 0)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Recorded after the commit so a rolled back import leaves no events
+	for _, event := range events {
+		s.audit.Record(ctx, event)
+	}
+
+	s.audit.Record(ctx, audit.Entry{
+		ActorID: userID,
+		UserID:  userID,
+		Action:  models.AuditCollectionImport,
+		Details: map[string]any{"created": report.Created, "updated": report.Updated, "skipped": report.Skipped},
+	})
+
+	return report, nil
+}
+
+// importInto applies the collection through db. Unless dryRun, the per-item
+// audit events of what was written are appended to events.
+func (s *CollectionService) importInto(ctx context.Context, db *gorm.DB, userID uint, collection *dtos.Collection, dryRun bool, events *[]audit.Entry) (*dtos.ImportReport, error) {
+	cameraRepo := repositories.NewCameraRepo(db)
+	lensRepo := repositories.NewLensRepo(db)
+
+	report := &dtos.ImportReport{DryRun: dryRun, Items: []dtos.ImportItemResult{}}
+
+	cameras, err := cameraRepo.GetAllByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	refs := newImportRefs()
+
+	cameraIndex := newCameraIndex()
+	camerasByID := map[uint]*models.Camera{}
+	for i := range cameras {
+		cameraIndex.add(&cameras[i])
+		camerasByID[cameras[i].ID] = &cameras[i]
+	}
+
+	for _, rec := range collection.Cameras {
+		item, err := s.importCamera(ctx, cameraRepo, cameraIndex, refs, userID, rec, dryRun, events)
+		if err != nil {
+			return nil, err
+		}
+		addImportItem(report, item)
+	}
+
+	lenses, err := lensRepo.GetAllByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	lensIndex := map[string]*models.Lens{}
+	for i := range lenses {
+		lensIndex[lensKey(lenses[i].Manufacturer, lenses[i].FocalLengthMin, lenses[i].FocalLengthMax, lenses[i].MaxApertureStr, lenses[i].Mount)] = &lenses[i]
+	}
+
+	for _, rec := range collection.Lenses {
+		item, err := s.importLens(ctx, lensRepo, lensIndex, refs, userID, rec, dryRun, events)
+		if err != nil {
+			return nil, err
+		}
+		addImportItem(report, item)
+	}
+
+	err = s.importFilm(ctx, db, userID, collection, camerasByID, refs, dryRun, report)
+	if err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+func (s *CollectionService) importCamera(ctx context.Context, repo *repositories.CameraRepo, index *cameraIndex, refs *importRefs, userID uint, rec dtos.CameraRecord, dryRun bool, events *[]audit.Entry) (dtos.ImportItemResult, error) {
+	item := dtos.ImportItemResult{Kind: "camera", Key: strings.TrimSpace(rec.Brand + " " + rec.CameraModel)}
+
+	camera := models.Camera{
+		Brand:        rec.Brand,
+		CameraModel:  rec.CameraModel,
+		CameraFormat: rec.CameraFormat,
+		Year:         rec.Year,
+		SerialNumber: rec.SerialNumber,
+		Notes:        rec.Notes,
+		ImageURL:     rec.ImageURL,
+		UserID:       userID,
+	}
+
+	if err := s.validate.Struct(camera); err != nil {
+		item.Action = dtos.ImportSkip
+		item.Reason = "invalid record"
+		item.Errors = helpers.ParseValidationErrors(i18n.FromContext(ctx), err)
+		return item, nil
+	}
+
+	existing := index.match(rec)
+	if existing == nil {
+		item.Action = dtos.ImportCreate
+		if !dryRun {
+			if err := repo.CreateCamera(ctx, &camera); err != nil {
+				return item, err
+			}
+			item.ID = camera.ID
+
+			*events = append(*events, audit.Entry{
+				ActorID:  userID,
+				UserID:   userID,
+				Action:   models.AuditCameraCreate,
+				Target:   "camera",
+				TargetID: camera.ID,
+				Details:  map[string]any{"after": camera, "source": "import"},
+			})
+		}
+		index.add(&camera)
+		setRef(refs.cameras, rec.Ref, &camera)
+		return item, nil
+	}
+
+	item.ID = existing.ID
+	setRef(refs.cameras, rec.Ref, existing)
+	before := cameraColumns(existing)
+
+	updates := map[string]any{}
+	if existing.Brand != rec.Brand {
+		updates["brand"] = rec.Brand
+	}
+	if existing.CameraModel != rec.CameraModel {
+		updates["camera_model"] = rec.CameraModel
+	}
+	if existing.CameraFormat != rec.CameraFormat {
+		updates["camera_format"] = rec.CameraFormat
+	}
+	if rec.Year != nil && (existing.Year == nil || *existing.Year != *rec.Year) {
+		updates["year"] = *rec.Year
+	}
+	if rec.SerialNumber != nil && (existing.SerialNumber == nil || *existing.SerialNumber != *rec.SerialNumber) {
+		updates["serial_number"] = *rec.SerialNumber
+		// Claim the serial so later records with another one don't match this body
+		index.setSerial(existing, *rec.SerialNumber)
+	}
+	if rec.Notes != nil && (existing.Notes == nil || *existing.Notes != *rec.Notes) {
+		updates["notes"] = *rec.Notes
+	}
+	if rec.ImageURL != nil && (existing.ImageURL == nil || *existing.ImageURL != *rec.ImageURL) {
+		updates["image_url"] = *rec.ImageURL
+	}
+
+	if len(updates) == 0 {
+		item.Action = dtos.ImportSkip
+		item.Reason = "unchanged"
+		return item, nil
+	}
+
+	item.Action = dtos.ImportUpdate
+	if !dryRun {
+		changes := audit.Diff(before, updates)
+
+		if err := repo.UpdateCamera(ctx, existing, updates); err != nil {
+			return item, err
+		}
+
+		*events = append(*events, audit.Entry{
+			ActorID:  userID,
+			UserID:   userID,
+			Action:   models.AuditCameraUpdate,
+			Target:   "camera",
+			TargetID: existing.ID,
+			Details:  map[string]any{"changes": changes, "source": "import"},
+		})
+	}
+
+	return item, nil
+}
+
+func (s *CollectionService) importLens(ctx context.Context, repo *repositories.LensRepo, index map[string]*models.Lens, refs *importRefs, userID uint, rec dtos.LensRecord, dryRun bool, events *[]audit.Entry) (dtos.ImportItemResult, error) {
+	item := dtos.ImportItemResult{
+		Kind: "lens",
+		Key:  fmt.Sprintf("%s %d-%dmm %s %s", rec.Manufacturer, rec.FocalLengthMin, rec.FocalLengthMax, rec.MaxApertureStr, rec.Mount),
+	}
+
+	lens := models.Lens{
+		Manufacturer:       rec.Manufacturer,
+		LensType:           rec.LensType,
+		ImageStabilization: rec.ImageStabilization,
+		FocalLengthMin:     rec.FocalLengthMin,
+		FocalLengthMax:     rec.FocalLengthMax,
+		MinApertureStr:     rec.MinApertureStr,
+		MaxApertureStr:     rec.MaxApertureStr,
+		Mount:              rec.Mount,
+		ImageURL:           rec.ImageURL,
+		Notes:              rec.Notes,
+		UserID:             userID,
+	}
+
+	if err := s.validate.Struct(lens); err != nil {
+		item.Action = dtos.ImportSkip
+		item.Reason = "invalid record"
+		item.Errors = helpers.ParseValidationErrors(i18n.FromContext(ctx), err)
+		return item, nil
+	}
+
+	key := lensKey(rec.Manufacturer, rec.FocalLengthMin, rec.FocalLengthMax, rec.MaxApertureStr, rec.Mount)
+	existing, ok := index[key]
+	if !ok {
+		item.Action = dtos.ImportCreate
+		if !dryRun {
+			if err := repo.CreateLens(ctx, &lens); err != nil {
+				return item, err
+			}
+			item.ID = lens.ID
+
+			*events = append(*events, audit.Entry{
+				ActorID:  userID,
+				UserID:   userID,
+				Action:   models.AuditLensCreate,
+				Target:   "lens",
+				TargetID: lens.ID,
+				Details:  map[string]any{"after": lens, "source": "import"},
+			})
+		}
+		index[key] = &lens
+		setRef(refs.lenses, rec.Ref, &lens)
+		return item, nil
+	}
+
+	item.ID = existing.ID
+	setRef(refs.lenses, rec.Ref, existing)
+
+	updates := map[string]any{}
+	if existing.LensType != rec.LensType {
+		updates["lens_type"] = rec.LensType
+	}
+	if existing.ImageStabilization != rec.ImageStabilization {
+		updates["image_stabilization"] = rec.ImageStabilization
+	}
+	if existing.MinApertureStr != rec.MinApertureStr {
+		updates["min_aperture_str"] = rec.MinApertureStr
+	}
+	if rec.ImageURL != nil && (existing.ImageURL == nil || *existing.ImageURL != *rec.ImageURL) {
+		updates["image_url"] = *rec.ImageURL
+	}
+	if rec.Notes != nil && (existing.Notes == nil || *existing.Notes != *rec.Notes) {
+		updates["notes"] = *rec.Notes
+	}
+
+	if len(updates) == 0 {
+		item.Action = dtos.ImportSkip
+		item.Reason = "unchanged"
+		return item, nil
+	}
+
+	item.Action = dtos.ImportUpdate
+	if !dryRun {
+		changes := audit.Diff(lensColumns(existing), updates)
+
+		if err := repo.UpdateLens(ctx, existing, updates); err != nil {
+			return item, err
+		}
+
+		*events = append(*events, audit.Entry{
+			ActorID:  userID,
+			UserID:   userID,
+			Action:   models.AuditLensUpdate,
+			Target:   "lens",
+			TargetID: existing.ID,
+			Details:  map[string]any{"changes": changes, "source": "import"},
+		})
+	}
+
+	return item, nil
+}
+
+// createdGear counts the cameras and lenses in the report that are created,
+// the items the quota limits
+func createdGear(report *dtos.ImportReport) int {
+	created := 0
+	for _, item := range report.Items {
+		if item.Action == dtos.ImportCreate && (item.Kind == "camera" || item.Kind == "lens") {
+			created++
+		}
+	}
+	return created
+}
+
+func addImportItem(report *dtos.ImportReport, item dtos.ImportItemResult) {
+	switch item.Action {
+	case dtos.ImportCreate:
+		report.Created++
+	case dtos.ImportUpdate:
+		report.Updated++
+	default:
+		report.Skipped++
+	}
+	report.Items = append(report.Items, item)
+}
+
+// cameraIndex finds the user's camera an import record refers to. Several
+// bodies of the same model may exist, told apart by serial number.
+type cameraIndex struct {
+	bySerial map[string]*models.Camera
+	byModel  map[string][]*models.Camera
+}
+
+func newCameraIndex() *cameraIndex {
+	return &cameraIndex{
+		bySerial: map[string]*models.Camera{},
+		byModel:  map[string][]*models.Camera{},
+	}
+}
+
+func (idx *cameraIndex) add(camera *models.Camera) {
+	if hasSerial(camera.SerialNumber) {
+		idx.bySerial[strings.ToLower(*camera.SerialNumber)] = camera
+	}
+	key := cameraModelKey(camera.Brand, camera.CameraModel)
+	idx.byModel[key] = append(idx.byModel[key], camera)
+}
+
+func (idx *cameraIndex) setSerial(camera *models.Camera, serial string) {
+	camera.SerialNumber = &serial
+	idx.bySerial[strings.ToLower(serial)] = camera
+}
+
+// match returns the camera with the record's serial number. Without a serial
+// match it falls back to brand+model, but a record and a camera that both
+// carry serials are different bodies, so then the record is a new camera.
+func (idx *cameraIndex) match(rec dtos.CameraRecord) *models.Camera {
+	recHasSerial := hasSerial(rec.SerialNumber)
+	if recHasSerial {
+		if camera, ok := idx.bySerial[strings.ToLower(*rec.SerialNumber)]; ok {
+			return camera
+		}
+	}
+
+	for _, camera := range idx.byModel[cameraModelKey(rec.Brand, rec.CameraModel)] {
+		if !recHasSerial || !hasSerial(camera.SerialNumber) {
+			return camera
+		}
+	}
+
+	return nil
+}
+
+func cameraModelKey(brand, model string) string {
+	return strings.ToLower(brand + "|" + model)
+}
+
+func hasSerial(serial *string) bool {
+	return serial != nil && *serial != ""
+}
+
+func lensKey(manufacturer string, minFocal, maxFocal int, maxAperture, mount string) string {
+	return strings.ToLower(fmt.Sprintf("%s|%d|%d|%s|%s", manufacturer, minFocal, maxFocal, maxAperture, mount))
+}
+
+// ImportExternal imports gear, film stocks, rolls and frames parsed from
+// another app's export. lensMap maps a lens name as the source app spells it
+// to one of the user's existing lenses; mapped lenses are not created.
+func (s *CollectionService) ImportExternal(ctx context.Context, userID uint, source string, parsed *importers.Result, lensMap map[string]uint, dryRun bool) (*dtos.ExternalImportReport, error) {
+	lensRepo := repositories.NewLensRepo(s.db)
+
+	collection := &dtos.Collection{
+		Version:    collectionVersion,
+		Cameras:    parsed.Cameras,
+		FilmStocks: parsed.FilmStocks,
+		Rolls:      parsed.Rolls,
+		Frames:     parsed.Frames,
+	}
+	var lensNames []string
+	var mapped []dtos.ImportItemResult
+
+	for _, candidate := range parsed.Lenses {
+		lensID, ok := lensMap[candidate.SourceName]
+		if !ok {
+			collection.Lenses = append(collection.Lenses, candidate.Record)
+			lensNames = append(lensNames, candidate.SourceName)
+			continue
+		}
+
+		lens, err := lensRepo.GetLensByID(ctx, lensID)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+
+		item := dtos.ImportItemResult{Kind: "lens", Key: candidate.SourceName, Action: dtos.ImportSkip, ID: lensID}
+		if err != nil || lens.UserID != userID {
+			item.Reason = "mapped lens not found"
+		} else {
+			item.Reason = "mapped to existing lens"
+		}
+		mapped = append(mapped, item)
+	}
+
+	report, err := s.Import(ctx, userID, collection, dryRun)
+	if err != nil {
+		return nil, err
+	}
+
+	// Lens items follow the cameras in collection order; label them with the
+	// source app's name so users know what to put in the lens map.
+	for i, name := range lensNames {
+		report.Items[len(collection.Cameras)+i].Key = name
+	}
+	for _, item := range mapped {
+		addImportItem(report, item)
+	}
+
+	return &dtos.ExternalImportReport{
+		Source:         source,
+		ImportReport:   *report,
+		UnmappedFields: parsed.Unmapped,
+	}, nil
+}
@@ -0,0 +1,308 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/georgiev098/film-manager/backend/internal/audit"
+	"github.com/georgiev098/film-manager/backend/internal/core"
+	"github.com/georgiev098/film-manager/backend/internal/dtos"
+	"github.com/georgiev098/film-manager/backend/internal/importers"
+	"github.com/georgiev098/film-manager/backend/internal/models"
+	"github.com/georgiev098/film-manager/backend/internal/repositories"
+	"github.com/glebarez/sqlite"
+	"github.com/go-playground/validator/v10"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestCollectionRoundTrip(t *testing.T) {
+	ctx := context.Background()
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")+"?_pragma=foreign_keys(1)"), &gorm.Config{
+		Logger: logger.Discard,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = db.AutoMigrate(&models.User{}, &models.AuditEvent{}, &models.Camera{}, &models.Lens{}, &models.FilmStock{}, &models.ArchiveLocation{},
+		&models.Roll{}, &models.Frame{}, &models.Print{}, &models.Kit{}, &models.Shoot{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	newUser := func(email string) *models.User {
+		t.Helper()
+
+		u := &models.User{Email: email, FirstName: "Ansel", LastName: "Adams", Role: models.RoleUser}
+		if err := db.Create(u).Error; err != nil {
+			t.Fatal(err)
+		}
+		return u
+	}
+
+	create := func(records ...any) {
+		t.Helper()
+
+		for _, r := range records {
+			if err := db.Omit("User").Create(r).Error; err != nil {
+				t.Fatalf("create %T: %v", r, err)
+			}
+		}
+	}
+
+	user := newUser("user@example.com")
+
+	camera := &models.Camera{Brand: "Nikon", CameraModel: "FM2", CameraFormat: models.Format35mm, UserID: user.ID}
+	lens := &models.Lens{Manufacturer: "Nikon", LensType: models.LensAnalog, FocalLengthMin: 50, FocalLengthMax: 50, MinApertureStr: "f/16", MaxApertureStr: "f/1.4", Mount: "F", UserID: user.ID}
+	stock := &models.FilmStock{Manufacturer: "Kodak", Name: "Tri-X", ISO: 400, Format: models.Format35mm, Process: models.ProcessBW, UserID: user.ID}
+	create(camera, lens, stock)
+
+	box := &models.ArchiveLocation{Kind: models.ArchiveBox, Label: "2", UserID: user.ID}
+	create(box)
+	binder := &models.ArchiveLocation{Kind: models.ArchiveBinder, Label: "1998", ParentID: &box.ID, UserID: user.ID}
+	create(binder)
+	page := &models.ArchiveLocation{Kind: models.ArchivePage, Label: "12", Position: 12, ParentID: &binder.ID, UserID: user.ID}
+	create(page)
+	strip := &models.ArchiveLocation{Kind: models.ArchiveStrip, Label: "3", Position: 3, ParentID: &page.ID, UserID: user.ID}
+	create(strip)
+
+	loadedAt := time.Date(2026, 3, 1, 9, 30, 0, 0, time.UTC)
+	developedAt := time.Date(2026, 4, 2, 18, 0, 0, 0, time.UTC)
+	developed := &models.Roll{FilmStockID: stock.ID, CameraID: &camera.ID, Status: models.RollDeveloped, LoadedAt: &loadedAt, DevelopedAt: &developedAt, ArchiveLocationID: &page.ID, UserID: user.ID}
+	loadedAgain := loadedAt.AddDate(0, 2, 0)
+	loaded := &models.Roll{FilmStockID: stock.ID, CameraID: &camera.ID, Status: models.RollLoaded, LoadedAt: &loadedAgain, UserID: user.ID}
+	create(developed, loaded)
+
+	frame := &models.Frame{RollID: developed.ID, Number: 14, StripID: &strip.ID, Title: ptr("Pier"), UserID: user.ID}
+	create(frame)
+
+	first := &models.Print{FrameID: frame.ID, Version: 1, Paper: "Ilford MGFB", PaperSize: "8x10in", ExposureSeconds: 12, UserID: user.ID}
+	create(first)
+	second := &models.Print{FrameID: frame.ID, Version: 2, PreviousID: &first.ID, Paper: "Ilford MGFB", PaperSize: "8x10in", ExposureSeconds: 15,
+		Changes: json.RawMessage(`{"exposure_seconds":{"from":12,"to":15}}`), UserID: user.ID}
+	create(second)
+
+	kit := &models.Kit{Name: "Street", Cameras: []models.Camera{*camera}, Lenses: []models.Lens{*lens}, UserID: user.ID}
+	if err := repositories.NewKitRepo(db).CreateKit(ctx, kit); err != nil {
+		t.Fatal(err)
+	}
+	create(&models.Shoot{Title: "Harbour", Date: "2026-06-21", Latitude: 42.5, Longitude: 27.47, Timezone: "Europe/Sofia", KitID: &kit.ID, UserID: user.ID})
+
+	s := NewCollectionService(db, validator.New(), NewItemQuota(db, core.UnverifiedPolicyNone, 0), audit.NewService(repositories.NewAuditRepo(db), log.New(io.Discard, "", 0)))
+
+	exported, err := s.Export(ctx, user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	counts := func(c *dtos.Collection) string {
+		return fmt.Sprintf("cameras=%d lenses=%d stocks=%d archive=%d rolls=%d frames=%d prints=%d kits=%d shoots=%d",
+			len(c.Cameras), len(c.Lenses), len(c.FilmStocks), len(c.Archive), len(c.Rolls), len(c.Frames), len(c.Prints), len(c.Kits), len(c.Shoots))
+	}
+	if got, want := counts(exported), "cameras=1 lenses=1 stocks=1 archive=4 rolls=2 frames=1 prints=2 kits=1 shoots=1"; got != want {
+		t.Fatalf("export: got %s, want %s", got, want)
+	}
+
+	for _, format := range []string{FormatJSON, FormatCSV, FormatZIP} {
+		t.Run(format, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := EncodeCollection(&buf, format, exported); err != nil {
+				t.Fatal(err)
+			}
+
+			decoded, err := DecodeCollection(format, buf.Bytes())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			target := newUser(format + "@example.com")
+
+			report, err := s.Import(ctx, target.ID, decoded, true)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if report.Created != 14 || report.Skipped != 0 {
+				t.Errorf("dry run: created %d, skipped %d, want 14 and 0: %+v", report.Created, report.Skipped, report.Items)
+			}
+
+			var rolls int64
+			db.Model(&models.Roll{}).Where("user_id = ?", target.ID).Count(&rolls)
+			if rolls != 0 {
+				t.Fatalf("dry run wrote %d rolls", rolls)
+			}
+
+			report, err = s.Import(ctx, target.ID, decoded, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if report.Created != 14 || report.Skipped != 0 {
+				t.Errorf("import: created %d, skipped %d, want 14 and 0: %+v", report.Created, report.Skipped, report.Items)
+			}
+
+			imported, err := s.Export(ctx, target.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got, want := counts(imported), counts(exported); got != want {
+				t.Fatalf("imported: got %s, want %s", got, want)
+			}
+
+			refs := map[string]string{}
+			for _, r := range imported.Rolls {
+				refs[r.Ref] = string(r.Status)
+				if r.Camera == nil || *r.Camera != imported.Cameras[0].Ref {
+					t.Errorf("%s roll: camera %v, want %s", r.Status, r.Camera, imported.Cameras[0].Ref)
+				}
+				if r.Status == models.RollDeveloped {
+					if !r.LoadedAt.Equal(loadedAt) || !r.DevelopedAt.Equal(developedAt) {
+						t.Errorf("developed roll: loaded %v, developed %v", r.LoadedAt, r.DevelopedAt)
+					}
+					if r.ArchiveLocation == nil {
+						t.Error("developed roll is not filed")
+					}
+				}
+			}
+
+			f := imported.Frames[0]
+			if refs[f.Roll] != string(models.RollDeveloped) || f.Strip == nil || f.Title == nil || *f.Title != "Pier" {
+				t.Errorf("frame: %+v", f)
+			}
+
+			// The indented JSON export reformats the changes
+			p := imported.Prints[1]
+			var changes bytes.Buffer
+			if err := json.Compact(&changes, p.Changes); err != nil {
+				t.Fatal(err)
+			}
+			if p.Version != 2 || p.Previous == nil || *p.Previous != imported.Prints[0].Ref || changes.String() != string(second.Changes) {
+				t.Errorf("reprint: %+v", p)
+			}
+
+			k := imported.Kits[0]
+			if len(k.Cameras) != 1 || len(k.Lenses) != 1 {
+				t.Errorf("kit: %+v", k)
+			}
+			if sh := imported.Shoots[0]; sh.Kit == nil || *sh.Kit != k.Ref {
+				t.Errorf("shoot: %+v", sh)
+			}
+
+			// Importing the same file again matches every record
+			report, err = s.Import(ctx, target.ID, decoded, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if report.Created != 0 || report.Updated != 0 {
+				t.Errorf("second import: created %d, updated %d, want none: %+v", report.Created, report.Updated, report.Items)
+			}
+		})
+	}
+}
+
+func TestImportExternalRolls(t *testing.T) {
+	ctx := context.Background()
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")+"?_pragma=foreign_keys(1)"), &gorm.Config{
+		Logger: logger.Discard,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = db.AutoMigrate(&models.User{}, &models.AuditEvent{}, &models.Camera{}, &models.Lens{}, &models.FilmStock{}, &models.ArchiveLocation{},
+		&models.Roll{}, &models.Frame{}, &models.Print{}, &models.Kit{}, &models.Shoot{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user := &models.User{Email: "user@example.com", FirstName: "Ansel", LastName: "Adams", Role: models.RoleUser}
+	if err := db.Create(user).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	parsed, err := importers.ExifNotesParser{}.Parse([]byte(`[
+		{"camera": {"make": "Nikon", "model": "FM2", "format": "135"},
+		 "filmStock": {"make": "Ilford", "model": "HP5 Plus", "iso": 400, "process": "B&W"},
+		 "date": "2026-03-01T09:30:00", "unloaded": "2026-03-08T17:00:00", "note": "Coast",
+		 "frames": [{"count": 1, "note": "Pier"}, {"count": 2, "shutter": "1/125"}]},
+		{"camera": {"make": "Nikon", "model": "FM2", "format": "135"},
+		 "filmStock": {"make": "Ilford", "model": "HP5 Plus", "iso": 400, "process": "B&W"},
+		 "date": "2026-04-01T10:00:00",
+		 "frames": [{"count": 1}]}
+	]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := NewCollectionService(db, validator.New(), NewItemQuota(db, core.UnverifiedPolicyNone, 0), audit.NewService(repositories.NewAuditRepo(db), log.New(io.Discard, "", 0)))
+
+	kinds := func(report *dtos.ExternalImportReport, action dtos.ImportAction) map[string]int {
+		got := map[string]int{}
+		for _, item := range report.Items {
+			if item.Action == action {
+				got[item.Kind]++
+			}
+		}
+		return got
+	}
+	want := fmt.Sprint(map[string]int{"camera": 1, "film_stock": 1, "roll": 2, "frame": 3})
+
+	report, err := s.ImportExternal(ctx, user.ID, "exif-notes", parsed, nil, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := fmt.Sprint(kinds(report, dtos.ImportCreate)); got != want {
+		t.Errorf("dry run creates %s, want %s: %+v", got, want, report.Items)
+	}
+
+	report, err = s.ImportExternal(ctx, user.ID, "exif-notes", parsed, nil, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := fmt.Sprint(kinds(report, dtos.ImportCreate)); got != want {
+		t.Errorf("import creates %s, want %s: %+v", got, want, report.Items)
+	}
+
+	rolls, err := repositories.NewRollRepo(db).GetAllByUserID(ctx, user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rolls) != 2 {
+		t.Fatalf("got %d rolls, want 2", len(rolls))
+	}
+	exposed, loaded := rolls[0], rolls[1]
+	if exposed.Status != models.RollExposed || exposed.ExposedAt == nil || exposed.Notes == nil || *exposed.Notes != "Coast" || exposed.CameraID == nil {
+		t.Errorf("first roll: %+v", exposed)
+	}
+	if loaded.Status != models.RollLoaded || loaded.CameraID == nil || loaded.FilmStock.Process != models.ProcessBW {
+		t.Errorf("second roll: %+v", loaded)
+	}
+
+	frames, err := repositories.NewFrameRepo(db).GetAllByRollID(ctx, exposed.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(frames) != 2 || frames[0].Notes == nil || *frames[0].Notes != "Pier" {
+		t.Errorf("frames of the first roll: %+v", frames)
+	}
+	if report.UnmappedFields["frame.shutter"] != 1 {
+		t.Errorf("unmapped: %v", report.UnmappedFields)
+	}
+
+	// The same file again finds what the first import created
+	report, err = s.ImportExternal(ctx, user.ID, "exif-notes", parsed, nil, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Created != 0 || report.Updated != 0 {
+		t.Errorf("second import: created %d, updated %d, want none: %+v", report.Created, report.Updated, report.Items)
+	}
+}
@@ -0,0 +1,601 @@
+package services
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/georgiev098/film-manager/backend/internal/apperrors"
+	"github.com/georgiev098/film-manager/backend/internal/core"
+	"github.com/georgiev098/film-manager/backend/internal/dtos"
+	"github.com/georgiev098/film-manager/backend/internal/helpers"
+	"github.com/georgiev098/film-manager/backend/internal/mailer"
+	"github.com/georgiev098/film-manager/backend/internal/models"
+	"github.com/georgiev098/film-manager/backend/internal/password"
+	"github.com/georgiev098/film-manager/backend/internal/repositories"
+	"gorm.io/gorm"
+)
+
+// A running request older than this is assumed abandoned and picked up again
+const staleDataRequestAfter = 30 * time.Minute
+
+var (
+	ErrDataRequestInProgress = apperrors.Conflict("a request of this kind is already in progress")
+	ErrExportNotReady        = apperrors.Conflict("export is not available for download")
+	ErrNoErasurePending      = apperrors.NotFound("no pending erasure request")
+	ErrExportNotFound        = apperrors.NotFound("export not found")
+)
+
+const exportReadme = `Film Manager data export
+========================
+
+profile.json        your account and preferences
+cameras.json        every camera you added, including deleted ones
+lenses.json         every lens you added, including deleted ones
+film_stocks.json    your film stocks, including deleted ones
+rolls.json          every roll you loaded, shot or developed
+kits.json           your kits and the gear in them
+shoots.json         your planned shoots
+frames.json         the frames you logged on your rolls
+prints.json         your darkroom prints of those frames, every version
+archive.json        the boxes, binders, pages and strips of your negative archive
+images.json         image URLs attached to your gear
+sessions.json       devices that signed in to your account
+access_tokens.json  personal access tokens (the tokens themselves are not stored)
+identities.json     linked external sign-in providers
+data_requests.json  your export and erasure requests
+audit_log.json      security and change events on your account
+collection.json     your current gear in the import format, for moving it to another account
+`
+
+// DataRequestService handles "download my data" exports and account erasure.
+// Both run in the background through Run.
+type DataRequestService struct {
+	repo       *repositories.DataRequestRepository
+	userData   *repositories.UserDataRepository
+	collection *CollectionService
+	loginGuard *LoginGuard
+	passwords  *password.Hasher
+	mailer     mailer.Mailer
+	logger     *log.Logger
+	appURL     string
+	cfg        core.DataRequests
+}
+
+func NewDataRequestService(
+	repo *repositories.DataRequestRepository,
+	userData *repositories.UserDataRepository,
+	collection *CollectionService,
+	loginGuard *LoginGuard,
+	passwords *password.Hasher,
+	mail mailer.Mailer,
+	logger *log.Logger,
+	appURL string,
+	cfg core.DataRequests,
+) *DataRequestService {
+	return &DataRequestService{
+		repo:       repo,
+		userData:   userData,
+		collection: collection,
+		loginGuard: loginGuard,
+		passwords:  passwords,
+		mailer:     mail,
+		logger:     logger,
+		appURL:     appURL,
+		cfg:        cfg,
+	}
+}
+
+// RequestExport queues a new export unless one is already pending or running
+func (s *DataRequestService) RequestExport(ctx context.Context, userID uint) (*models.DataRequest, error) {
+	_, err := s.repo.FindOpen(ctx, userID, models.DataRequestExport)
+	if err == nil {
+		return nil, ErrDataRequestInProgress
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	req := &models.DataRequest{
+		UserID: userID,
+		Kind:   models.DataRequestExport,
+		Status: models.DataRequestPending,
+		DueAt:  time.Now(),
+	}
+
+	err = s.repo.Create(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+func (s *DataRequestService) ListExports(ctx context.Context, userID uint) ([]models.DataRequest, error) {
+	return s.repo.GetAllByUser(ctx, userID, models.DataRequestExport)
+}
+
+func (s *DataRequestService) GetExport(ctx context.Context, userID, id uint) (*models.DataRequest, error) {
+	req, err := s.repo.GetForUser(ctx, userID, id, models.DataRequestExport)
+	if err != nil {
+		return nil, notFound(err, ErrExportNotFound)
+	}
+
+	return req, nil
+}
+
+// ExportFile returns a finished export whose archive can still be downloaded
+func (s *DataRequestService) ExportFile(ctx context.Context, userID, id uint) (*models.DataRequest, error) {
+	req, err := s.repo.GetForUser(ctx, userID, id, models.DataRequestExport)
+	if err != nil {
+		return nil, notFound(err, ErrExportNotFound)
+	}
+
+	if req.Status != models.DataRequestCompleted || req.FilePath == "" {
+		return nil, ErrExportNotReady
+	}
+	if req.ExpiresAt != nil && time.Now().After(*req.ExpiresAt) {
+		return nil, ErrExportNotReady
+	}
+
+	return req, nil
+}
+
+// RequestErasure schedules the account for permanent deletion once the grace
+// period is over. Until then the user can cancel it.
+func (s *DataRequestService) RequestErasure(ctx context.Context, userID uint, pw string) (*models.DataRequest, error) {
+	user, err := s.userData.GetUser(ctx, userID)
+	if err != nil {
+		return nil, notFound(err, ErrUserNotFound)
+	}
+
+	if user.PasswordHash != "" {
+		ok, err := s.passwords.Verify(user.PasswordHash, pw)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, ErrWrongPassword
+		}
+	}
+
+	_, err = s.repo.FindOpen(ctx, userID, models.DataRequestErasure)
+	if err == nil {
+		return nil, ErrDataRequestInProgress
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	req := &models.DataRequest{
+		UserID: userID,
+		Kind:   models.DataRequestErasure,
+		Status: models.DataRequestPending,
+		DueAt:  time.Now().Add(s.cfg.ErasureGracePeriod),
+	}
+
+	err = s.repo.Create(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	s.sendMail(user.ID, mailer.Message{
+		To:      user.Email,
+		Subject: "Your Film Manager account will be deleted",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nYou asked us to delete your Film Manager account and all of its data.\n"+
+				"This will happen permanently on %s.\n\n"+
+				"Changed your mind? Cancel the deletion before then from your account settings:\n\n%s\n",
+			user.FirstName, req.DueAt.UTC().Format("2 January 2006 15:04 MST"), s.link("/account"),
+		),
+	})
+
+	return req, nil
+}
+
+// GetErasure returns the user's open erasure request
+func (s *DataRequestService) GetErasure(ctx context.Context, userID uint) (*models.DataRequest, error) {
+	req, err := s.repo.FindOpen(ctx, userID, models.DataRequestErasure)
+	if err != nil {
+		return nil, notFound(err, ErrNoErasurePending)
+	}
+
+	return req, nil
+}
+
+func (s *DataRequestService) CancelErasure(ctx context.Context, userID uint) error {
+	ok, err := s.repo.CancelPending(ctx, userID, models.DataRequestErasure)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrNoErasurePending
+	}
+
+	return nil
+}
+
+// ErasureLog lists every erasure request, the record that deletions were carried out
+func (s *DataRequestService) ErasureLog(ctx context.Context) ([]models.DataRequest, error) {
+	return s.repo.GetAllByKind(ctx, models.DataRequestErasure)
+}
+
+// Run processes due requests and removes expired archives every poll
+// interval until ctx is cancelled
+func (s *DataRequestService) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		s.RunDue(ctx)
+		s.RemoveExpiredExports(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunDue claims and runs every request that is due
+func (s *DataRequestService) RunDue(ctx context.Context) {
+	now := time.Now()
+
+	reqs, err := s.repo.GetDue(ctx, now, now.Add(-staleDataRequestAfter), 20)
+	if err != nil {
+		s.logger.Println("error loading data requests:", err)
+		return
+	}
+
+	for i := range reqs {
+		req := &reqs[i]
+
+		claimed, err := s.repo.Claim(ctx, req)
+		if err != nil {
+			s.logger.Printf("error claiming data request %d: %v", req.ID, err)
+			continue
+		}
+		if !claimed {
+			continue
+		}
+
+		switch req.Kind {
+		case models.DataRequestExport:
+			err = s.runExport(ctx, req)
+		case models.DataRequestErasure:
+			err = s.runErasure(ctx, req)
+		default:
+			err = fmt.Errorf("unknown data request kind %q", req.Kind)
+		}
+
+		if err != nil {
+			s.logger.Printf("%s request %d for user %d failed: %v", req.Kind, req.ID, req.UserID, err)
+
+			msg := helpers.Truncate(err.Error(), 255)
+
+			updateErr := s.repo.Update(ctx, req, map[string]any{
+				"status": models.DataRequestFailed,
+				"error":  msg,
+			})
+			if updateErr != nil {
+				s.logger.Printf("error marking data request %d failed: %v", req.ID, updateErr)
+			}
+		}
+	}
+}
+
+// RemoveExpiredExports deletes archives past their download window
+func (s *DataRequestService) RemoveExpiredExports(ctx context.Context) {
+	reqs, err := s.repo.GetExpiredExports(ctx, time.Now())
+	if err != nil {
+		s.logger.Println("error loading expired exports:", err)
+		return
+	}
+
+	for i := range reqs {
+		req := &reqs[i]
+
+		err = os.Remove(req.FilePath)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			s.logger.Printf("error removing export %d: %v", req.ID, err)
+			continue
+		}
+
+		err = s.repo.Update(ctx, req, map[string]any{
+			"status":    models.DataRequestExpired,
+			"file_path": "",
+		})
+		if err != nil {
+			s.logger.Printf("error expiring export %d: %v", req.ID, err)
+		}
+	}
+}
+
+func (s *DataRequestService) runExport(ctx context.Context, req *models.DataRequest) error {
+	data, err := s.userData.Collect(ctx, req.UserID)
+	if err != nil {
+		return err
+	}
+
+	collection, err := s.collection.Export(ctx, req.UserID)
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(s.cfg.ExportDir, 0o700)
+	if err != nil {
+		return err
+	}
+
+	name, err := randomString()
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(s.cfg.ExportDir, fmt.Sprintf("%d-%d-%s.zip", req.UserID, req.ID, name))
+
+	err = writeExportArchive(path, data, collection)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	expires := now.Add(s.cfg.ExportTTL)
+
+	err = s.repo.Update(ctx, req, map[string]any{
+		"status":       models.DataRequestCompleted,
+		"completed_at": now,
+		"expires_at":   expires,
+		"file_path":    path,
+		"error":        "",
+	})
+	if err != nil {
+		os.Remove(path)
+		return err
+	}
+
+	s.sendMail(req.UserID, mailer.Message{
+		To:      data.User.Email,
+		Subject: "Your Film Manager data export is ready",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nThe copy of your Film Manager data you asked for is ready.\n"+
+				"Download it from your account settings before %s:\n\n%s\n",
+			data.User.FirstName, expires.UTC().Format("2 January 2006 15:04 MST"), s.link("/account"),
+		),
+	})
+
+	return nil
+}
+
+func (s *DataRequestService) runErasure(ctx context.Context, req *models.DataRequest) error {
+	// The account may already be soft-deleted or gone, erase whatever is left
+	var email, firstName string
+
+	user, err := s.userData.GetUser(ctx, req.UserID)
+	if err == nil {
+		email, firstName = user.Email, user.FirstName
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+
+	exports, err := s.repo.GetAllByUser(ctx, req.UserID, models.DataRequestExport)
+	if err != nil {
+		return err
+	}
+
+	deleted, err := s.userData.Erase(ctx, req.UserID)
+	if err != nil {
+		return err
+	}
+
+	for _, export := range exports {
+		if export.FilePath == "" {
+			continue
+		}
+
+		err = os.Remove(export.FilePath)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+
+	if email != "" {
+		// Password counters are keyed by email, which may be used again
+		err = s.loginGuard.Unlock(ctx, req.UserID, email)
+		if err != nil {
+			return err
+		}
+	}
+
+	summary, err := json.Marshal(deleted)
+	if err != nil {
+		return err
+	}
+
+	err = s.repo.Update(ctx, req, map[string]any{
+		"status":       models.DataRequestCompleted,
+		"completed_at": time.Now(),
+		"summary":      string(summary),
+		"error":        "",
+	})
+	if err != nil {
+		return err
+	}
+
+	s.logger.Printf("erased user %d: %s", req.UserID, summary)
+
+	if email != "" {
+		s.sendMail(req.UserID, mailer.Message{
+			To:      email,
+			Subject: "Your Film Manager account has been deleted",
+			Body: fmt.Sprintf(
+				"Hi %s,\n\nYour Film Manager account and all of its data have now been permanently deleted.\n",
+				firstName,
+			),
+		})
+	}
+
+	return nil
+}
+
+func (s *DataRequestService) link(path string) string {
+	return strings.TrimRight(s.appURL, "/") + path
+}
+
+func (s *DataRequestService) sendMail(userID uint, msg mailer.Message) {
+	go func() {
+		sendCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		if err := s.mailer.Send(sendCtx, msg); err != nil {
+			s.logger.Printf("data request mail to user %d failed: %v", userID, err)
+		}
+	}()
+}
+
+// writeExportArchive writes the zip to a temporary file first, so a crash
+// never leaves a half written archive behind under the final name
+func writeExportArchive(path string, data *repositories.UserData, collection *dtos.Collection) error {
+	tmp := path + ".tmp"
+
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp)
+
+	zw := zip.NewWriter(f)
+
+	err = writeExportFiles(zw, data, collection)
+	if err != nil {
+		f.Close()
+		return err
+	}
+
+	err = zw.Close()
+	if err != nil {
+		f.Close()
+		return err
+	}
+
+	err = f.Close()
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, path)
+}
+
+func writeExportFiles(zw *zip.Writer, data *repositories.UserData, collection *dtos.Collection) error {
+	sessions := make([]dtos.ExportSession, 0, len(data.Sessions))
+	for _, t := range data.Sessions {
+		sessions = append(sessions, dtos.ExportSession{
+			UserAgent:  t.UserAgent,
+			IPAddress:  t.IPAddress,
+			StartedAt:  t.SessionStartedAt,
+			LastUsedAt: t.LastUsedAt,
+			ExpiresAt:  t.ExpiresAt,
+			Revoked:    t.Revoked,
+			DeletedAt:  deletedAt(t.DeletedAt),
+		})
+	}
+
+	tokens := make([]dtos.ExportAccessToken, 0, len(data.AccessTokens))
+	for _, t := range data.AccessTokens {
+		tokens = append(tokens, dtos.ExportAccessToken{
+			Name:       t.Name,
+			Prefix:     t.Prefix,
+			Scopes:     t.ScopeList(),
+			CreatedAt:  t.CreatedAt,
+			ExpiresAt:  t.ExpiresAt,
+			LastUsedAt: t.LastUsedAt,
+			DeletedAt:  deletedAt(t.DeletedAt),
+		})
+	}
+
+	identities := make([]dtos.ExportIdentity, 0, len(data.Identities))
+	for _, i := range data.Identities {
+		identities = append(identities, dtos.ExportIdentity{
+			Provider:  i.Provider,
+			Subject:   i.Subject,
+			Email:     i.Email,
+			LinkedAt:  i.CreatedAt,
+			DeletedAt: deletedAt(i.DeletedAt),
+		})
+	}
+
+	images := []dtos.ExportImage{}
+	for _, c := range data.Cameras {
+		if c.ImageURL != nil && *c.ImageURL != "" {
+			images = append(images, dtos.ExportImage{Item: "camera", ItemID: c.ID, URL: *c.ImageURL})
+		}
+	}
+	for _, l := range data.Lenses {
+		if l.ImageURL != nil && *l.ImageURL != "" {
+			images = append(images, dtos.ExportImage{Item: "lens", ItemID: l.ID, URL: *l.ImageURL})
+		}
+	}
+
+	files := []struct {
+		name  string
+		value any
+	}{
+		{"profile.json", data.User},
+		{"cameras.json", data.Cameras},
+		{"lenses.json", data.Lenses},
+		{"film_stocks.json", data.FilmStocks},
+		{"rolls.json", data.Rolls},
+		{"kits.json", data.Kits},
+		{"shoots.json", data.Shoots},
+		{"frames.json", data.Frames},
+		{"prints.json", data.Prints},
+		{"archive.json", data.Archive},
+		{"images.json", images},
+		{"sessions.json", sessions},
+		{"access_tokens.json", tokens},
+		{"identities.json", identities},
+		{"data_requests.json", data.DataRequests},
+		{"audit_log.json", data.AuditEvents},
+		{"collection.json", collection},
+	}
+
+	f, err := zw.Create("README.txt")
+	if err != nil {
+		return err
+	}
+	_, err = f.Write([]byte(exportReadme))
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		f, err := zw.Create(file.name)
+		if err != nil {
+			return err
+		}
+
+		enc := json.NewEncoder(f)
+		enc.SetIndent("", "  ")
+
+		err = enc.Encode(file.value)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func deletedAt(d gorm.DeletedAt) *time.Time {
+	if !d.Valid {
+		return nil
+	}
+	return &d.Time
+}
@@ -0,0 +1,181 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"log"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/georgiev098/film-manager/backend/internal/apperrors"
+	"github.com/georgiev098/film-manager/backend/internal/mailer"
+	"github.com/georgiev098/film-manager/backend/internal/models"
+	"github.com/georgiev098/film-manager/backend/internal/repositories"
+)
+
+var (
+	ErrInvalidVerificationToken = apperrors.Validation("invalid or expired verification link")
+	ErrAlreadyVerified          = apperrors.Conflict("email already verified")
+)
+
+type EmailVerificationService struct {
+	userRepo *repositories.UserRepository
+	mailer   mailer.Mailer
+	logger   *log.Logger
+	appURL   string
+	secret   []byte
+	ttl      time.Duration
+}
+
+func NewEmailVerificationService(userRepo *repositories.UserRepository, mail mailer.Mailer, logger *log.Logger, appURL, secret string, ttl time.Duration) *EmailVerificationService {
+	return &EmailVerificationService{
+		userRepo: userRepo,
+		mailer:   mail,
+		logger:   logger,
+		appURL:   appURL,
+		secret:   []byte(secret),
+		ttl:      ttl,
+	}
+}
+
+// SendVerification emails a signed verification link to the user's current address
+func (s *EmailVerificationService) SendVerification(ctx context.Context, user *models.User) error {
+	if user.EmailVerifiedAt != nil {
+		return ErrAlreadyVerified
+	}
+
+	token := s.sign(user.ID, user.Email, time.Now().Add(s.ttl))
+	link := fmt.Sprintf("%s/verify-email?token=%s", strings.TrimRight(s.appURL, "/"), url.QueryEscape(token))
+
+	msg := mailer.Message{
+		To:      user.Email,
+		Subject: "Confirm your Film Manager email address",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nPlease confirm your email address by opening the link below:\n\n%s\n\n"+
+				"The link is valid for %d hours.\n",
+			user.FirstName, link, int(s.ttl.Hours()),
+		),
+	}
+
+	go func() {
+		sendCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		if err := s.mailer.Send(sendCtx, msg); err != nil {
+			s.logger.Printf("verification mail to user %d failed: %v", user.ID, err)
+		}
+	}()
+
+	return nil
+}
+
+// Resend sends a fresh link to a logged-in user
+func (s *EmailVerificationService) Resend(ctx context.Context, userID uint) error {
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	return s.SendVerification(ctx, user)
+}
+
+// ResendByEmail sends a fresh link if the address belongs to an unverified account.
+// Like the password reset request it never reveals whether the account exists.
+func (s *EmailVerificationService) ResendByEmail(ctx context.Context, email string) error {
+	email = strings.ToLower(strings.TrimSpace(email))
+
+	user, err := s.userRepo.GetUserByEmail(ctx, email)
+	if err != nil || user.Disabled || user.EmailVerifiedAt != nil {
+		return nil
+	}
+
+	return s.SendVerification(ctx, user)
+}
+
+// Verify checks a link token and marks the address as verified
+func (s *EmailVerificationService) Verify(ctx context.Context, token string) (*models.User, error) {
+	userID, email, err := s.parse(token)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, ErrInvalidVerificationToken
+	}
+
+	// A link sent to an address the user has since changed must not verify the new one
+	if user.Email != email {
+		return nil, ErrInvalidVerificationToken
+	}
+
+	if user.EmailVerifiedAt != nil {
+		return user, nil
+	}
+
+	err = s.userRepo.UpdateUser(ctx, user, map[string]any{"email_verified_at": time.Now()})
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// Tokens are base64url("<userID>|<email>|<unix expiry>") + "." + base64url(HMAC-SHA256)
+func (s *EmailVerificationService) sign(userID uint, email string, expires time.Time) string {
+	payload := fmt.Sprintf("%d|%s|%d", userID, email, expires.Unix())
+
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(payload))
+
+	return base64.RawURLEncoding.EncodeToString([]byte(payload)) + "." +
+		base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func (s *EmailVerificationService) parse(token string) (uint, string, error) {
+	encPayload, encSig, ok := strings.Cut(token, ".")
+	if !ok {
+		return 0, "", ErrInvalidVerificationToken
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encPayload)
+	if err != nil {
+		return 0, "", ErrInvalidVerificationToken
+	}
+
+	sig, err := base64.RawURLEncoding.DecodeString(encSig)
+	if err != nil {
+		return 0, "", ErrInvalidVerificationToken
+	}
+
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write(payload)
+	if !hmac.Equal(sig, mac.Sum(nil)) {
+		return 0, "", ErrInvalidVerificationToken
+	}
+
+	// The email sits in the middle and may itself contain "|"
+	idPart, rest, ok := strings.Cut(string(payload), "|")
+	sep := strings.LastIndex(rest, "|")
+	if !ok || sep < 0 {
+		return 0, "", ErrInvalidVerificationToken
+	}
+	email, expiryPart := rest[:sep], rest[sep+1:]
+
+	userID, err := strconv.ParseUint(idPart, 10, 64)
+	if err != nil {
+		return 0, "", ErrInvalidVerificationToken
+	}
+
+	expires, err := strconv.ParseInt(expiryPart, 10, 64)
+	if err != nil || time.Now().Unix() > expires {
+		return 0, "", ErrInvalidVerificationToken
+	}
+
+	return uint(userID), email, nil
+}
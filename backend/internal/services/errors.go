@@ -0,0 +1,16 @@
+package services
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// notFound swaps gorm's missing-record error for the resource's own domain
+// error, so handlers don't need to know about the database
+func notFound(err, domainErr error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return domainErr
+	}
+	return err
+}
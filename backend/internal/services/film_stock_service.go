@@ -0,0 +1,92 @@
+package services
+
+import (
+	"context"
+
+	"github.com/georgiev098/film-manager/backend/internal/apperrors"
+	"github.com/georgiev098/film-manager/backend/internal/dtos"
+	"github.com/georgiev098/film-manager/backend/internal/models"
+	"github.com/georgiev098/film-manager/backend/internal/repositories"
+)
+
+// ErrFilmStockNotFound is also returned for another user's film stock
+var ErrFilmStockNotFound = apperrors.NotFound("film stock not found")
+
+type FilmStockService struct {
+	repo *repositories.FilmStockRepo
+}
+
+func NewFilmStockService(repo *repositories.FilmStockRepo) *FilmStockService {
+	return &FilmStockService{
+		repo: repo,
+	}
+}
+
+func (s *FilmStockService) CreateFilmStock(ctx context.Context, stock *models.FilmStock) error {
+	return s.repo.CreateFilmStock(ctx, stock)
+}
+
+func (s *FilmStockService) GetAllForUser(ctx context.Context, userID uint) ([]models.FilmStock, error) {
+	return s.repo.GetAllByUserID(ctx, userID)
+}
+
+func (s *FilmStockService) GetFilmStockByID(ctx context.Context, stockID uint, userID uint) (*models.FilmStock, error) {
+	stock, err := s.repo.GetFilmStockByID(ctx, stockID)
+	if err != nil {
+		return nil, notFound(err, ErrFilmStockNotFound)
+	}
+
+	if stock.UserID != userID {
+		return nil, ErrFilmStockNotFound
+	}
+
+	return stock, nil
+}
+
+func (s *FilmStockService) UpdateFilmStock(ctx context.Context, stockID uint, userID uint, input dtos.FilmStockUpdate) (*models.FilmStock, error) {
+	stock, err := s.GetFilmStockByID(ctx, stockID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	updates := map[string]any{}
+
+	if input.Manufacturer != nil {
+		updates["manufacturer"] = input.Manufacturer
+	}
+	if input.Name != nil {
+		updates["name"] = input.Name
+	}
+	if input.ISO != nil {
+		updates["iso"] = input.ISO
+	}
+	if input.Format != nil {
+		updates["format"] = input.Format
+	}
+	if input.Process != nil {
+		updates["process"] = input.Process
+	}
+	if input.Notes != nil {
+		updates["notes"] = input.Notes
+	}
+
+	if len(updates) == 0 {
+		return stock, nil
+	}
+
+	err = s.repo.UpdateFilmStock(ctx, stock, updates)
+	if err != nil {
+		return nil, err
+	}
+
+	return stock, nil
+}
+
+func (s *FilmStockService) DeleteFilmStock(ctx context.Context, stockID uint, userID uint) error {
+	stock, err := s.GetFilmStockByID(ctx, stockID, userID)
+	if err != nil {
+		return err
+	}
+
+	return s.repo.DeleteFilmStock(ctx, stock)
+}
@@ -0,0 +1,157 @@
+package services
+
+import (
+	"context"
+
+	"github.com/georgiev098/film-manager/backend/internal/apperrors"
+	"github.com/georgiev098/film-manager/backend/internal/dtos"
+	"github.com/georgiev098/film-manager/backend/internal/models"
+	"github.com/georgiev098/film-manager/backend/internal/repositories"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrFrameNotFound is also returned for another user's frame
+	ErrFrameNotFound    = apperrors.NotFound("frame not found")
+	ErrFrameNumberTaken = apperrors.Conflict("the roll already has a frame with this number")
+	ErrStripNotOnPage   = apperrors.Validation("a frame is filed on a strip of the page its roll is filed on")
+)
+
+type FrameService struct {
+	db *gorm.DB
+}
+
+func NewFrameService(db *gorm.DB) *FrameService {
+	return &FrameService{
+		db: db,
+	}
+}
+
+func (s *FrameService) CreateFrame(ctx context.Context, frame *models.Frame) error {
+	roll, err := getRoll(ctx, repositories.NewRollRepo(s.db), frame.RollID, frame.UserID)
+	if err != nil {
+		return err
+	}
+
+	repo := repositories.NewFrameRepo(s.db)
+
+	taken, err := repo.NumberTaken(ctx, roll.ID, frame.Number, 0)
+	if err != nil {
+		return err
+	}
+	if taken {
+		return ErrFrameNumberTaken
+	}
+
+	if frame.StripID != nil {
+		err = s.checkStrip(ctx, roll, *frame.StripID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return repo.CreateFrame(ctx, frame)
+}
+
+func (s *FrameService) GetAllForRoll(ctx context.Context, rollID uint, userID uint) ([]models.Frame, error) {
+	roll, err := getRoll(ctx, repositories.NewRollRepo(s.db), rollID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return repositories.NewFrameRepo(s.db).GetAllByRollID(ctx, roll.ID)
+}
+
+func (s *FrameService) GetFrameByID(ctx context.Context, frameID uint, userID uint) (*models.Frame, error) {
+	frame, err := repositories.NewFrameRepo(s.db).GetFrameByID(ctx, frameID)
+	if err != nil {
+		return nil, notFound(err, ErrFrameNotFound)
+	}
+
+	if frame.UserID != userID {
+		return nil, ErrFrameNotFound
+	}
+
+	return frame, nil
+}
+
+func (s *FrameService) UpdateFrame(ctx context.Context, frameID uint, userID uint, input dtos.FrameUpdate) (*models.Frame, error) {
+	frame, err := s.GetFrameByID(ctx, frameID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	repo := repositories.NewFrameRepo(s.db)
+	updates := map[string]any{}
+
+	if input.Number != nil && *input.Number != frame.Number {
+		taken, err := repo.NumberTaken(ctx, frame.RollID, *input.Number, frame.ID)
+		if err != nil {
+			return nil, err
+		}
+		if taken {
+			return nil, ErrFrameNumberTaken
+		}
+		updates["number"] = input.Number
+	}
+	if input.StripID != nil {
+		if *input.StripID == 0 {
+			updates["strip_id"] = nil
+		} else {
+			roll, err := getRoll(ctx, repositories.NewRollRepo(s.db), frame.RollID, userID)
+			if err != nil {
+				return nil, err
+			}
+
+			err = s.checkStrip(ctx, roll, *input.StripID)
+			if err != nil {
+				return nil, err
+			}
+			updates["strip_id"] = input.StripID
+		}
+	}
+	if input.Title != nil {
+		updates["title"] = input.Title
+	}
+	if input.Notes != nil {
+		updates["notes"] = input.Notes
+	}
+
+	if len(updates) == 0 {
+		return frame, nil
+	}
+
+	err = repo.UpdateFrame(ctx, frame, updates)
+	if err != nil {
+		return nil, err
+	}
+
+	return frame, nil
+}
+
+func (s *FrameService) DeleteFrame(ctx context.Context, frameID uint, userID uint) error {
+	frame, err := s.GetFrameByID(ctx, frameID, userID)
+	if err != nil {
+		return err
+	}
+
+	return repositories.NewFrameRepo(s.db).DeleteFrame(ctx, frame)
+}
+
+// checkStrip makes sure stripID is a strip on the page the roll is filed on
+func (s *FrameService) checkStrip(ctx context.Context, roll *models.Roll, stripID uint) error {
+	if roll.ArchiveLocationID == nil {
+		return ErrStripNotOnPage
+	}
+
+	strip, err := NewArchiveService(s.db).GetLocationByID(ctx, stripID, roll.UserID)
+	if err != nil {
+		return err
+	}
+
+	if strip.Kind != models.ArchiveStrip || strip.ParentID == nil || *strip.ParentID != *roll.ArchiveLocationID {
+		return ErrStripNotOnPage
+	}
+
+	return nil
+}
@@ -0,0 +1,81 @@
+package services
+
+import (
+	"context"
+
+	"github.com/georgiev098/film-manager/backend/internal/apperrors"
+	"github.com/georgiev098/film-manager/backend/internal/core"
+	"github.com/georgiev098/film-manager/backend/internal/repositories"
+	"gorm.io/gorm"
+)
+
+var ErrItemLimitReached = apperrors.Forbidden("item limit reached, verify your email address to add more")
+
+// ItemQuota enforces core.UnverifiedPolicyLimited: a user who hasn't verified
+// their email may own at most limit cameras and lenses together. It is checked
+// where items are written, so a bulk import counts every item it creates.
+type ItemQuota struct {
+	db     *gorm.DB
+	policy string
+	limit  int
+}
+
+func NewItemQuota(db *gorm.DB, policy string, limit int) *ItemQuota {
+	return &ItemQuota{
+		db:     db,
+		policy: policy,
+		limit:  limit,
+	}
+}
+
+// Create runs create in a transaction once the user has room for adding more
+// items. Without the limited policy create just runs.
+func (q *ItemQuota) Create(ctx context.Context, userID uint, adding int, create func(tx *gorm.DB) error) error {
+	if q.policy != core.UnverifiedPolicyLimited {
+		return create(q.db)
+	}
+
+	return q.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := q.Check(ctx, tx, userID, adding)
+		if err != nil {
+			return err
+		}
+
+		return create(tx)
+	})
+}
+
+// Check fails with ErrItemLimitReached when the user's items plus adding would
+// exceed the limit. It must run inside tx: the user's row stays locked until
+// tx ends, so concurrent requests are counted one after another. Items
+// already written in tx are counted, so pass 0 after creating them.
+func (q *ItemQuota) Check(ctx context.Context, tx *gorm.DB, userID uint, adding int) error {
+	if q.policy != core.UnverifiedPolicyLimited {
+		return nil
+	}
+
+	user, err := repositories.NewUserRepo(tx).LockUserByID(ctx, userID)
+	if err != nil {
+		return notFound(err, ErrUserNotFound)
+	}
+
+	if user.EmailVerifiedAt != nil {
+		return nil
+	}
+
+	cameras, err := repositories.NewCameraRepo(tx).CountByUserIDLocked(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	lenses, err := repositories.NewLensRepo(tx).CountByUserIDLocked(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if cameras+lenses+int64(adding) > int64(q.limit) {
+		return ErrItemLimitReached
+	}
+
+	return nil
+}
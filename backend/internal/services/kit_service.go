@@ -0,0 +1,190 @@
+package services
+
+import (
+	"context"
+
+	"github.com/georgiev098/film-manager/backend/internal/apperrors"
+	"github.com/georgiev098/film-manager/backend/internal/dtos"
+	"github.com/georgiev098/film-manager/backend/internal/models"
+	"github.com/georgiev098/film-manager/backend/internal/repositories"
+	"gorm.io/gorm"
+)
+
+// ErrKitNotFound is also returned for another user's kit
+var ErrKitNotFound = apperrors.NotFound("kit not found")
+
+type KitService struct {
+	db *gorm.DB
+}
+
+func NewKitService(db *gorm.DB) *KitService {
+	return &KitService{
+		db: db,
+	}
+}
+
+func (s *KitService) CreateKit(ctx context.Context, userID uint, input dtos.KitRequest) (*models.Kit, error) {
+	cameras, err := s.cameras(ctx, userID, input.CameraIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	lenses, err := s.lenses(ctx, userID, input.LensIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	kit := &models.Kit{
+		Name:    input.Name,
+		Notes:   input.Notes,
+		Cameras: cameras,
+		Lenses:  lenses,
+		UserID:  userID,
+	}
+
+	err = repositories.NewKitRepo(s.db).CreateKit(ctx, kit)
+	if err != nil {
+		return nil, err
+	}
+
+	return kit, nil
+}
+
+func (s *KitService) GetAllForUser(ctx context.Context, userID uint) ([]models.Kit, error) {
+	return repositories.NewKitRepo(s.db).GetAllByUserID(ctx, userID)
+}
+
+func (s *KitService) GetKitByID(ctx context.Context, kitID uint, userID uint) (*models.Kit, error) {
+	kit, err := repositories.NewKitRepo(s.db).GetKitByID(ctx, kitID)
+	if err != nil {
+		return nil, notFound(err, ErrKitNotFound)
+	}
+
+	if kit.UserID != userID {
+		return nil, ErrKitNotFound
+	}
+
+	return kit, nil
+}
+
+func (s *KitService) UpdateKit(ctx context.Context, kitID uint, userID uint, input dtos.KitUpdate) (*models.Kit, error) {
+	kit, err := s.GetKitByID(ctx, kitID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var cameras []models.Camera
+	if input.CameraIDs != nil {
+		cameras, err = s.cameras(ctx, userID, *input.CameraIDs)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var lenses []models.Lens
+	if input.LensIDs != nil {
+		lenses, err = s.lenses(ctx, userID, *input.LensIDs)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	updates := map[string]any{}
+
+	if input.Name != nil {
+		updates["name"] = input.Name
+	}
+	if input.Notes != nil {
+		updates["notes"] = input.Notes
+	}
+
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		repo := repositories.NewKitRepo(tx)
+
+		if len(updates) > 0 {
+			err := repo.UpdateKit(ctx, kit, updates)
+			if err != nil {
+				return err
+			}
+		}
+
+		if input.CameraIDs != nil {
+			err := repo.ReplaceCameras(ctx, kit, cameras)
+			if err != nil {
+				return err
+			}
+		}
+
+		if input.LensIDs != nil {
+			return repo.ReplaceLenses(ctx, kit, lenses)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return kit, nil
+}
+
+// DeleteKit deletes the kit; shoots planned with it keep going without one
+func (s *KitService) DeleteKit(ctx context.Context, kitID uint, userID uint) error {
+	kit, err := s.GetKitByID(ctx, kitID, userID)
+	if err != nil {
+		return err
+	}
+
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := repositories.NewShootRepo(tx).DetachKit(ctx, kit.ID)
+		if err != nil {
+			return err
+		}
+
+		return repositories.NewKitRepo(tx).DeleteKit(ctx, kit)
+	})
+}
+
+// cameras loads the user's cameras with these IDs. Someone else's camera
+// reads as missing, like it does everywhere else.
+func (s *KitService) cameras(ctx context.Context, userID uint, cameraIDs []uint) ([]models.Camera, error) {
+	if len(cameraIDs) == 0 {
+		return []models.Camera{}, nil
+	}
+
+	cameras, err := repositories.NewCameraRepo(s.db).GetByIDsForUser(ctx, userID, cameraIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(cameras) != len(uniqueIDs(cameraIDs)) {
+		return nil, ErrCameraNotFound
+	}
+
+	return cameras, nil
+}
+
+func (s *KitService) lenses(ctx context.Context, userID uint, lensIDs []uint) ([]models.Lens, error) {
+	if len(lensIDs) == 0 {
+		return []models.Lens{}, nil
+	}
+
+	lenses, err := repositories.NewLensRepo(s.db).GetByIDsForUser(ctx, userID, lensIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(lenses) != len(uniqueIDs(lensIDs)) {
+		return nil, ErrLensNotFound
+	}
+
+	return lenses, nil
+}
+
+func uniqueIDs(ids []uint) map[uint]struct{} {
+	set := make(map[uint]struct{}, len(ids))
+	for _, id := range ids {
+		set[id] = struct{}{}
+	}
+	return set
+}
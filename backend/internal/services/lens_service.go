@@ -2,21 +2,29 @@ package services
 
 import (
 	"context"
-	"errors"
 
+	"github.com/georgiev098/film-manager/backend/internal/apperrors"
+	"github.com/georgiev098/film-manager/backend/internal/audit"
 	"github.com/georgiev098/film-manager/backend/internal/dtos"
 	"github.com/georgiev098/film-manager/backend/internal/models"
 	"github.com/georgiev098/film-manager/backend/internal/repositories"
 	"gorm.io/gorm"
 )
 
+// ErrLensNotFound is also returned for another user's lens, so IDs cannot be probed
+var ErrLensNotFound = apperrors.NotFound("lens not found")
+
 type LensService struct {
-	repo *repositories.LensRepo
+	repo  *repositories.LensRepo
+	quota *ItemQuota
+	audit *audit.Service
 }
 
-func NewLensService(repo *repositories.LensRepo) *LensService {
+func NewLensService(repo *repositories.LensRepo, quota *ItemQuota, audit *audit.Service) *LensService {
 	return &LensService{
-		repo: repo,
+		repo:  repo,
+		quota: quota,
+		audit: audit,
 	}
 }
 
@@ -31,61 +39,90 @@ func (s *LensService) GetAllForUser(ctx context.Context, userID uint) ([]models.
 func (s *LensService) GetLensByID(ctx context.Context, lensID uint, userID uint) (*models.Lens, error) {
 	lens, err := s.repo.GetLensByID(ctx, lensID)
 	if err != nil {
-		return nil, err
+		return nil, notFound(err, ErrLensNotFound)
 	}
 
 	if lens.UserID != userID {
-		return nil, gorm.ErrRecordNotFound
+		return nil, ErrLensNotFound
 	}
 
 	return lens, nil
 }
 
 func (s *LensService) CreateLens(ctx context.Context, lens *models.Lens) error {
-	// business logic, if any
-	return s.repo.CreateLens(ctx, lens)
+	err := s.quota.Create(ctx, lens.UserID, 1, func(tx *gorm.DB) error {
+		return repositories.NewLensRepo(tx).CreateLens(ctx, lens)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.audit.Record(ctx, audit.Entry{
+		ActorID:  lens.UserID,
+		UserID:   lens.UserID,
+		Action:   models.AuditLensCreate,
+		Target:   "lens",
+		TargetID: lens.ID,
+		Details:  map[string]any{"after": lens},
+	})
+
+	return nil
 }
 
 func (s *LensService) DeleteLens(ctx context.Context, lensID uint, userID uint) error {
 	lens, err := s.repo.GetLensByID(ctx, lensID)
 
 	if err != nil {
-		return err
+		return notFound(err, ErrLensNotFound)
 	}
 
 	if lens.UserID != userID {
-		return errors.New("forbidden")
+		return ErrLensNotFound
 	}
 
-	return s.repo.DeleteLens(ctx, lens)
+	err = s.repo.DeleteLens(ctx, lens)
+	if err != nil {
+		return err
+	}
+
+	s.audit.Record(ctx, audit.Entry{
+		ActorID:  userID,
+		UserID:   lens.UserID,
+		Action:   models.AuditLensDelete,
+		Target:   "lens",
+		TargetID: lens.ID,
+		Details:  map[string]any{"before": lens},
+	})
+
+	return nil
 }
 
 func (s *LensService) UpdateLens(ctx context.Context, lensID uint, userID uint, input dtos.LensUpdate) (*models.Lens, error) {
 	lens, err := s.repo.GetLensByID(ctx, lensID)
 	if err != nil {
-		return nil, err
+		return nil, notFound(err, ErrLensNotFound)
 	}
 
 	if lens.UserID != userID {
-		return nil, errors.New("forbidden")
+		return nil, ErrLensNotFound
 	}
 
 	updates := map[string]any{}
 
 	if input.FocalLengthMax != nil {
-		updates["max_focal_length"] = input.FocalLengthMax
+		updates["focal_length_max"] = input.FocalLengthMax
 	}
 	if input.FocalLengthMin != nil {
-		updates["min_focal_length"] = input.FocalLengthMin
+		updates["focal_length_min"] = input.FocalLengthMin
 	}
 	if input.ImageURL != nil {
 		updates["image_url"] = input.ImageURL
 	}
 	if input.MaxApertureStr != nil {
-		updates["max_aperture"] = input.MaxApertureStr
+		updates["max_aperture_str"] = input.MaxApertureStr
 	}
 	if input.MinApertureStr != nil {
-		updates["min_aperture"] = input.MinApertureStr
+		updates["min_aperture_str"] = input.MinApertureStr
 	}
 	if input.Mount != nil {
 		updates["mount"] = input.Mount
@@ -107,17 +144,62 @@ func (s *LensService) UpdateLens(ctx context.Context, lensID uint, userID uint,
 		return lens, nil // nothing to update
 	}
 
+	before := lensColumns(lens)
+
 	err = s.repo.UpdateLens(ctx, lens, updates)
 	if err != nil {
 		return nil, err
 	}
 
+	s.audit.Record(ctx, audit.Entry{
+		ActorID:  userID,
+		UserID:   lens.UserID,
+		Action:   models.AuditLensUpdate,
+		Target:   "lens",
+		TargetID: lens.ID,
+		Details:  map[string]any{"changes": audit.Diff(before, updates)},
+	})
+
 	return lens, nil
 }
 
 func (s *LensService) DeleteAllByUser(ctx context.Context, requestingUserID, targetUserID uint) error {
 	if requestingUserID != targetUserID {
-		return errors.New("forbidden")
+		return ErrLensNotFound
+	}
+
+	lenses, err := s.repo.GetAllByUserID(ctx, targetUserID)
+	if err != nil {
+		return err
+	}
+
+	err = s.repo.DeleteAllByUserID(ctx, targetUserID)
+	if err != nil {
+		return err
+	}
+
+	s.audit.Record(ctx, audit.Entry{
+		ActorID: requestingUserID,
+		UserID:  targetUserID,
+		Action:  models.AuditLensDeleteAll,
+		Details: map[string]any{"before": lenses},
+	})
+
+	return nil
+}
+
+// lensColumns maps update columns to the lens's current values, for audit diffs
+func lensColumns(l *models.Lens) map[string]any {
+	return map[string]any{
+		"manufacturer":        l.Manufacturer,
+		"lens_type":           l.LensType,
+		"image_stabilization": l.ImageStabilization,
+		"focal_length_min":    l.FocalLengthMin,
+		"focal_length_max":    l.FocalLengthMax,
+		"min_aperture_str":    l.MinApertureStr,
+		"max_aperture_str":    l.MaxApertureStr,
+		"mount":               l.Mount,
+		"image_url":           l.ImageURL,
+		"notes":               l.Notes,
 	}
-	return s.repo.DeleteAllByUserID(ctx, targetUserID)
 }
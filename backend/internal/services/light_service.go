@@ -0,0 +1,270 @@
+package services
+
+import (
+	"math"
+	"time"
+)
+
+// Sun elevation angles (degrees) that bound the light windows we care about.
+const (
+	sunriseElevation    = -0.833 // upper limb on the horizon, refraction corrected
+	goldenHourHighLimit = 6.0
+	goldenHourLowLimit  = -4.0
+	blueHourLowLimit    = -6.0
+)
+
+type LightService struct{}
+
+func NewLightService() *LightService {
+	return &LightService{}
+}
+
+// TimeWindow is a span of time, nil ends mean the sun never crosses that elevation on the day
+type TimeWindow struct {
+	Start *time.Time `json:"start"`
+	End   *time.Time `json:"end"`
+}
+
+type SunPosition struct {
+	Time      time.Time `json:"time"`
+	Azimuth   float64   `json:"azimuth"`   // degrees clockwise from north
+	Elevation float64   `json:"elevation"` // degrees above the horizon
+}
+
+type LightWindows struct {
+	Date      string  `json:"date"`
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+
+	Sunrise   *time.Time `json:"sunrise"`
+	SolarNoon time.Time  `json:"solar_noon"`
+	Sunset    *time.Time `json:"sunset"`
+
+	MorningBlueHour   TimeWindow `json:"morning_blue_hour"`
+	MorningGoldenHour TimeWindow `json:"morning_golden_hour"`
+	EveningGoldenHour TimeWindow `json:"evening_golden_hour"`
+	EveningBlueHour   TimeWindow `json:"evening_blue_hour"`
+
+	SunriseAzimuth *float64    `json:"sunrise_azimuth"`
+	SunsetAzimuth  *float64    `json:"sunset_azimuth"`
+	NoonPosition   SunPosition `json:"noon_position"`
+
+	Suggestions []FilmSuggestion `json:"suggestions"`
+}
+
+// FilmSuggestion recommends a film speed for one of the light windows
+type FilmSuggestion struct {
+	Window string `json:"window"`
+	MinISO int    `json:"min_iso"`
+	MaxISO int    `json:"max_iso"`
+	Note   string `json:"note"`
+}
+
+// GetLightWindows computes sun times for the given calendar day at a location.
+// All times are returned in loc.
+func (s *LightService) GetLightWindows(lat, lng float64, day time.Time, loc *time.Location) *LightWindows {
+	day = time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, loc)
+
+	noon := solarNoon(lng, day).Round(time.Second)
+
+	res := &LightWindows{
+		Date:      day.Format("2006-01-02"),
+		Latitude:  lat,
+		Longitude: lng,
+		SolarNoon: noon.In(loc),
+	}
+
+	res.Sunrise = elevationCrossing(lat, lng, noon, sunriseElevation, true, loc)
+	res.Sunset = elevationCrossing(lat, lng, noon, sunriseElevation, false, loc)
+
+	morningGoldenStart := elevationCrossing(lat, lng, noon, goldenHourLowLimit, true, loc)
+	morningGoldenEnd := elevationCrossing(lat, lng, noon, goldenHourHighLimit, true, loc)
+	eveningGoldenStart := elevationCrossing(lat, lng, noon, goldenHourHighLimit, false, loc)
+	eveningGoldenEnd := elevationCrossing(lat, lng, noon, goldenHourLowLimit, false, loc)
+	morningBlueStart := elevationCrossing(lat, lng, noon, blueHourLowLimit, true, loc)
+	eveningBlueEnd := elevationCrossing(lat, lng, noon, blueHourLowLimit, false, loc)
+
+	res.MorningBlueHour = TimeWindow{Start: morningBlueStart, End: morningGoldenStart}
+	res.MorningGoldenHour = TimeWindow{Start: morningGoldenStart, End: morningGoldenEnd}
+	res.EveningGoldenHour = TimeWindow{Start: eveningGoldenStart, End: eveningGoldenEnd}
+	res.EveningBlueHour = TimeWindow{Start: eveningGoldenEnd, End: eveningBlueEnd}
+
+	if res.Sunrise != nil {
+		az, _ := sunPosition(lat, lng, *res.Sunrise)
+		res.SunriseAzimuth = &az
+	}
+	if res.Sunset != nil {
+		az, _ := sunPosition(lat, lng, *res.Sunset)
+		res.SunsetAzimuth = &az
+	}
+
+	res.NoonPosition = s.GetSunPosition(lat, lng, res.SolarNoon)
+	res.Suggestions = suggestFilm(res.NoonPosition.Elevation)
+
+	return res
+}
+
+// GetSunPosition returns the sun's azimuth and elevation at an instant
+func (s *LightService) GetSunPosition(lat, lng float64, t time.Time) SunPosition {
+	az, el := sunPosition(lat, lng, t)
+	return SunPosition{Time: t, Azimuth: az, Elevation: el}
+}
+
+// suggestFilm maps each light window to a film speed range. The midday
+// suggestion depends on how high the sun gets.
+func suggestFilm(noonElevation float64) []FilmSuggestion {
+	midday := FilmSuggestion{Window: "midday", MinISO: 50, MaxISO: 200, Note: "hard light, slow film keeps shutter speeds in range"}
+	if noonElevation < 25 {
+		midday = FilmSuggestion{Window: "midday", MinISO: 200, MaxISO: 400, Note: "low winter sun, soft but dimmer light"}
+	}
+
+	return []FilmSuggestion{
+		{Window: "blue_hour", MinISO: 800, MaxISO: 3200, Note: "fast film or a tripod for long exposures"},
+		{Window: "golden_hour", MinISO: 200, MaxISO: 400, Note: "warm low light, colour negative handles the contrast well"},
+		midday,
+	}
+}
+
+// --- Astronomical formulas (NOAA solar calculator) ---
+
+func toRad(deg float64) float64 { return deg * math.Pi / 180 }
+func toDeg(rad float64) float64 { return rad * 180 / math.Pi }
+
+func julianDay(t time.Time) float64 {
+	return float64(t.UTC().UnixNano())/float64(24*time.Hour) + 2440587.5
+}
+
+// solarParams returns the sun's declination (degrees) and the equation of time (minutes)
+func solarParams(t time.Time) (declination, eqTime float64) {
+	jc := (julianDay(t) - 2451545) / 36525
+
+	meanLong := math.Mod(280.46646+jc*(36000.76983+jc*0.0003032), 360)
+	meanAnom := 357.52911 + jc*(35999.05029-0.0001537*jc)
+	ecc := 0.016708634 - jc*(0.000042037+0.0000001267*jc)
+
+	center := math.Sin(toRad(meanAnom))*(1.914602-jc*(0.004817+0.000014*jc)) +
+		math.Sin(toRad(2*meanAnom))*(0.019993-0.000101*jc) +
+		math.Sin(toRad(3*meanAnom))*0.000289
+
+	trueLong := meanLong + center
+	omega := 125.04 - 1934.136*jc
+	appLong := trueLong - 0.00569 - 0.00478*math.Sin(toRad(omega))
+
+	meanObliq := 23 + (26+(21.448-jc*(46.815+jc*(0.00059-jc*0.001813)))/60)/60
+	obliq := meanObliq + 0.00256*math.Cos(toRad(omega))
+
+	declination = toDeg(math.Asin(math.Sin(toRad(obliq)) * math.Sin(toRad(appLong))))
+
+	y := math.Pow(math.Tan(toRad(obliq/2)), 2)
+	eqTime = 4 * toDeg(y*math.Sin(2*toRad(meanLong))-
+		2*ecc*math.Sin(toRad(meanAnom))+
+		4*ecc*y*math.Sin(toRad(meanAnom))*math.Cos(2*toRad(meanLong))-
+		0.5*y*y*math.Sin(4*toRad(meanLong))-
+		1.25*ecc*ecc*math.Sin(2*toRad(meanAnom)))
+
+	return declination, eqTime
+}
+
+// solarNoon finds the moment of solar noon closest to the middle of the given local day
+func solarNoon(lng float64, day time.Time) time.Time {
+	midnight := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, day.Location())
+	_, offset := midnight.Zone()
+
+	noon := midnight.Add(12 * time.Hour)
+	for range 2 {
+		_, eqTime := solarParams(noon)
+
+		// NOAA gives minutes after UTC midnight; local midnight is offset earlier
+		minutes := 720 - 4*lng - eqTime + float64(offset)/60
+
+		// Zones far from their longitude's offset, like Pacific/Apia, can put
+		// it on the day before or after; take the noon nearest to midday
+		minutes -= 1440 * math.Round((minutes-720)/1440)
+
+		noon = midnight.Add(time.Duration(minutes * float64(time.Minute)))
+	}
+
+	return noon
+}
+
+// elevationCrossing returns when the sun passes the given elevation before
+// (rising) or after solar noon, or nil if it never does on that day.
+func elevationCrossing(lat, lng float64, noon time.Time, elevation float64, rising bool, loc *time.Location) *time.Time {
+	t := noon
+	for range 3 {
+		decl, _ := solarParams(t)
+
+		cosHA := (math.Cos(toRad(90-elevation)) - math.Sin(toRad(lat))*math.Sin(toRad(decl))) /
+			(math.Cos(toRad(lat)) * math.Cos(toRad(decl)))
+		if cosHA > 1 || cosHA < -1 {
+			return nil
+		}
+
+		offset := time.Duration(4 * toDeg(math.Acos(cosHA)) * float64(time.Minute))
+		if rising {
+			t = noon.Add(-offset)
+		} else {
+			t = noon.Add(offset)
+		}
+	}
+
+	t = t.Round(time.Second).In(loc)
+	return &t
+}
+
+// sunPosition returns azimuth and elevation in degrees, elevation corrected for refraction
+func sunPosition(lat, lng float64, t time.Time) (azimuth, elevation float64) {
+	decl, eqTime := solarParams(t)
+
+	utc := t.UTC()
+	minutes := float64(utc.Hour()*60+utc.Minute()) + float64(utc.Second())/60
+	trueSolarTime := math.Mod(minutes+eqTime+4*lng, 1440)
+
+	hourAngle := trueSolarTime/4 - 180
+	if hourAngle < -180 {
+		hourAngle += 360
+	}
+
+	cosZenith := math.Sin(toRad(lat))*math.Sin(toRad(decl)) +
+		math.Cos(toRad(lat))*math.Cos(toRad(decl))*math.Cos(toRad(hourAngle))
+	cosZenith = math.Max(-1, math.Min(1, cosZenith))
+	zenith := toDeg(math.Acos(cosZenith))
+
+	elevation = 90 - zenith + refraction(90-zenith)
+
+	sinZenith := math.Sin(toRad(zenith))
+	if sinZenith == 0 {
+		return 180, elevation
+	}
+
+	cosAz := (math.Sin(toRad(lat))*cosZenith - math.Sin(toRad(decl))) / (math.Cos(toRad(lat)) * sinZenith)
+	cosAz = math.Max(-1, math.Min(1, cosAz))
+	az := toDeg(math.Acos(cosAz))
+	if hourAngle > 0 {
+		azimuth = math.Mod(az+180, 360)
+	} else {
+		azimuth = math.Mod(540-az, 360)
+	}
+
+	return azimuth, elevation
+}
+
+// refraction approximates atmospheric refraction (degrees) for an apparent elevation
+func refraction(elevation float64) float64 {
+	if elevation > 85 {
+		return 0
+	}
+
+	te := math.Tan(toRad(elevation))
+	var arcSeconds float64
+	switch {
+	case elevation > 5:
+		arcSeconds = 58.1/te - 0.07/math.Pow(te, 3) + 0.000086/math.Pow(te, 5)
+	case elevation > -0.575:
+		arcSeconds = 1735 + elevation*(-518.2+elevation*(103.4+elevation*(-12.79+elevation*0.711)))
+	default:
+		arcSeconds = -20.774 / te
+	}
+
+	return arcSeconds / 3600
+}
@@ -0,0 +1,57 @@
+package services
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGetLightWindowsLocalDay(t *testing.T) {
+	tests := []struct {
+		name     string
+		zone     string
+		lat, lng float64
+	}{
+		{"Sofia", "Europe/Sofia", 42.70, 23.32},
+		{"Apia, UTC+13 at 172°W", "Pacific/Apia", -13.83, -171.76},
+		{"Kiritimati, UTC+14 at 157°W", "Pacific/Kiritimati", 1.87, -157.43},
+		{"Honolulu", "Pacific/Honolulu", 21.31, -157.86},
+		{"Auckland", "Pacific/Auckland", -36.85, 174.76},
+	}
+
+	s := NewLightService()
+	day := time.Date(2026, 6, 21, 0, 0, 0, 0, time.UTC)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loc, err := time.LoadLocation(tt.zone)
+			if err != nil {
+				t.Skipf("no tzdata for %s: %v", tt.zone, err)
+			}
+
+			w := s.GetLightWindows(tt.lat, tt.lng, day, loc)
+
+			if w.Date != "2026-06-21" {
+				t.Errorf("date %s", w.Date)
+			}
+
+			for name, at := range map[string]*time.Time{"sunrise": w.Sunrise, "solar noon": &w.SolarNoon, "sunset": w.Sunset} {
+				if at == nil {
+					t.Errorf("no %s", name)
+					continue
+				}
+				if got := at.Format("2006-01-02"); got != "2026-06-21" {
+					t.Errorf("%s at %s, want it on 2026-06-21", name, at.Format(time.RFC3339))
+				}
+			}
+
+			// Solar noon is within a couple of hours of 12:00 wherever the zone is sensible
+			local := w.SolarNoon.Hour()*60 + w.SolarNoon.Minute()
+			if local < 10*60 || local > 15*60 {
+				t.Errorf("solar noon at %s", w.SolarNoon.Format(time.Kitchen))
+			}
+			if w.NoonPosition.Elevation < 25 {
+				t.Errorf("sun only %.1f° high at noon", w.NoonPosition.Elevation)
+			}
+		})
+	}
+}
@@ -0,0 +1,147 @@
+package services
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/georgiev098/film-manager/backend/internal/core"
+	"github.com/georgiev098/film-manager/backend/internal/ratelimit"
+)
+
+// LoginGuard limits login attempts per IP and slows down, then locks, accounts
+// that keep failing. Accounts are keyed by email, whether they exist or not,
+// so the responses don't reveal which addresses are registered.
+type LoginGuard struct {
+	store  ratelimit.Store
+	policy core.LoginProtection
+}
+
+func NewLoginGuard(store ratelimit.Store, policy core.LoginProtection) *LoginGuard {
+	return &LoginGuard{
+		store:  store,
+		policy: policy,
+	}
+}
+
+// CheckIP counts a login attempt from ip. A positive duration means the IP is
+// over its limit and has to wait that long.
+func (g *LoginGuard) CheckIP(ctx context.Context, ip string) (time.Duration, error) {
+	entry, err := g.store.Hit(ctx, "login:ip:"+ip, g.policy.IPWindow)
+	if err != nil {
+		return 0, err
+	}
+
+	if entry.Count > g.policy.IPLimit {
+		return time.Until(entry.ResetAt), nil
+	}
+
+	return 0, nil
+}
+
+// BeginLogin counts a password attempt for the account before the password is
+// checked. Counting and checking the block happen in one step, so parallel
+// requests cannot all slip past the delay. A positive duration means the
+// account is blocked for that long and the attempt was not counted. Follow up
+// with LoginSucceeded, or LoginAborted if the attempt failed for any other
+// reason than wrong credentials.
+func (g *LoginGuard) BeginLogin(ctx context.Context, email string) (time.Duration, error) {
+	return g.begin(ctx, accountKey(email))
+}
+
+// LoginSucceeded clears the failures of an account after a correct password
+func (g *LoginGuard) LoginSucceeded(ctx context.Context, email string) error {
+	return g.store.Reset(ctx, accountKey(email))
+}
+
+// LoginAborted takes back an attempt that was not a wrong guess, e.g. a
+// disabled account or a database error
+func (g *LoginGuard) LoginAborted(ctx context.Context, email string) error {
+	return g.store.Undo(ctx, accountKey(email), g.failureDelay)
+}
+
+// BeginSecondFactor is BeginLogin for the TOTP or recovery code step. It is
+// counted apart from passwords, so knowing the password and logging in again
+// doesn't reset the second factor's failures.
+func (g *LoginGuard) BeginSecondFactor(ctx context.Context, userID uint) (time.Duration, error) {
+	return g.begin(ctx, secondFactorKey(userID))
+}
+
+func (g *LoginGuard) SecondFactorSucceeded(ctx context.Context, userID uint) error {
+	return g.store.Reset(ctx, secondFactorKey(userID))
+}
+
+func (g *LoginGuard) SecondFactorAborted(ctx context.Context, userID uint) error {
+	return g.store.Undo(ctx, secondFactorKey(userID), g.failureDelay)
+}
+
+// AllowMail counts a request from ip to email address about purpose, e.g. a
+// password reset. It returns false once the IP or the address is over its
+// limit. Addresses are counted whether they have an account or not.
+func (g *LoginGuard) AllowMail(ctx context.Context, ip, purpose, address string) (bool, error) {
+	entry, err := g.store.Hit(ctx, "mail:ip:"+ip, g.policy.MailWindow)
+	if err != nil {
+		return false, err
+	}
+	if entry.Count > g.policy.MailIPLimit {
+		return false, nil
+	}
+
+	entry, err = g.store.Hit(ctx, "mail:"+purpose+":"+normalizeEmail(address), g.policy.MailWindow)
+	if err != nil {
+		return false, err
+	}
+
+	return entry.Count <= g.policy.MailAddressLimit, nil
+}
+
+// Unlock lifts a lockout early, used by admins and account erasure
+func (g *LoginGuard) Unlock(ctx context.Context, userID uint, email string) error {
+	err := g.store.Reset(ctx, accountKey(email))
+	if err != nil {
+		return err
+	}
+
+	return g.store.Reset(ctx, secondFactorKey(userID))
+}
+
+// begin counts an attempt as if it fails. Past DelayAfter attempts each one
+// blocks the key for twice as long as the previous; at LockoutThreshold it is
+// locked for LockoutDuration.
+func (g *LoginGuard) begin(ctx context.Context, key string) (time.Duration, error) {
+	entry, ok, err := g.store.Attempt(ctx, key, g.policy.LockoutWindow, g.failureDelay)
+	if err != nil {
+		return 0, err
+	}
+
+	if !ok {
+		return max(time.Until(entry.BlockedUntil), time.Second), nil
+	}
+
+	return 0, nil
+}
+
+func (g *LoginGuard) failureDelay(count int) time.Duration {
+	switch {
+	case count >= g.policy.LockoutThreshold:
+		return g.policy.LockoutDuration
+	case count >= g.policy.DelayAfter:
+		shift := min(count-g.policy.DelayAfter, 30)
+		return min(time.Second<<shift, g.policy.LockoutDuration)
+	default:
+		return 0
+	}
+}
+
+func accountKey(email string) string {
+	return "login:account:" + normalizeEmail(email)
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+func secondFactorKey(userID uint) string {
+	return "login:mfa:" + strconv.FormatUint(uint64(userID), 10)
+}
@@ -0,0 +1,211 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/georgiev098/film-manager/backend/internal/core"
+	"github.com/georgiev098/film-manager/backend/internal/ratelimit"
+)
+
+var testLoginPolicy = core.LoginProtection{
+	IPLimit:          10,
+	IPWindow:         time.Minute,
+	DelayAfter:       3,
+	LockoutThreshold: 5,
+	LockoutWindow:    15 * time.Minute,
+	LockoutDuration:  15 * time.Minute,
+	MailIPLimit:      4,
+	MailAddressLimit: 2,
+	MailWindow:       time.Hour,
+}
+
+func TestLoginGuardFailureDelay(t *testing.T) {
+	g := NewLoginGuard(ratelimit.NewMemoryStore(), testLoginPolicy)
+
+	tests := []struct {
+		count int
+		want  time.Duration
+	}{
+		{1, 0},
+		{2, 0},
+		{3, time.Second},
+		{4, 2 * time.Second},
+		{5, 15 * time.Minute},
+		{50, 15 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		if got := g.failureDelay(tt.count); got != tt.want {
+			t.Errorf("failureDelay(%d) = %v, want %v", tt.count, got, tt.want)
+		}
+	}
+}
+
+func TestLoginGuardBeginLogin(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		// Steps before the checked attempt: "fail" leaves an attempt counted,
+		// "abort" takes it back, "ok" is a successful login
+		steps       []string
+		wantBlocked bool
+	}{
+		{"first attempt", nil, false},
+		{"below the delay", []string{"fail", "fail"}, false},
+		{"delayed after three failures", []string{"fail", "fail", "fail"}, true},
+		{"aborted attempts don't count", []string{"fail", "abort", "abort", "abort", "abort"}, false},
+		{"aborts don't leave a delay behind", []string{"fail", "abort", "fail", "abort"}, false},
+		{"success resets", []string{"fail", "fail", "ok"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewLoginGuard(ratelimit.NewMemoryStore(), testLoginPolicy)
+			email := "user@example.com"
+
+			for _, step := range tt.steps {
+				wait, err := g.BeginLogin(ctx, email)
+				if err != nil || wait > 0 {
+					t.Fatalf("step %q: wait %v, err %v", step, wait, err)
+				}
+
+				switch step {
+				case "abort":
+					err = g.LoginAborted(ctx, email)
+				case "ok":
+					err = g.LoginSucceeded(ctx, email)
+				}
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			wait, err := g.BeginLogin(ctx, "  USER@example.com ")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if blocked := wait > 0; blocked != tt.wantBlocked {
+				t.Errorf("blocked = %v (wait %v), want %v", blocked, wait, tt.wantBlocked)
+			}
+		})
+	}
+}
+
+// Parallel attempts must not slip past the delay between checking and counting
+func TestLoginGuardConcurrentAttempts(t *testing.T) {
+	g := NewLoginGuard(ratelimit.NewMemoryStore(), testLoginPolicy)
+	ctx := context.Background()
+
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		allowed int
+	)
+
+	for range 50 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			wait, err := g.BeginLogin(ctx, "user@example.com")
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if wait == 0 {
+				mu.Lock()
+				allowed++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if allowed != testLoginPolicy.DelayAfter {
+		t.Errorf("%d attempts allowed, want %d", allowed, testLoginPolicy.DelayAfter)
+	}
+}
+
+func TestLoginGuardSecondFactorIsSeparate(t *testing.T) {
+	g := NewLoginGuard(ratelimit.NewMemoryStore(), testLoginPolicy)
+	ctx := context.Background()
+
+	for range testLoginPolicy.DelayAfter {
+		if _, err := g.BeginSecondFactor(ctx, 7); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// A correct password doesn't clear the second factor's failures
+	if err := g.LoginSucceeded(ctx, "user@example.com"); err != nil {
+		t.Fatal(err)
+	}
+
+	wait, err := g.BeginSecondFactor(ctx, 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if wait == 0 {
+		t.Error("second factor should be delayed")
+	}
+
+	wait, err = g.BeginSecondFactor(ctx, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if wait != 0 {
+		t.Errorf("another user waits %v", wait)
+	}
+
+	if err := g.Unlock(ctx, 7, "user@example.com"); err != nil {
+		t.Fatal(err)
+	}
+
+	wait, err = g.BeginSecondFactor(ctx, 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if wait != 0 {
+		t.Errorf("unlocked user waits %v", wait)
+	}
+}
+
+func TestLoginGuardAllowMail(t *testing.T) {
+	ctx := context.Background()
+	g := NewLoginGuard(ratelimit.NewMemoryStore(), testLoginPolicy)
+
+	allow := func(ip, purpose, address string) bool {
+		t.Helper()
+
+		ok, err := g.AllowMail(ctx, ip, purpose, address)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ok
+	}
+
+	// Two per address, however it is written
+	if !allow("10.0.0.1", "password_reset", "ansel@example.com") || !allow("10.0.0.2", "password_reset", " Ansel@Example.com") {
+		t.Fatal("first two mails to an address refused")
+	}
+	if allow("10.0.0.3", "password_reset", "ansel@example.com") {
+		t.Error("third mail to an address allowed")
+	}
+	if !allow("10.0.0.3", "verification", "ansel@example.com") {
+		t.Error("verification mail counted with password resets")
+	}
+
+	// Four per IP, whatever the addresses
+	for i, address := range []string{"a@example.com", "b@example.com", "c@example.com"} {
+		if !allow("10.0.0.1", "password_reset", address) {
+			t.Fatalf("mail %d from an IP refused", i+2)
+		}
+	}
+	if allow("10.0.0.1", "password_reset", "d@example.com") {
+		t.Error("fifth mail from an IP allowed")
+	}
+}
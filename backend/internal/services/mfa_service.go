@@ -0,0 +1,413 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/hex"
+	"image/png"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/georgiev098/film-manager/backend/internal/apperrors"
+	"github.com/georgiev098/film-manager/backend/internal/audit"
+	"github.com/georgiev098/film-manager/backend/internal/models"
+	"github.com/georgiev098/film-manager/backend/internal/password"
+	"github.com/georgiev098/film-manager/backend/internal/ratelimit"
+	"github.com/georgiev098/film-manager/backend/internal/repositories"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/pquerna/otp/totp"
+)
+
+const (
+	mfaIssuer          = "Film Manager"
+	mfaChallengeTTL    = 5 * time.Minute
+	mfaChallengeType   = "mfa"
+	mfaChallengeTries  = 5 // codes that can be tried against one challenge
+	totpPeriod         = 30
+	recoveryCodeCount  = 10
+	recoveryCodeLength = 10
+)
+
+var (
+	ErrInvalidMFACode      = apperrors.Unauthorized("invalid authentication code")
+	ErrInvalidMFAChallenge = apperrors.Unauthorized("invalid or expired MFA challenge")
+	ErrMFAAlreadyEnabled   = apperrors.Conflict("two-factor authentication is already enabled")
+	ErrMFANotEnrolled      = apperrors.Conflict("start two-factor enrolment first")
+	ErrMFANotEnabled       = apperrors.Conflict("two-factor authentication is not enabled")
+)
+
+var (
+	recoveryCodeEncoding   = base32.StdEncoding.WithPadding(base32.NoPadding)
+	recoveryCodeNormalizer = strings.NewReplacer("-", "", " ", "")
+)
+
+type MFAService struct {
+	userRepo     *repositories.UserRepository
+	recoveryRepo *repositories.RecoveryCodeRepository
+	audit        *audit.Service
+	passwords    *password.Hasher
+	limits       ratelimit.Store // attempts per challenge
+	challengeKey []byte
+	recoveryKey  []byte
+}
+
+// The challenge and recovery code keys are derived from the access secret but
+// differ from it, so a challenge token can never pass as an access token.
+func NewMFAService(
+	userRepo *repositories.UserRepository,
+	recoveryRepo *repositories.RecoveryCodeRepository,
+	audit *audit.Service,
+	passwords *password.Hasher,
+	limits ratelimit.Store,
+	accessSecret string,
+) *MFAService {
+	return &MFAService{
+		userRepo:     userRepo,
+		recoveryRepo: recoveryRepo,
+		audit:        audit,
+		passwords:    passwords,
+		limits:       limits,
+		challengeKey: []byte(accessSecret + "|mfa-challenge"),
+		recoveryKey:  []byte(accessSecret + "|recovery-codes"),
+	}
+}
+
+// Enrollment is returned when a user starts setting up TOTP
+type Enrollment struct {
+	Secret     string `json:"secret"`
+	OTPAuthURI string `json:"otpauth_uri"`
+	QRCodePNG  []byte `json:"qr_code_png"` // base64 in JSON
+}
+
+type mfaChallengeClaims struct {
+	Type string `json:"typ"`
+	jwt.RegisteredClaims
+}
+
+// Enroll generates a new TOTP secret after re-authenticating the user. It is
+// not enforced until Confirm.
+func (s *MFAService) Enroll(ctx context.Context, userID uint, reauth Reauth) (*Enrollment, error) {
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.TOTPEnabled {
+		return nil, ErrMFAAlreadyEnabled
+	}
+
+	err = reauth.verify(s.passwords, user)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := totp.Generate(totp.GenerateOpts{
+		Issuer:      mfaIssuer,
+		AccountName: user.Email,
+		Period:      totpPeriod,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	img, err := key.Image(256, 256)
+	if err != nil {
+		return nil, err
+	}
+
+	var qr bytes.Buffer
+	if err := png.Encode(&qr, img); err != nil {
+		return nil, err
+	}
+
+	err = s.userRepo.UpdateUser(ctx, user, map[string]any{
+		"totp_secret":    key.Secret(),
+		"totp_enabled":   false,
+		"totp_last_step": 0,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &Enrollment{
+		Secret:     key.Secret(),
+		OTPAuthURI: key.URL(),
+		QRCodePNG:  qr.Bytes(),
+	}, nil
+}
+
+// Confirm enables TOTP once the user re-authenticates and proves their
+// authenticator works, and returns a fresh set of recovery codes to show
+// exactly once.
+func (s *MFAService) Confirm(ctx context.Context, userID uint, code string, reauth Reauth) ([]string, error) {
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.TOTPEnabled {
+		return nil, ErrMFAAlreadyEnabled
+	}
+	if user.TOTPSecret == "" {
+		return nil, ErrMFANotEnrolled
+	}
+
+	err = reauth.verify(s.passwords, user)
+	if err != nil {
+		return nil, err
+	}
+
+	ok, err := s.verifyTOTP(ctx, user, code)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrInvalidMFACode
+	}
+
+	err = s.userRepo.UpdateUser(ctx, user, map[string]any{"totp_enabled": true})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.newRecoveryCodes(ctx, user.ID)
+}
+
+// Disable turns TOTP off after checking a current code or a recovery code
+func (s *MFAService) Disable(ctx context.Context, userID uint, code string) error {
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if !user.TOTPEnabled {
+		return ErrMFANotEnabled
+	}
+
+	ok, err := s.verifySecondFactor(ctx, user, code)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrInvalidMFACode
+	}
+
+	err = s.userRepo.UpdateUser(ctx, user, map[string]any{
+		"totp_secret":    "",
+		"totp_enabled":   false,
+		"totp_last_step": 0,
+	})
+	if err != nil {
+		return err
+	}
+
+	return s.recoveryRepo.DeleteAllByUser(ctx, user.ID)
+}
+
+// RegenerateRecoveryCodes replaces all recovery codes after checking a current TOTP code
+func (s *MFAService) RegenerateRecoveryCodes(ctx context.Context, userID uint, code string) ([]string, error) {
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !user.TOTPEnabled {
+		return nil, ErrMFANotEnabled
+	}
+
+	ok, err := s.verifyTOTP(ctx, user, code)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrInvalidMFACode
+	}
+
+	return s.newRecoveryCodes(ctx, user.ID)
+}
+
+// CreateChallenge issues the short-lived token a client trades, together with
+// a second factor, for real tokens at POST /auth/login/mfa. Each challenge can
+// be completed once and takes at most mfaChallengeTries codes.
+func (s *MFAService) CreateChallenge(user *models.User) (string, error) {
+	jti, err := newRandomID()
+	if err != nil {
+		return "", err
+	}
+
+	claims := mfaChallengeClaims{
+		Type: mfaChallengeType,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        jti,
+			Subject:   strconv.FormatUint(uint64(user.ID), 10),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(mfaChallengeTTL)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	}
+
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(s.challengeKey)
+}
+
+// ChallengeUserID returns whom a valid challenge token was issued to, so
+// attempts can be limited per account before the code is checked
+func (s *MFAService) ChallengeUserID(challenge string) (uint, error) {
+	claims, err := s.parseChallenge(challenge)
+	if err != nil {
+		return 0, err
+	}
+
+	return claims.userID, nil
+}
+
+// CompleteChallenge checks the challenge token and the second factor, which
+// may be a TOTP code or a recovery code, and returns the user to log in
+func (s *MFAService) CompleteChallenge(ctx context.Context, challenge, code string) (*models.User, error) {
+	claims, err := s.parseChallenge(challenge)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.GetUserByID(ctx, claims.userID)
+	if err != nil || user.Disabled || !user.TOTPEnabled {
+		return nil, ErrInvalidMFAChallenge
+	}
+
+	// Once the tries are used up, the challenge is blocked until it expires
+	key := "mfa:challenge:" + claims.id
+	_, allowed, err := s.limits.Attempt(ctx, key, mfaChallengeTTL, func(count int) time.Duration {
+		if count < mfaChallengeTries {
+			return 0
+		}
+		return time.Until(claims.expiresAt)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, ErrInvalidMFAChallenge
+	}
+
+	ok, err := s.verifySecondFactor(ctx, user, code)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		s.audit.Record(ctx, audit.Entry{
+			UserID:  user.ID,
+			Action:  models.AuditLoginFailed,
+			Details: map[string]any{"reason": "wrong_mfa_code"},
+		})
+		return nil, ErrInvalidMFACode
+	}
+
+	// Used up, so the same challenge can't log in a second time
+	err = s.limits.Block(ctx, key, claims.expiresAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+type parsedChallenge struct {
+	id        string
+	userID    uint
+	expiresAt time.Time
+}
+
+func (s *MFAService) parseChallenge(challenge string) (*parsedChallenge, error) {
+	var claims mfaChallengeClaims
+
+	_, err := jwt.ParseWithClaims(challenge, &claims, func(token *jwt.Token) (any, error) {
+		return s.challengeKey, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithExpirationRequired())
+	if err != nil || claims.Type != mfaChallengeType || claims.ID == "" {
+		return nil, ErrInvalidMFAChallenge
+	}
+
+	userID, err := strconv.ParseUint(claims.Subject, 10, 64)
+	if err != nil {
+		return nil, ErrInvalidMFAChallenge
+	}
+
+	return &parsedChallenge{
+		id:        claims.ID,
+		userID:    uint(userID),
+		expiresAt: claims.ExpiresAt.Time,
+	}, nil
+}
+
+// verifySecondFactor accepts either a 6-digit TOTP code or a recovery code
+func (s *MFAService) verifySecondFactor(ctx context.Context, user *models.User, code string) (bool, error) {
+	code = strings.TrimSpace(code)
+
+	if len(code) == 6 {
+		return s.verifyTOTP(ctx, user, code)
+	}
+
+	return s.recoveryRepo.Redeem(ctx, user.ID, s.hashRecoveryCode(normalizeRecoveryCode(code)))
+}
+
+// verifyTOTP accepts codes from the previous, current and next time step, each at most once
+func (s *MFAService) verifyTOTP(ctx context.Context, user *models.User, code string) (bool, error) {
+	now := time.Now()
+
+	for _, offset := range []int64{-1, 0, 1} {
+		at := now.Add(time.Duration(offset*totpPeriod) * time.Second)
+
+		expected, err := totp.GenerateCode(user.TOTPSecret, at)
+		if err != nil {
+			return false, err
+		}
+
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) != 1 {
+			continue
+		}
+
+		step := at.Unix() / totpPeriod
+		return s.userRepo.AdvanceTOTPStep(ctx, user.ID, step)
+	}
+
+	return false, nil
+}
+
+func (s *MFAService) newRecoveryCodes(ctx context.Context, userID uint) ([]string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	rows := make([]models.RecoveryCode, 0, recoveryCodeCount)
+
+	for range recoveryCodeCount {
+		raw := make([]byte, 8)
+		if _, err := rand.Read(raw); err != nil {
+			return nil, err
+		}
+
+		code := strings.ToLower(recoveryCodeEncoding.EncodeToString(raw)[:recoveryCodeLength])
+		codes = append(codes, code[:5]+"-"+code[5:])
+		rows = append(rows, models.RecoveryCode{UserID: userID, CodeHash: s.hashRecoveryCode(code)})
+	}
+
+	err := s.recoveryRepo.Replace(ctx, userID, rows)
+	if err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// hashRecoveryCode keys the hash with a server secret. Recovery codes are
+// short enough to brute force from a leaked table if they were hashed plainly.
+func (s *MFAService) hashRecoveryCode(code string) string {
+	mac := hmac.New(sha256.New, s.recoveryKey)
+	mac.Write([]byte(code))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func normalizeRecoveryCode(code string) string {
+	return strings.ToLower(recoveryCodeNormalizer.Replace(code))
+}
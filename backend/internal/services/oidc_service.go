@@ -0,0 +1,315 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"github.com/georgiev098/film-manager/backend/internal/apperrors"
+	"github.com/georgiev098/film-manager/backend/internal/core"
+	"github.com/georgiev098/film-manager/backend/internal/dtos"
+	"github.com/georgiev098/film-manager/backend/internal/helpers"
+	"github.com/georgiev098/film-manager/backend/internal/models"
+	"github.com/georgiev098/film-manager/backend/internal/repositories"
+	"github.com/go-playground/validator/v10"
+	"golang.org/x/oauth2"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrUnknownOIDCProvider  = apperrors.NotFound("unknown identity provider")
+	ErrOIDCLoginFailed      = apperrors.Unauthorized("identity provider login failed")
+	ErrOIDCEmailNotVerified = apperrors.Forbidden("identity provider did not verify the email address")
+	ErrOIDCAccountNotLinked = apperrors.Conflict("an account with this email address exists but was never verified, sign in with its password first")
+	ErrOIDCProfileInvalid   = apperrors.Validation("identity provider did not share a usable name")
+)
+
+// OIDCFlow is the per-login state kept by the browser between redirect and callback
+type OIDCFlow struct {
+	Provider string `json:"provider"`
+	State    string `json:"state"`
+	Nonce    string `json:"nonce"`
+	Verifier string `json:"verifier"` // PKCE code verifier
+}
+
+type oidcClient struct {
+	oauth    *oauth2.Config
+	verifier *oidc.IDTokenVerifier
+}
+
+type oidcClaims struct {
+	Subject       string `json:"sub"`
+	Email         string `json:"email"`
+	EmailVerified bool   `json:"email_verified"`
+	GivenName     string `json:"given_name"`
+	FamilyName    string `json:"family_name"`
+	Name          string `json:"name"`
+	Nonce         string `json:"nonce"`
+}
+
+type OIDCService struct {
+	userRepo        *repositories.UserRepository
+	identityRepo    *repositories.UserIdentityRepository
+	validate        *validator.Validate
+	providers       map[string]core.OIDCProvider
+	order           []string
+	redirectBaseURL string
+
+	// Discovery documents are fetched on first use, so an unreachable
+	// provider doesn't stop the API from starting
+	mu      sync.Mutex
+	clients map[string]*oidcClient
+}
+
+func NewOIDCService(userRepo *repositories.UserRepository, identityRepo *repositories.UserIdentityRepository, validate *validator.Validate, providers []core.OIDCProvider, redirectBaseURL string) *OIDCService {
+	s := &OIDCService{
+		userRepo:        userRepo,
+		identityRepo:    identityRepo,
+		validate:        validate,
+		providers:       make(map[string]core.OIDCProvider, len(providers)),
+		redirectBaseURL: strings.TrimRight(redirectBaseURL, "/"),
+		clients:         make(map[string]*oidcClient),
+	}
+
+	for _, p := range providers {
+		s.providers[p.Name] = p
+		s.order = append(s.order, p.Name)
+	}
+
+	return s
+}
+
+func (s *OIDCService) Providers() []dtos.OIDCProvider {
+	result := make([]dtos.OIDCProvider, 0, len(s.order))
+	for _, name := range s.order {
+		result = append(result, dtos.OIDCProvider{
+			Name:        name,
+			DisplayName: s.providers[name].DisplayName,
+			LoginURL:    fmt.Sprintf("/auth/oidc/%s/login", name),
+		})
+	}
+
+	return result
+}
+
+// Begin starts an authorization code flow with PKCE and returns the URL to send the browser to
+func (s *OIDCService) Begin(ctx context.Context, provider string) (string, *OIDCFlow, error) {
+	client, err := s.client(ctx, provider)
+	if err != nil {
+		return "", nil, err
+	}
+
+	state, err := randomString()
+	if err != nil {
+		return "", nil, err
+	}
+
+	nonce, err := randomString()
+	if err != nil {
+		return "", nil, err
+	}
+
+	flow := &OIDCFlow{
+		Provider: provider,
+		State:    state,
+		Nonce:    nonce,
+		Verifier: oauth2.GenerateVerifier(),
+	}
+
+	authURL := client.oauth.AuthCodeURL(state,
+		oidc.Nonce(nonce),
+		oauth2.S256ChallengeOption(flow.Verifier),
+	)
+
+	return authURL, flow, nil
+}
+
+// Complete exchanges the authorization code, verifies the ID token and returns
+// the linked user, linking or creating one on first login
+func (s *OIDCService) Complete(ctx context.Context, flow *OIDCFlow, state, code string) (*models.User, error) {
+	if flow == nil || state == "" || subtle.ConstantTimeCompare([]byte(flow.State), []byte(state)) != 1 {
+		return nil, ErrOIDCLoginFailed
+	}
+
+	client, err := s.client(ctx, flow.Provider)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := client.oauth.Exchange(ctx, code, oauth2.VerifierOption(flow.Verifier))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrOIDCLoginFailed, err)
+	}
+
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok {
+		return nil, fmt.Errorf("%w: no id_token in token response", ErrOIDCLoginFailed)
+	}
+
+	idToken, err := client.verifier.Verify(ctx, rawIDToken)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrOIDCLoginFailed, err)
+	}
+
+	var claims oidcClaims
+	err = idToken.Claims(&claims)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrOIDCLoginFailed, err)
+	}
+
+	if subtle.ConstantTimeCompare([]byte(claims.Nonce), []byte(flow.Nonce)) != 1 {
+		return nil, fmt.Errorf("%w: nonce mismatch", ErrOIDCLoginFailed)
+	}
+
+	user, err := s.resolveUser(ctx, flow.Provider, &claims)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.Disabled {
+		return nil, ErrUserDisabled
+	}
+
+	return user, nil
+}
+
+// resolveUser finds the user linked to the identity. A new identity is linked to
+// the account with the same email, or gets a new password-less account, but
+// only if the provider vouches for the email address.
+func (s *OIDCService) resolveUser(ctx context.Context, provider string, claims *oidcClaims) (*models.User, error) {
+	identity, err := s.identityRepo.FindByProviderSubject(ctx, provider, claims.Subject)
+	if err == nil {
+		return s.userRepo.GetUserByID(ctx, identity.UserID)
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	email := strings.ToLower(strings.TrimSpace(claims.Email))
+	if email == "" || !claims.EmailVerified {
+		return nil, ErrOIDCEmailNotVerified
+	}
+
+	user, err := s.userRepo.GetUserByEmail(ctx, email)
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		user, err = s.createUser(ctx, email, claims)
+		if err != nil {
+			return nil, err
+		}
+	case err != nil:
+		return nil, err
+	case user.EmailVerifiedAt == nil:
+		// Anyone can sign up with an address they don't own and wait for its
+		// owner to arrive through a provider. Linking would hand the owner's
+		// login to an account whose password the squatter knows.
+		return nil, ErrOIDCAccountNotLinked
+	}
+
+	err = s.identityRepo.Create(ctx, &models.UserIdentity{
+		UserID:   user.ID,
+		Provider: provider,
+		Subject:  claims.Subject,
+		Email:    email,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// createUser signs up a user from the provider's profile. Missing names fall
+// back to the local part of the email address.
+func (s *OIDCService) createUser(ctx context.Context, email string, claims *oidcClaims) (*models.User, error) {
+	firstName, lastName := claims.GivenName, claims.FamilyName
+	if firstName == "" && lastName == "" {
+		firstName, lastName, _ = strings.Cut(strings.TrimSpace(claims.Name), " ")
+	}
+
+	localPart, _, _ := strings.Cut(email, "@")
+
+	verifiedAt := time.Now()
+
+	user := &models.User{
+		Email:           email,
+		FirstName:       profileName(firstName, localPart),
+		LastName:        profileName(lastName, localPart),
+		Role:            models.RoleUser,
+		EmailVerifiedAt: &verifiedAt,
+	}
+
+	// The password is the only other required field and these users have none
+	err := s.validate.StructPartial(user, "Email", "FirstName", "LastName")
+	if err != nil {
+		return nil, ErrOIDCProfileInvalid
+	}
+
+	err = s.userRepo.CraeteUser(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// profileName trims a name to the 50 bytes users may have, or returns
+// fallback for an empty one
+func profileName(name, fallback string) string {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		name = fallback
+	}
+
+	return helpers.Truncate(name, 50)
+}
+
+func (s *OIDCService) client(ctx context.Context, name string) (*oidcClient, error) {
+	cfg, ok := s.providers[name]
+	if !ok {
+		return nil, ErrUnknownOIDCProvider
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if client, ok := s.clients[name]; ok {
+		return client, nil
+	}
+
+	provider, err := oidc.NewProvider(ctx, cfg.Issuer)
+	if err != nil {
+		return nil, fmt.Errorf("oidc discovery for %s: %w", name, err)
+	}
+
+	client := &oidcClient{
+		oauth: &oauth2.Config{
+			ClientID:     cfg.ClientID,
+			ClientSecret: cfg.ClientSecret,
+			Endpoint:     provider.Endpoint(),
+			RedirectURL:  fmt.Sprintf("%s/auth/oidc/%s/callback", s.redirectBaseURL, name),
+			Scopes:       cfg.Scopes,
+		},
+		verifier: provider.Verifier(&oidc.Config{ClientID: cfg.ClientID}),
+	}
+
+	s.clients[name] = client
+	return client, nil
+}
+
+func randomString() (string, error) {
+	raw := make([]byte, 24)
+	_, err := rand.Read(raw)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
@@ -0,0 +1,149 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/georgiev098/film-manager/backend/internal/apperrors"
+	"github.com/georgiev098/film-manager/backend/internal/mailer"
+	"github.com/georgiev098/film-manager/backend/internal/models"
+	"github.com/georgiev098/film-manager/backend/internal/password"
+	"github.com/georgiev098/film-manager/backend/internal/repositories"
+)
+
+var ErrInvalidResetToken = apperrors.Validation("invalid or expired reset token")
+
+type PasswordResetService struct {
+	resetRepo *repositories.PasswordResetRepository
+	userRepo  *repositories.UserRepository
+	passwords *password.Hasher
+	mailer    mailer.Mailer
+	logger    *log.Logger
+	appURL    string
+	ttl       time.Duration
+}
+
+func NewPasswordResetService(
+	resetRepo *repositories.PasswordResetRepository,
+	userRepo *repositories.UserRepository,
+	passwords *password.Hasher,
+	mail mailer.Mailer,
+	logger *log.Logger,
+	appURL string,
+	ttl time.Duration,
+) *PasswordResetService {
+	return &PasswordResetService{
+		resetRepo: resetRepo,
+		userRepo:  userRepo,
+		passwords: passwords,
+		mailer:    mail,
+		logger:    logger,
+		appURL:    appURL,
+		ttl:       ttl,
+	}
+}
+
+// RequestReset emails a reset link if the address belongs to an active user.
+// It reports success either way so callers cannot probe for accounts.
+func (s *PasswordResetService) RequestReset(ctx context.Context, email string) error {
+	email = strings.ToLower(strings.TrimSpace(email))
+
+	user, err := s.userRepo.GetUserByEmail(ctx, email)
+	if err != nil || user.Disabled {
+		return nil
+	}
+
+	// Only the newest link should work
+	err = s.resetRepo.InvalidateAllByUser(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+
+	token, tokenHash, err := newOpaqueToken()
+	if err != nil {
+		return err
+	}
+
+	err = s.resetRepo.Create(ctx, &models.PasswordResetToken{
+		UserID:    user.ID,
+		TokenHash: tokenHash,
+		ExpiresAt: time.Now().Add(s.ttl),
+	})
+	if err != nil {
+		return err
+	}
+
+	link := fmt.Sprintf("%s/reset-password?token=%s", strings.TrimRight(s.appURL, "/"), url.QueryEscape(token))
+
+	msg := mailer.Message{
+		To:      user.Email,
+		Subject: "Reset your Film Manager password",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nSomeone asked to reset the password for your Film Manager account.\n"+
+				"Use the link below within %d minutes to choose a new one:\n\n%s\n\n"+
+				"If this wasn't you, you can ignore this email.\n",
+			user.FirstName, int(s.ttl.Minutes()), link,
+		),
+	}
+
+	// Send in the background so response times don't reveal whether the account exists
+	go func() {
+		sendCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		if err := s.mailer.Send(sendCtx, msg); err != nil {
+			s.logger.Printf("password reset mail to user %d failed: %v", user.ID, err)
+		}
+	}()
+
+	return nil
+}
+
+// UserForToken returns the user a still valid reset token belongs to, without redeeming it
+func (s *PasswordResetService) UserForToken(ctx context.Context, token string) (*models.User, error) {
+	rt, err := s.resetRepo.FindTokenByHash(ctx, hashToken(token))
+	if err != nil || rt.UsedAt != nil || rt.ExpiresAt.Before(time.Now()) {
+		return nil, ErrInvalidResetToken
+	}
+
+	return s.userRepo.GetUserByID(ctx, rt.UserID)
+}
+
+// ResetPassword redeems a reset token, sets the new password and logs the user
+// out everywhere, revoking their personal access tokens too
+func (s *PasswordResetService) ResetPassword(ctx context.Context, token, newPassword string) error {
+	rt, err := s.resetRepo.FindTokenByHash(ctx, hashToken(token))
+	if err != nil {
+		return ErrInvalidResetToken
+	}
+
+	if rt.UsedAt != nil || rt.ExpiresAt.Before(time.Now()) {
+		return ErrInvalidResetToken
+	}
+
+	user, err := s.userRepo.GetUserByID(ctx, rt.UserID)
+	if err != nil {
+		return ErrInvalidResetToken
+	}
+
+	// Hash before redeeming, so a failure here doesn't burn the token
+	hash, err := s.passwords.Hash(newPassword)
+	if err != nil {
+		return err
+	}
+
+	// Single use, even with concurrent requests
+	redeemed, err := s.resetRepo.Redeem(ctx, rt.ID, user.ID, hash)
+	if err != nil {
+		return err
+	}
+	if !redeemed {
+		return ErrInvalidResetToken
+	}
+
+	return nil
+}
@@ -0,0 +1,211 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/georgiev098/film-manager/backend/internal/apperrors"
+	"github.com/georgiev098/film-manager/backend/internal/audit"
+	"github.com/georgiev098/film-manager/backend/internal/dtos"
+	"github.com/georgiev098/film-manager/backend/internal/models"
+	"github.com/georgiev098/film-manager/backend/internal/repositories"
+	"gorm.io/gorm"
+)
+
+// ErrPrintNotFound is also returned for another user's print
+var ErrPrintNotFound = apperrors.NotFound("print not found")
+
+type PrintService struct {
+	db *gorm.DB
+}
+
+func NewPrintService(db *gorm.DB) *PrintService {
+	return &PrintService{
+		db: db,
+	}
+}
+
+// CreatePrint logs a first attempt at printing a frame. Versions count the
+// attempts per frame, so it gets the number after the frame's latest print.
+func (s *PrintService) CreatePrint(ctx context.Context, print *models.Print) error {
+	_, err := NewFrameService(s.db).GetFrameByID(ctx, print.FrameID, print.UserID)
+	if err != nil {
+		return err
+	}
+
+	print.PreviousID = nil
+	print.Changes = nil
+
+	return s.create(ctx, print)
+}
+
+// GetAllForUser lists the user's prints, only those of one frame when frameID is set
+func (s *PrintService) GetAllForUser(ctx context.Context, userID uint, frameID *uint) ([]models.Print, error) {
+	repo := repositories.NewPrintRepo(s.db)
+
+	if frameID == nil {
+		return repo.GetAllByUserID(ctx, userID)
+	}
+
+	frame, err := NewFrameService(s.db).GetFrameByID(ctx, *frameID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return repo.GetAllByFrameID(ctx, frame.ID)
+}
+
+func (s *PrintService) GetPrintByID(ctx context.Context, printID uint, userID uint) (*models.Print, error) {
+	print, err := repositories.NewPrintRepo(s.db).GetPrintByID(ctx, printID)
+	if err != nil {
+		return nil, notFound(err, ErrPrintNotFound)
+	}
+
+	if print.UserID != userID {
+		return nil, ErrPrintNotFound
+	}
+
+	return print, nil
+}
+
+// UpdatePrint only changes the notes; the settings are a record of how the
+// print was made
+func (s *PrintService) UpdatePrint(ctx context.Context, printID uint, userID uint, input dtos.PrintUpdate) (*models.Print, error) {
+	print, err := s.GetPrintByID(ctx, printID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if input.Notes == nil {
+		return print, nil
+	}
+
+	err = repositories.NewPrintRepo(s.db).UpdatePrint(ctx, print, map[string]any{"notes": input.Notes})
+	if err != nil {
+		return nil, err
+	}
+
+	return print, nil
+}
+
+// Reprint makes the next version of a print. It starts from the previous
+// attempt's settings, applies the changes and records them.
+func (s *PrintService) Reprint(ctx context.Context, printID uint, userID uint, input dtos.Reprint) (*models.Print, error) {
+	previous, err := s.GetPrintByID(ctx, printID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	updates := map[string]any{}
+
+	if input.Paper != nil {
+		updates["paper"] = input.Paper
+	}
+	if input.PaperSize != nil {
+		updates["paper_size"] = input.PaperSize
+	}
+	if input.Contrast != nil {
+		updates["contrast"] = input.Contrast
+	}
+	if input.EnlargerHeightCM != nil {
+		updates["enlarger_height_cm"] = input.EnlargerHeightCM
+	}
+	if input.Aperture != nil {
+		updates["aperture"] = input.Aperture
+	}
+	if input.ExposureSeconds != nil {
+		updates["exposure_seconds"] = input.ExposureSeconds
+	}
+	if input.TestStrip != nil {
+		updates["test_strip"] = input.TestStrip
+	}
+	if input.DodgeBurn != nil {
+		updates["dodge_burn"] = input.DodgeBurn
+	}
+	if input.Toning != nil {
+		updates["toning"] = input.Toning
+	}
+
+	changes, err := json.Marshal(audit.Diff(printColumns(previous), updates))
+	if err != nil {
+		return nil, err
+	}
+
+	print := &models.Print{
+		FrameID:          previous.FrameID,
+		PreviousID:       &previous.ID,
+		Paper:            pick(input.Paper, previous.Paper),
+		PaperSize:        pick(input.PaperSize, previous.PaperSize),
+		Contrast:         pickOptional(input.Contrast, previous.Contrast),
+		EnlargerHeightCM: pickOptional(input.EnlargerHeightCM, previous.EnlargerHeightCM),
+		Aperture:         pickOptional(input.Aperture, previous.Aperture),
+		ExposureSeconds:  pick(input.ExposureSeconds, previous.ExposureSeconds),
+		TestStrip:        pickOptional(input.TestStrip, previous.TestStrip),
+		DodgeBurn:        pickOptional(input.DodgeBurn, previous.DodgeBurn),
+		Toning:           pickOptional(input.Toning, previous.Toning),
+		Notes:            input.Notes, // notes are about this attempt, not carried over
+		Changes:          changes,
+		UserID:           userID,
+	}
+
+	err = s.create(ctx, print)
+	if err != nil {
+		return nil, err
+	}
+
+	return print, nil
+}
+
+func (s *PrintService) DeletePrint(ctx context.Context, printID uint, userID uint) error {
+	print, err := s.GetPrintByID(ctx, printID, userID)
+	if err != nil {
+		return err
+	}
+
+	return repositories.NewPrintRepo(s.db).DeletePrint(ctx, print)
+}
+
+// create numbers the print after the frame's latest one and inserts it
+func (s *PrintService) create(ctx context.Context, print *models.Print) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		repo := repositories.NewPrintRepo(tx)
+
+		latest, err := repo.MaxVersionLocked(ctx, print.FrameID)
+		if err != nil {
+			return err
+		}
+
+		print.Version = latest + 1
+
+		return repo.CreatePrint(ctx, print)
+	})
+}
+
+// printColumns maps reprint columns to the print's values, for the diff
+func printColumns(p *models.Print) map[string]any {
+	return map[string]any{
+		"paper":              p.Paper,
+		"paper_size":         p.PaperSize,
+		"contrast":           p.Contrast,
+		"enlarger_height_cm": p.EnlargerHeightCM,
+		"aperture":           p.Aperture,
+		"exposure_seconds":   p.ExposureSeconds,
+		"test_strip":         p.TestStrip,
+		"dodge_burn":         p.DodgeBurn,
+		"toning":             p.Toning,
+	}
+}
+
+func pick[T any](value *T, fallback T) T {
+	if value == nil {
+		return fallback
+	}
+	return *value
+}
+
+func pickOptional[T any](value *T, fallback *T) *T {
+	if value == nil {
+		return fallback
+	}
+	return value
+}
@@ -0,0 +1,131 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/georgiev098/film-manager/backend/internal/dtos"
+	"github.com/georgiev098/film-manager/backend/internal/models"
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestReprint(t *testing.T) {
+	ctx := context.Background()
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{
+		Logger: logger.Discard,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = db.AutoMigrate(&models.User{}, &models.Camera{}, &models.FilmStock{}, &models.Roll{}, &models.ArchiveLocation{}, &models.Frame{}, &models.Print{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user := &models.User{Email: "user@example.com", FirstName: "Ansel", LastName: "Adams", Role: models.RoleUser}
+	other := &models.User{Email: "other@example.com", FirstName: "Edward", LastName: "Weston", Role: models.RoleUser}
+	for _, u := range []*models.User{user, other} {
+		if err := db.Create(u).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	stock := &models.FilmStock{Manufacturer: "Kodak", Name: "Tri-X", ISO: 400, Format: models.Format35mm, Process: models.ProcessBW, UserID: user.ID}
+	if err := db.Create(stock).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	roll := &models.Roll{FilmStockID: stock.ID, Status: models.RollDeveloped, UserID: user.ID}
+	if err := db.Create(roll).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	frame := &models.Frame{RollID: roll.ID, Number: 14, UserID: user.ID}
+	if err := db.Create(frame).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	s := NewPrintService(db)
+
+	err = s.CreatePrint(ctx, &models.Print{FrameID: frame.ID, Paper: "Ilford MGFB", PaperSize: "8x10in", ExposureSeconds: 12, UserID: other.ID})
+	if !errors.Is(err, ErrFrameNotFound) {
+		t.Errorf("print of another user's frame: got %v, want %v", err, ErrFrameNotFound)
+	}
+
+	first := &models.Print{
+		FrameID:         frame.ID,
+		Paper:           "Ilford MGFB",
+		PaperSize:       "8x10in",
+		Contrast:        ptr("2"),
+		Aperture:        ptr("f/8"),
+		ExposureSeconds: 12,
+		DodgeBurn:       ptr("dodge face 3s"),
+		Notes:           ptr("sky too light"),
+		UserID:          user.ID,
+	}
+	if err := s.CreatePrint(ctx, first); err != nil {
+		t.Fatal(err)
+	}
+	if first.Version != 1 || first.PreviousID != nil {
+		t.Errorf("first print: version %d, previous %v", first.Version, first.PreviousID)
+	}
+
+	second, err := s.Reprint(ctx, first.ID, user.ID, dtos.Reprint{
+		Contrast:        ptr("3"),
+		ExposureSeconds: ptr(12.0), // unchanged, so not in the changes
+		DodgeBurn:       ptr("dodge face 3s, burn sky +50%"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if second.Version != 2 || second.PreviousID == nil || *second.PreviousID != first.ID {
+		t.Errorf("reprint: version %d, previous %v", second.Version, second.PreviousID)
+	}
+	if second.Paper != "Ilford MGFB" || *second.Aperture != "f/8" || *second.Contrast != "3" {
+		t.Errorf("reprint settings: %+v", second)
+	}
+	if second.Notes != nil {
+		t.Errorf("notes carried over: %q", *second.Notes)
+	}
+
+	var changes map[string]dtos.AuditChange
+	if err := json.Unmarshal(second.Changes, &changes); err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 2 || changes["contrast"].From != "2" || changes["contrast"].To != "3" {
+		t.Errorf("changes: %s", second.Changes)
+	}
+	if _, ok := changes["dodge_burn"]; !ok {
+		t.Errorf("changes miss dodge_burn: %s", second.Changes)
+	}
+
+	// A reprint of the first attempt is still numbered after the latest one
+	third, err := s.Reprint(ctx, first.ID, user.ID, dtos.Reprint{Paper: ptr("Foma 532")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if third.Version != 3 || *third.PreviousID != first.ID {
+		t.Errorf("branch: version %d, previous %v", third.Version, *third.PreviousID)
+	}
+
+	_, err = s.Reprint(ctx, first.ID, other.ID, dtos.Reprint{})
+	if !errors.Is(err, ErrPrintNotFound) {
+		t.Errorf("reprint of another user's print: got %v, want %v", err, ErrPrintNotFound)
+	}
+
+	prints, err := s.GetAllForUser(ctx, user.ID, &frame.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(prints) != 3 {
+		t.Errorf("got %d prints of the frame, want 3", len(prints))
+	}
+}
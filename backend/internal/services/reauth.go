@@ -0,0 +1,44 @@
+package services
+
+import (
+	"time"
+
+	"github.com/georgiev098/film-manager/backend/internal/apperrors"
+	"github.com/georgiev098/film-manager/backend/internal/models"
+	"github.com/georgiev098/film-manager/backend/internal/password"
+)
+
+// How recently an account without a password must have signed in to pass as
+// re-authenticated
+const reauthMaxAge = 10 * time.Minute
+
+var ErrReauthRequired = apperrors.Unauthorized("sign in again to continue")
+
+// Reauth is what a user offers to confirm a sensitive change: their password,
+// and when the session they are using was started
+type Reauth struct {
+	Password string
+	AuthTime time.Time // zero for personal access tokens
+}
+
+// verify checks the password of accounts that have one. Accounts that only
+// sign in through an identity provider have no password to ask for, so their
+// session must have started within reauthMaxAge instead.
+func (r Reauth) verify(passwords *password.Hasher, user *models.User) error {
+	if user.PasswordHash == "" {
+		if r.AuthTime.IsZero() || time.Since(r.AuthTime) > reauthMaxAge {
+			return ErrReauthRequired
+		}
+		return nil
+	}
+
+	ok, err := passwords.Verify(user.PasswordHash, r.Password)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrWrongPassword
+	}
+
+	return nil
+}
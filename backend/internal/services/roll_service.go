@@ -0,0 +1,249 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/georgiev098/film-manager/backend/internal/apperrors"
+	"github.com/georgiev098/film-manager/backend/internal/dtos"
+	"github.com/georgiev098/film-manager/backend/internal/models"
+	"github.com/georgiev098/film-manager/backend/internal/repositories"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrRollNotFound is also returned for another user's roll
+	ErrRollNotFound       = apperrors.NotFound("roll not found")
+	ErrCameraLoaded       = apperrors.Conflict("another roll is loaded in this camera")
+	ErrRollNeedsCamera    = apperrors.Validation("a loaded roll needs a camera")
+	ErrRollWrongFormat    = apperrors.Validation("the film format doesn't fit the camera")
+	ErrRollStatusBackward = apperrors.Validation("a roll can only move on: loaded, exposed, developed")
+	ErrRollNotLoaded      = apperrors.Validation("only a loaded roll can move to another camera")
+	ErrRollNotDeveloped   = apperrors.Validation("only a developed roll can be filed")
+	ErrRollNotOnPage      = apperrors.Validation("a roll is filed on a page of the archive")
+)
+
+// rollStages orders the statuses a roll goes through
+var rollStages = map[models.RollStatus]int{
+	models.RollLoaded:    0,
+	models.RollExposed:   1,
+	models.RollDeveloped: 2,
+}
+
+type RollService struct {
+	db *gorm.DB
+}
+
+func NewRollService(db *gorm.DB) *RollService {
+	return &RollService{
+		db: db,
+	}
+}
+
+// CreateRoll records a roll. A loaded roll goes into one of the user's
+// cameras of the same format that has no other roll in it.
+func (s *RollService) CreateRoll(ctx context.Context, roll *models.Roll) error {
+	stock, err := NewFilmStockService(repositories.NewFilmStockRepo(s.db)).GetFilmStockByID(ctx, roll.FilmStockID, roll.UserID)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	switch roll.Status {
+	case models.RollLoaded:
+		roll.LoadedAt = &now
+	case models.RollExposed:
+		roll.ExposedAt = &now
+	case models.RollDeveloped:
+		roll.DevelopedAt = &now
+	}
+
+	if roll.ArchiveLocationID != nil {
+		err = s.checkFiling(ctx, roll, *roll.ArchiveLocationID)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := s.checkCamera(ctx, tx, roll, stock)
+		if err != nil {
+			return err
+		}
+
+		return repositories.NewRollRepo(tx).CreateRoll(ctx, roll)
+	})
+	if err != nil {
+		return err
+	}
+
+	roll.FilmStock = stock
+	return nil
+}
+
+func (s *RollService) GetAllForUser(ctx context.Context, userID uint) ([]models.Roll, error) {
+	return repositories.NewRollRepo(s.db).GetAllByUserID(ctx, userID)
+}
+
+func (s *RollService) GetRollByID(ctx context.Context, rollID uint, userID uint) (*models.Roll, error) {
+	return getRoll(ctx, repositories.NewRollRepo(s.db), rollID, userID)
+}
+
+// UpdateRoll moves a roll on to a later status, a loaded roll to another
+// camera, or files a developed one in the archive
+func (s *RollService) UpdateRoll(ctx context.Context, rollID uint, userID uint, input dtos.RollUpdate) (*models.Roll, error) {
+	var roll *models.Roll
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		repo := repositories.NewRollRepo(tx)
+
+		var err error
+		roll, err = getRoll(ctx, repo, rollID, userID)
+		if err != nil {
+			return err
+		}
+
+		updates := map[string]any{}
+		now := time.Now()
+
+		if input.Status != nil && *input.Status != roll.Status {
+			if rollStages[*input.Status] < rollStages[roll.Status] {
+				return ErrRollStatusBackward
+			}
+
+			updates["status"] = *input.Status
+			switch *input.Status {
+			case models.RollExposed:
+				updates["exposed_at"] = now
+			case models.RollDeveloped:
+				updates["developed_at"] = now
+			}
+			roll.Status = *input.Status
+		}
+
+		if input.CameraID != nil && (roll.CameraID == nil || *input.CameraID != *roll.CameraID) {
+			if roll.Status != models.RollLoaded {
+				return ErrRollNotLoaded
+			}
+
+			roll.CameraID = input.CameraID
+			err = s.checkCamera(ctx, tx, roll, roll.FilmStock)
+			if err != nil {
+				return err
+			}
+			updates["camera_id"] = *input.CameraID
+		}
+
+		if input.ArchiveLocationID != nil {
+			pageID := input.ArchiveLocationID
+			if *pageID == 0 {
+				pageID = nil
+			} else {
+				err = s.checkFiling(ctx, roll, *pageID)
+				if err != nil {
+					return err
+				}
+			}
+
+			if !sameID(pageID, roll.ArchiveLocationID) {
+				// The strips of the old page don't hold its frames any more
+				err = repositories.NewFrameRepo(tx).UnfileByRollID(ctx, roll.ID)
+				if err != nil {
+					return err
+				}
+
+				updates["archive_location_id"] = pageID
+				roll.ArchiveLocationID = pageID
+			}
+		}
+
+		if input.Notes != nil {
+			updates["notes"] = input.Notes
+		}
+
+		if len(updates) == 0 {
+			return nil
+		}
+
+		return repo.UpdateRoll(ctx, roll, updates)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return roll, nil
+}
+
+func (s *RollService) DeleteRoll(ctx context.Context, rollID uint, userID uint) error {
+	repo := repositories.NewRollRepo(s.db)
+
+	roll, err := getRoll(ctx, repo, rollID, userID)
+	if err != nil {
+		return err
+	}
+
+	return repo.DeleteRoll(ctx, roll)
+}
+
+// checkCamera makes sure a loaded roll can go into its camera. It runs inside
+// tx, whose locking read keeps a second roll from being loaded meanwhile.
+func (s *RollService) checkCamera(ctx context.Context, tx *gorm.DB, roll *models.Roll, stock *models.FilmStock) error {
+	if roll.CameraID == nil {
+		if roll.Status == models.RollLoaded {
+			return ErrRollNeedsCamera
+		}
+		return nil
+	}
+
+	camera, err := NewCameraService(repositories.NewCameraRepo(tx), nil, nil).GetCameraByID(ctx, *roll.CameraID, roll.UserID)
+	if err != nil {
+		return err
+	}
+
+	if roll.Status != models.RollLoaded {
+		return nil
+	}
+
+	if stock != nil && camera.CameraFormat != stock.Format {
+		return ErrRollWrongFormat
+	}
+
+	loaded, err := repositories.NewRollRepo(tx).CameraLoadedLocked(ctx, camera.ID, roll.ID)
+	if err != nil {
+		return err
+	}
+	if loaded {
+		return ErrCameraLoaded
+	}
+
+	return nil
+}
+
+// checkFiling makes sure the roll is developed and pageID is one of the user's pages
+func (s *RollService) checkFiling(ctx context.Context, roll *models.Roll, pageID uint) error {
+	if roll.Status != models.RollDeveloped {
+		return ErrRollNotDeveloped
+	}
+
+	return NewArchiveService(s.db).checkPage(ctx, pageID, roll.UserID)
+}
+
+func sameID(a, b *uint) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func getRoll(ctx context.Context, repo *repositories.RollRepo, rollID uint, userID uint) (*models.Roll, error) {
+	roll, err := repo.GetRollByID(ctx, rollID)
+	if err != nil {
+		return nil, notFound(err, ErrRollNotFound)
+	}
+
+	if roll.UserID != userID {
+		return nil, ErrRollNotFound
+	}
+
+	return roll, nil
+}
@@ -0,0 +1,268 @@
+package services
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/georgiev098/film-manager/backend/internal/apperrors"
+	"github.com/georgiev098/film-manager/backend/internal/dtos"
+	"github.com/georgiev098/film-manager/backend/internal/models"
+	"github.com/georgiev098/film-manager/backend/internal/repositories"
+	"gorm.io/gorm"
+)
+
+// ErrShootNotFound is also returned for another user's shoot
+var ErrShootNotFound = apperrors.NotFound("shoot not found")
+
+// ShootLight is the light on the day of a shoot and how the film loaded in
+// its kit suits it
+type ShootLight struct {
+	Shoot *models.Shoot `json:"shoot"`
+	Light *LightWindows `json:"light"`
+
+	// Rolls loaded in the kit's cameras, or in any camera for a shoot without a kit
+	LoadedFilm []LoadedFilm `json:"loaded_film"`
+	// The best of the loaded rolls for each of Light.Suggestions
+	Picks []FilmPick `json:"picks"`
+}
+
+type LoadedFilm struct {
+	RollID    uint     `json:"roll_id"`
+	CameraID  uint     `json:"camera_id"`
+	Camera    string   `json:"camera"`
+	FilmStock string   `json:"film_stock"`
+	ISO       int      `json:"iso"`
+	Suits     []string `json:"suits"` // windows whose suggested speeds include the film's
+}
+
+// FilmPick names the loaded roll to reach for in a light window. When no
+// loaded film is within the suggested speeds, the closest one is picked and
+// Fits is false.
+type FilmPick struct {
+	Window string `json:"window"`
+	RollID *uint  `json:"roll_id"` // nil when nothing is loaded
+	Fits   bool   `json:"fits"`
+}
+
+type ShootService struct {
+	db    *gorm.DB
+	light *LightService
+}
+
+func NewShootService(db *gorm.DB, light *LightService) *ShootService {
+	return &ShootService{
+		db:    db,
+		light: light,
+	}
+}
+
+func (s *ShootService) CreateShoot(ctx context.Context, shoot *models.Shoot) error {
+	if shoot.KitID != nil {
+		_, err := NewKitService(s.db).GetKitByID(ctx, *shoot.KitID, shoot.UserID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return repositories.NewShootRepo(s.db).CreateShoot(ctx, shoot)
+}
+
+func (s *ShootService) GetAllForUser(ctx context.Context, userID uint) ([]models.Shoot, error) {
+	return repositories.NewShootRepo(s.db).GetAllByUserID(ctx, userID)
+}
+
+func (s *ShootService) GetShootByID(ctx context.Context, shootID uint, userID uint) (*models.Shoot, error) {
+	shoot, err := repositories.NewShootRepo(s.db).GetShootByID(ctx, shootID)
+	if err != nil {
+		return nil, notFound(err, ErrShootNotFound)
+	}
+
+	if shoot.UserID != userID {
+		return nil, ErrShootNotFound
+	}
+
+	return shoot, nil
+}
+
+func (s *ShootService) UpdateShoot(ctx context.Context, shootID uint, userID uint, input dtos.ShootUpdate) (*models.Shoot, error) {
+	shoot, err := s.GetShootByID(ctx, shootID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	updates := map[string]any{}
+
+	if input.Title != nil {
+		updates["title"] = input.Title
+	}
+	if input.Date != nil {
+		updates["date"] = input.Date
+	}
+	if input.Latitude != nil {
+		updates["latitude"] = input.Latitude
+	}
+	if input.Longitude != nil {
+		updates["longitude"] = input.Longitude
+	}
+	if input.Timezone != nil {
+		updates["timezone"] = input.Timezone
+	}
+	if input.KitID != nil {
+		if *input.KitID == 0 {
+			updates["kit_id"] = nil
+		} else {
+			_, err := NewKitService(s.db).GetKitByID(ctx, *input.KitID, userID)
+			if err != nil {
+				return nil, err
+			}
+			updates["kit_id"] = input.KitID
+		}
+	}
+	if input.Notes != nil {
+		updates["notes"] = input.Notes
+	}
+
+	if len(updates) == 0 {
+		return shoot, nil
+	}
+
+	err = repositories.NewShootRepo(s.db).UpdateShoot(ctx, shoot, updates)
+	if err != nil {
+		return nil, err
+	}
+
+	return shoot, nil
+}
+
+func (s *ShootService) DeleteShoot(ctx context.Context, shootID uint, userID uint) error {
+	shoot, err := s.GetShootByID(ctx, shootID, userID)
+	if err != nil {
+		return err
+	}
+
+	return repositories.NewShootRepo(s.db).DeleteShoot(ctx, shoot)
+}
+
+// GetLight computes the light windows at the shoot's place and date and
+// matches them against the film loaded in the kit's cameras
+func (s *ShootService) GetLight(ctx context.Context, shootID uint, userID uint) (*ShootLight, error) {
+	shoot, err := s.GetShootByID(ctx, shootID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Both were validated when the shoot was saved
+	loc := time.UTC
+	if shoot.Timezone != "" {
+		loc, err = time.LoadLocation(shoot.Timezone)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	day, err := time.ParseInLocation("2006-01-02", shoot.Date, loc)
+	if err != nil {
+		return nil, err
+	}
+
+	var cameraIDs []uint
+	if shoot.KitID != nil {
+		kit, err := NewKitService(s.db).GetKitByID(ctx, *shoot.KitID, userID)
+		if err != nil {
+			return nil, err
+		}
+
+		cameraIDs = make([]uint, 0, len(kit.Cameras))
+		for _, c := range kit.Cameras {
+			cameraIDs = append(cameraIDs, c.ID)
+		}
+	}
+
+	rolls, err := repositories.NewRollRepo(s.db).GetLoaded(ctx, userID, cameraIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	light := s.light.GetLightWindows(shoot.Latitude, shoot.Longitude, day, loc)
+
+	return &ShootLight{
+		Shoot:      shoot,
+		Light:      light,
+		LoadedFilm: loadedFilm(rolls, light.Suggestions),
+		Picks:      pickFilm(rolls, light.Suggestions),
+	}, nil
+}
+
+func loadedFilm(rolls []models.Roll, suggestions []FilmSuggestion) []LoadedFilm {
+	loaded := make([]LoadedFilm, 0, len(rolls))
+	for _, roll := range rolls {
+		if roll.FilmStock == nil || roll.Camera == nil {
+			continue // stock or camera deleted since
+		}
+
+		film := LoadedFilm{
+			RollID:    roll.ID,
+			CameraID:  roll.Camera.ID,
+			Camera:    roll.Camera.Brand + " " + roll.Camera.CameraModel,
+			FilmStock: roll.FilmStock.Manufacturer + " " + roll.FilmStock.Name,
+			ISO:       roll.FilmStock.ISO,
+			Suits:     []string{},
+		}
+
+		for _, sg := range suggestions {
+			if isoDistance(film.ISO, sg) == 0 {
+				film.Suits = append(film.Suits, sg.Window)
+			}
+		}
+
+		loaded = append(loaded, film)
+	}
+
+	return loaded
+}
+
+// pickFilm picks, per window, the loaded roll whose speed is closest to the
+// suggested range, in stops. Ties go to the middle of the range, then to the
+// roll loaded first.
+func pickFilm(rolls []models.Roll, suggestions []FilmSuggestion) []FilmPick {
+	picks := make([]FilmPick, 0, len(suggestions))
+
+	for _, sg := range suggestions {
+		pick := FilmPick{Window: sg.Window}
+		best := math.Inf(1)
+		bestMid := math.Inf(1)
+		mid := math.Sqrt(float64(sg.MinISO) * float64(sg.MaxISO))
+
+		for _, roll := range rolls {
+			if roll.FilmStock == nil || roll.Camera == nil {
+				continue
+			}
+
+			d := isoDistance(roll.FilmStock.ISO, sg)
+			dMid := math.Abs(math.Log2(float64(roll.FilmStock.ISO) / mid))
+			if d < best || (d == best && dMid < bestMid) {
+				id := roll.ID
+				pick.RollID = &id
+				pick.Fits = d == 0
+				best, bestMid = d, dMid
+			}
+		}
+
+		picks = append(picks, pick)
+	}
+
+	return picks
+}
+
+// isoDistance is how many stops iso lies outside the suggested range
+func isoDistance(iso int, sg FilmSuggestion) float64 {
+	switch {
+	case iso < sg.MinISO:
+		return math.Log2(float64(sg.MinISO) / float64(iso))
+	case iso > sg.MaxISO:
+		return math.Log2(float64(iso) / float64(sg.MaxISO))
+	default:
+		return 0
+	}
+}
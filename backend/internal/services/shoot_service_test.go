@@ -0,0 +1,90 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/georgiev098/film-manager/backend/internal/models"
+)
+
+func TestPickFilm(t *testing.T) {
+	camera := &models.Camera{Brand: "Nikon", CameraModel: "FM2"}
+	roll := func(id uint, iso int) models.Roll {
+		r := models.Roll{FilmStock: &models.FilmStock{ISO: iso}, Camera: camera}
+		r.ID = id
+		return r
+	}
+
+	suggestions := []FilmSuggestion{
+		{Window: "blue_hour", MinISO: 800, MaxISO: 3200},
+		{Window: "golden_hour", MinISO: 200, MaxISO: 400},
+		{Window: "midday", MinISO: 50, MaxISO: 200},
+	}
+
+	tests := []struct {
+		name  string
+		rolls []models.Roll
+		want  []FilmPick
+	}{
+		{
+			name:  "nothing loaded",
+			rolls: nil,
+			want: []FilmPick{
+				{Window: "blue_hour"},
+				{Window: "golden_hour"},
+				{Window: "midday"},
+			},
+		},
+		{
+			name:  "one roll fits some windows, is closest for the rest",
+			rolls: []models.Roll{roll(1, 400)},
+			want: []FilmPick{
+				{Window: "blue_hour", RollID: ptr(uint(1)), Fits: false},
+				{Window: "golden_hour", RollID: ptr(uint(1)), Fits: true},
+				{Window: "midday", RollID: ptr(uint(1)), Fits: false},
+			},
+		},
+		{
+			name:  "closest in stops, ties go to the middle of the range",
+			rolls: []models.Roll{roll(1, 100), roll(2, 400), roll(3, 1600)},
+			want: []FilmPick{
+				{Window: "blue_hour", RollID: ptr(uint(3)), Fits: true},
+				{Window: "golden_hour", RollID: ptr(uint(2)), Fits: true},
+				{Window: "midday", RollID: ptr(uint(1)), Fits: true},
+			},
+		},
+		{
+			name:  "rolls whose stock or camera is gone are skipped",
+			rolls: []models.Roll{{FilmStock: nil, Camera: camera}, {FilmStock: &models.FilmStock{ISO: 400}}},
+			want: []FilmPick{
+				{Window: "blue_hour"},
+				{Window: "golden_hour"},
+				{Window: "midday"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := pickFilm(tt.rolls, suggestions)
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d picks, want %d", len(got), len(tt.want))
+			}
+
+			for i := range got {
+				g, w := got[i], tt.want[i]
+				if g.Window != w.Window || g.Fits != w.Fits || !sameID(g.RollID, w.RollID) {
+					t.Errorf("pick %d: got %+v (roll %v), want %+v (roll %v)", i, g, deref(g.RollID), w, deref(w.RollID))
+				}
+			}
+		})
+	}
+}
+
+func ptr[T any](v T) *T { return &v }
+
+func deref(id *uint) any {
+	if id == nil {
+		return nil
+	}
+	return *id
+}
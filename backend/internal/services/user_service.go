@@ -5,35 +5,60 @@ import (
 	"errors"
 	"strings"
 
-	"github.com/georgiev098/film-manager/backend/internal/helpers"
+	"github.com/georgiev098/film-manager/backend/internal/apperrors"
+	"github.com/georgiev098/film-manager/backend/internal/audit"
+	"github.com/georgiev098/film-manager/backend/internal/dtos"
 	"github.com/georgiev098/film-manager/backend/internal/models"
+	"github.com/georgiev098/film-manager/backend/internal/password"
 	"github.com/georgiev098/film-manager/backend/internal/repositories"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrUserNotFound       = apperrors.NotFound("user not found")
+	ErrInvalidCredentials = apperrors.Unauthorized("invalid credentials")
+	ErrUserDisabled       = apperrors.Forbidden("account disabled")
+	ErrWrongPassword      = apperrors.Forbidden("current password is incorrect")
+	ErrEmailTaken         = apperrors.Conflict("email address is already in use")
 )
 
 type UserService struct {
-	repo *repositories.UserRepository
+	repo      *repositories.UserRepository
+	passwords *password.Hasher
+	audit     *audit.Service
 }
 
-func NewUserService(repo *repositories.UserRepository) *UserService {
+func NewUserService(repo *repositories.UserRepository, passwords *password.Hasher, audit *audit.Service) *UserService {
 	return &UserService{
-		repo: repo,
+		repo:      repo,
+		passwords: passwords,
+		audit:     audit,
 	}
 }
 
-func (s *UserService) SignUp(ctx context.Context, email, password, firstName, lastName string) (*models.User, error) {
+func (s *UserService) SignUp(ctx context.Context, email, pw, firstName, lastName string) (*models.User, error) {
+	email = strings.ToLower(strings.TrimSpace(email))
 
-	hash, err := helpers.HasPassword(password)
+	// Soft-deleted accounts still hold their address in the unique index
+	taken, err := s.repo.EmailTaken(ctx, email)
 	if err != nil {
 		return nil, err
 	}
+	if taken {
+		return nil, ErrEmailTaken
+	}
 
-	email = strings.ToLower(strings.TrimSpace(email))
+	hash, err := s.passwords.Hash(pw)
+	if err != nil {
+		return nil, err
+	}
 
 	newUser := &models.User{
 		Email:        email,
-		PasswordHash: string(hash),
+		PasswordHash: hash,
 		FirstName:    firstName,
 		LastName:     lastName,
+		Role:         models.RoleUser,
 	}
 
 	err = s.repo.CraeteUser(ctx, newUser)
@@ -44,18 +69,223 @@ func (s *UserService) SignUp(ctx context.Context, email, password, firstName, la
 	return newUser, nil
 }
 
-func (s *UserService) Login(ctx context.Context, email, password string) (*models.User, error) {
+func (s *UserService) Login(ctx context.Context, email, pw string) (*models.User, error) {
 	email = strings.ToLower(strings.TrimSpace(email))
 
 	user, err := s.repo.GetUserByEmail(ctx, email)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		s.recordLoginFailure(ctx, 0, "unknown_account")
+		return nil, ErrInvalidCredentials
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Accounts created through an identity provider have no password until they set one
+	if user.PasswordHash == "" {
+		s.recordLoginFailure(ctx, user.ID, "no_password")
+		return nil, ErrInvalidCredentials
+	}
+
+	ok, err := s.passwords.Verify(user.PasswordHash, pw)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		s.recordLoginFailure(ctx, user.ID, "wrong_password")
+		return nil, ErrInvalidCredentials
+	}
+
+	if user.Disabled {
+		s.recordLoginFailure(ctx, user.ID, "disabled")
+		return nil, ErrUserDisabled
+	}
+
+	// Upgrade bcrypt and outdated argon2id hashes while we have the plain password.
+	// Failing here only means trying again on the next login.
+	if s.passwords.NeedsRehash(user.PasswordHash) {
+		if hash, err := s.passwords.Hash(pw); err == nil {
+			_ = s.repo.UpdateUser(ctx, user, map[string]any{"password_hash": hash})
+		}
+	}
+
+	return user, nil
+}
+
+func (s *UserService) GetUserByID(ctx context.Context, userID uint) (*models.User, error) {
+	user, err := s.repo.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, notFound(err, ErrUserNotFound)
+	}
+
+	return user, nil
+}
+
+func (s *UserService) GetAllUsers(ctx context.Context) ([]models.User, error) {
+	return s.repo.GetAllUsers(ctx)
+}
+
+// SetDisabled blocks or unblocks logins for a user
+func (s *UserService) SetDisabled(ctx context.Context, userID uint, disabled bool) (*models.User, error) {
+	user, err := s.repo.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, notFound(err, ErrUserNotFound)
+	}
+
+	err = s.repo.UpdateUser(ctx, user, map[string]any{"disabled": disabled})
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+func (s *UserService) SetRole(ctx context.Context, userID uint, role models.Role) (*models.User, error) {
+	user, err := s.repo.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, notFound(err, ErrUserNotFound)
+	}
+
+	err = s.repo.UpdateUser(ctx, user, map[string]any{"role": role})
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// SetPassword replaces a user's password without checking the old one
+func (s *UserService) SetPassword(ctx context.Context, userID uint, pw string) error {
+	user, err := s.repo.GetUserByID(ctx, userID)
+	if err != nil {
+		return notFound(err, ErrUserNotFound)
+	}
+
+	hash, err := s.passwords.Hash(pw)
 	if err != nil {
-		return nil, errors.New("invalid credentials")
+		return err
 	}
 
-	err = helpers.CompareHashAndPassword(user.PasswordHash, password)
+	return s.repo.SetPasswordHash(ctx, user, hash)
+}
+
+// UpdateProfile changes the user's name and preferences. Nil fields are left as they are.
+func (s *UserService) UpdateProfile(ctx context.Context, userID uint, input dtos.ProfileUpdate) (*models.User, error) {
+	user, err := s.repo.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, notFound(err, ErrUserNotFound)
+	}
+
+	updates := map[string]any{}
+
+	if input.FirstName != nil {
+		updates["first_name"] = strings.TrimSpace(*input.FirstName)
+	}
+	if input.LastName != nil {
+		updates["last_name"] = strings.TrimSpace(*input.LastName)
+	}
+	if input.Units != nil {
+		updates["units"] = *input.Units
+	}
+	if input.Currency != nil {
+		updates["currency"] = strings.ToUpper(*input.Currency)
+	}
+	if input.Timezone != nil {
+		updates["timezone"] = *input.Timezone
+	}
+
+	if len(updates) == 0 {
+		return user, nil
+	}
+
+	err = s.repo.UpdateUser(ctx, user, updates)
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// ChangePassword sets a new password after confirming the current one
+func (s *UserService) ChangePassword(ctx context.Context, userID uint, current, newPassword string) error {
+	user, err := s.repo.GetUserByID(ctx, userID)
+	if err != nil {
+		return notFound(err, ErrUserNotFound)
+	}
+
+	err = s.checkPassword(user, current)
+	if err != nil {
+		return err
+	}
+
+	hash, err := s.passwords.Hash(newPassword)
+	if err != nil {
+		return err
+	}
+
+	return s.repo.SetPasswordHash(ctx, user, hash)
+}
+
+// ChangeEmail moves the account to a new address after confirming the password.
+// The new address is unverified until the user follows the new verification link.
+func (s *UserService) ChangeEmail(ctx context.Context, userID uint, email, pw string) (*models.User, error) {
+	user, err := s.repo.GetUserByID(ctx, userID)
 	if err != nil {
-		return nil, errors.New("invalid credentials")
+		return nil, notFound(err, ErrUserNotFound)
+	}
 
+	err = s.checkPassword(user, pw)
+	if err != nil {
+		return nil, err
 	}
+
+	email = strings.ToLower(strings.TrimSpace(email))
+	if email == user.Email {
+		return user, nil
+	}
+
+	// Soft-deleted accounts still hold their address in the unique index
+	taken, err := s.repo.EmailTaken(ctx, email)
+	if err != nil {
+		return nil, err
+	}
+	if taken {
+		return nil, ErrEmailTaken
+	}
+
+	err = s.repo.UpdateUser(ctx, user, map[string]any{
+		"email":             email,
+		"email_verified_at": nil,
+	})
+	if err != nil {
+		return nil, err
+	}
+
 	return user, nil
 }
+
+// recordLoginFailure audits a failed password login. The attempted address is
+// not stored, unknown accounts are only counted per IP.
+func (s *UserService) recordLoginFailure(ctx context.Context, userID uint, reason string) {
+	s.audit.Record(ctx, audit.Entry{
+		UserID:  userID,
+		Action:  models.AuditLoginFailed,
+		Details: map[string]any{"reason": reason},
+	})
+}
+
+func (s *UserService) checkPassword(user *models.User, pw string) error {
+	if user.PasswordHash == "" {
+		return ErrWrongPassword
+	}
+
+	ok, err := s.passwords.Verify(user.PasswordHash, pw)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrWrongPassword
+	}
+
+	return nil
+}